@@ -72,6 +72,9 @@ type BC interface {
 	NetworkID() (*big.Int, error)
 	SuggestGasPrice() (*big.Int, error)
 	HeaderByNumber(number *big.Int) (*types.Header, error)
+	NonceAt(account common.Address) (uint64, error)
+	PendingNonceAt(account common.Address) (uint64, error)
+	PendingTransactionCount() (uint, error)
 
 	TransferMyst(req TransferRequest) (tx *types.Transaction, err error)
 	TransferEth(etr EthTransferRequest) (*types.Transaction, error)
@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/payments" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package client
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/bindings"
+	"github.com/pkg/errors"
+)
+
+// RegistryClient is used by CachedRegistry to look up identity registration.
+type RegistryClient interface {
+	IsRegistered(registryAddress, addressToCheck common.Address) (bool, error)
+	SubscribeToIdentityRegistrationEvents(registryAddress common.Address) (sink chan *bindings.RegistryRegisteredIdentity, cancel func(), err error)
+}
+
+// CachedRegistry caches identity registration status of a single registry.
+//
+// Cache is populated by identity registration events and by looking up
+// the registration status on cache misses. Only registered identities are
+// cached as registration events might be missed, e.g. while resubscribing.
+type CachedRegistry struct {
+	// hits and misses are accessed atomically and kept first for alignment.
+	hits, misses uint64
+
+	bc              RegistryClient
+	registryAddress common.Address
+
+	// cache holds registered identities as common.Address to true.
+	cache  sync.Map
+	cancel func()
+}
+
+// NewCachedRegistry returns a new cached registry watching registration events of the given registry.
+func NewCachedRegistry(bc RegistryClient, registryAddress common.Address) (*CachedRegistry, error) {
+	sink, cancel, err := bc.SubscribeToIdentityRegistrationEvents(registryAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not subscribe to identity registration events")
+	}
+
+	r := &CachedRegistry{
+		bc:              bc,
+		registryAddress: registryAddress,
+		cancel:          cancel,
+	}
+	go r.watch(sink)
+
+	return r, nil
+}
+
+func (r *CachedRegistry) watch(sink chan *bindings.RegistryRegisteredIdentity) {
+	for ev := range sink {
+		if ev == nil {
+			continue
+		}
+		r.cache.Store(ev.Identity, true)
+	}
+}
+
+// IsRegistered checks wether the given identity is registered or not.
+func (r *CachedRegistry) IsRegistered(identity common.Address) (bool, error) {
+	if registered, ok := r.cache.Load(identity); ok {
+		atomic.AddUint64(&r.hits, 1)
+		return registered.(bool), nil
+	}
+	atomic.AddUint64(&r.misses, 1)
+
+	registered, err := r.bc.IsRegistered(r.registryAddress, identity)
+	if err != nil {
+		return false, err
+	}
+
+	if registered {
+		r.cache.Store(identity, true)
+	}
+	return registered, nil
+}
+
+// Invalidate removes the identity from the cache forcing a fresh lookup on next access.
+func (r *CachedRegistry) Invalidate(identity common.Address) {
+	r.cache.Delete(identity)
+}
+
+// Size returns the amount of cached identities.
+func (r *CachedRegistry) Size() int {
+	size := 0
+	r.cache.Range(func(_, _ interface{}) bool {
+		size++
+		return true
+	})
+	return size
+}
+
+// HitRate returns the share of IsRegistered calls served from the cache.
+func (r *CachedRegistry) HitRate() float64 {
+	hits := atomic.LoadUint64(&r.hits)
+	total := hits + atomic.LoadUint64(&r.misses)
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) / float64(total)
+}
+
+// Stop stops watching registration events.
+func (r *CachedRegistry) Stop() {
+	r.cancel()
+}
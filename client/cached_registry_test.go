@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/payments" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package client
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/bindings"
+	"github.com/stretchr/testify/assert"
+)
+
+type registryClientMock struct {
+	sink       chan *bindings.RegistryRegisteredIdentity
+	registered map[common.Address]bool
+	calls      int
+	m          sync.Mutex
+}
+
+func newRegistryClientMock() *registryClientMock {
+	return &registryClientMock{
+		sink:       make(chan *bindings.RegistryRegisteredIdentity),
+		registered: make(map[common.Address]bool),
+	}
+}
+
+func (c *registryClientMock) IsRegistered(registryAddress, addressToCheck common.Address) (bool, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.calls++
+	return c.registered[addressToCheck], nil
+}
+
+func (c *registryClientMock) SubscribeToIdentityRegistrationEvents(registryAddress common.Address) (chan *bindings.RegistryRegisteredIdentity, func(), error) {
+	var once sync.Once
+	return c.sink, func() { once.Do(func() { close(c.sink) }) }, nil
+}
+
+func (c *registryClientMock) register(identity common.Address) {
+	c.m.Lock()
+	c.registered[identity] = true
+	c.m.Unlock()
+	c.sink <- &bindings.RegistryRegisteredIdentity{Identity: identity}
+}
+
+func (c *registryClientMock) callCount() int {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.calls
+}
+
+func TestCachedRegistry(t *testing.T) {
+	bc := newRegistryClientMock()
+	registry, err := NewCachedRegistry(bc, common.HexToAddress("0x1"))
+	assert.NoError(t, err)
+	defer registry.Stop()
+
+	identity := common.HexToAddress("0x2")
+	for n := 0; n < 2; n++ {
+		registered, err := registry.IsRegistered(identity)
+		assert.NoError(t, err)
+		assert.False(t, registered)
+	}
+	assert.Equal(t, 2, bc.callCount(), "unregistered identity should not be cached")
+	assert.Equal(t, 0, registry.Size())
+
+	bc.register(identity)
+	assert.Eventually(t, func() bool {
+		registered, err := registry.IsRegistered(identity)
+		return err == nil && registered
+	}, time.Second, time.Millisecond)
+	calls := bc.callCount()
+	for n := 0; n < 20; n++ {
+		registered, err := registry.IsRegistered(identity)
+		assert.NoError(t, err)
+		assert.True(t, registered)
+	}
+	assert.Equal(t, calls, bc.callCount(), "registered identity should be served from cache")
+	assert.Equal(t, 1, registry.Size())
+
+	registry.Invalidate(identity)
+	for n := 0; n < 3; n++ {
+		registered, err := registry.IsRegistered(identity)
+		assert.NoError(t, err)
+		assert.True(t, registered)
+	}
+	assert.Equal(t, calls+1, bc.callCount(), "invalidated identity should be looked up once")
+	assert.True(t, registry.HitRate() > 0.8, "hit rate %v", registry.HitRate())
+}
@@ -960,6 +960,28 @@ func (bc *Blockchain) HeaderByNumber(number *big.Int) (*types.Header, error) {
 	return bc.ethClient.Client().HeaderByNumber(ctx, number)
 }
 
+// NonceAt returns the confirmed nonce of the given account at the latest block.
+func (bc *Blockchain) NonceAt(account common.Address) (uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	defer cancel()
+	return bc.ethClient.Client().NonceAt(ctx, account, nil)
+}
+
+// PendingNonceAt returns the nonce of the account in the pending state as seen by the node.
+// Custom nonce trackers are not used.
+func (bc *Blockchain) PendingNonceAt(account common.Address) (uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	defer cancel()
+	return bc.ethClient.Client().PendingNonceAt(ctx, account)
+}
+
+// PendingTransactionCount returns the amount of transactions in the pending block.
+func (bc *Blockchain) PendingTransactionCount() (uint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
+	defer cancel()
+	return bc.ethClient.Client().PendingTransactionCount(ctx)
+}
+
 func (bc *Blockchain) SuggestGasPrice() (*big.Int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), bc.bcTimeout)
 	defer cancel()
@@ -304,6 +304,103 @@ func (mbc *MultichainBlockchainClient) TransactionReceipt(chainID int64, hash co
 	return bc.TransactionReceipt(hash)
 }
 
+// TransactionConfirmations returns the amount of blocks mined on top of the block the transaction is included in.
+func (mbc *MultichainBlockchainClient) TransactionConfirmations(chainID int64, hash common.Hash) (uint64, error) {
+	bc, err := mbc.getClientByChain(chainID)
+	if err != nil {
+		return 0, err
+	}
+
+	receipt, err := bc.TransactionReceipt(hash)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get transaction receipt")
+	}
+
+	latest, err := bc.HeaderByNumber(nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get latest block header")
+	}
+
+	if latest.Number.Cmp(receipt.BlockNumber) < 0 {
+		return 0, nil
+	}
+
+	return new(big.Int).Sub(latest.Number, receipt.BlockNumber).Uint64(), nil
+}
+
+// BlockNumber returns the latest block number of the given chain.
+func (mbc *MultichainBlockchainClient) BlockNumber(chainID int64) (uint64, error) {
+	bc, err := mbc.getClientByChain(chainID)
+	if err != nil {
+		return 0, err
+	}
+
+	latest, err := bc.HeaderByNumber(nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get latest block header")
+	}
+
+	return latest.Number.Uint64(), nil
+}
+
+// NonceAt returns the confirmed nonce of the account at the latest block of the given chain.
+func (mbc *MultichainBlockchainClient) NonceAt(chainID int64, account common.Address) (uint64, error) {
+	bc, err := mbc.getClientByChain(chainID)
+	if err != nil {
+		return 0, err
+	}
+
+	nonce, err := bc.NonceAt(account)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get account nonce")
+	}
+
+	return nonce, nil
+}
+
+// PendingNonceAt returns the nonce of the account in the pending state of the given chain.
+func (mbc *MultichainBlockchainClient) PendingNonceAt(chainID int64, account common.Address) (uint64, error) {
+	bc, err := mbc.getClientByChain(chainID)
+	if err != nil {
+		return 0, err
+	}
+
+	nonce, err := bc.PendingNonceAt(account)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get account pending nonce")
+	}
+
+	return nonce, nil
+}
+
+// PendingTransactionCount returns the amount of transactions in the pending block of the given chain.
+func (mbc *MultichainBlockchainClient) PendingTransactionCount(chainID int64) (uint, error) {
+	bc, err := mbc.getClientByChain(chainID)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := bc.PendingTransactionCount()
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get pending transaction count")
+	}
+
+	return count, nil
+}
+
+// SuggestedGasPrice returns the gas price currently suggested by the given chain's node.
+//
+// It is not the protocol enforced minimum, which has no standard way of being queried,
+// but a market price that can be used as a floor when pricing transactions.
+func (mbc *MultichainBlockchainClient) SuggestedGasPrice(chainID int64) (*big.Int, error) {
+	bc, err := mbc.getClientByChain(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	return bc.SuggestGasPrice()
+}
+
 func (mbc *MultichainBlockchainClient) TransferEth(chainID int64, etr EthTransferRequest) (*types.Transaction, error) {
 	bc, err := mbc.getClientByChain(chainID)
 	if err != nil {
@@ -93,6 +93,48 @@ func (bwr *BlockchainWithRetries) HeaderByNumber(number *big.Int) (*types.Header
 	return res, err
 }
 
+// PendingTransactionCount returns the amount of transactions in the pending block.
+func (bwr *BlockchainWithRetries) PendingTransactionCount() (uint, error) {
+	var res uint
+	err := bwr.callWithRetry(func() error {
+		r, err := bwr.bc.PendingTransactionCount()
+		if err != nil {
+			return errors.Wrap(err, "could not get pending transaction count")
+		}
+		res = r
+		return nil
+	})
+	return res, err
+}
+
+// PendingNonceAt returns the nonce of the given account in the pending state.
+func (bwr *BlockchainWithRetries) PendingNonceAt(account common.Address) (uint64, error) {
+	var res uint64
+	err := bwr.callWithRetry(func() error {
+		r, err := bwr.bc.PendingNonceAt(account)
+		if err != nil {
+			return errors.Wrap(err, "could not get pending nonce")
+		}
+		res = r
+		return nil
+	})
+	return res, err
+}
+
+// NonceAt returns the confirmed nonce of the given account at the latest block.
+func (bwr *BlockchainWithRetries) NonceAt(account common.Address) (uint64, error) {
+	var res uint64
+	err := bwr.callWithRetry(func() error {
+		r, err := bwr.bc.NonceAt(account)
+		if err != nil {
+			return errors.Wrap(err, "could not get nonce")
+		}
+		res = r
+		return nil
+	})
+	return res, err
+}
+
 func (bwr *BlockchainWithRetries) SuggestGasPrice() (*big.Int, error) {
 	var res *big.Int
 	err := bwr.callWithRetry(func() error {
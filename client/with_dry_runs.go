@@ -318,6 +318,18 @@ func (cwdr *WithDryRuns) HeaderByNumber(number *big.Int) (*types.Header, error)
 	return cwdr.bc.HeaderByNumber(number)
 }
 
+func (cwdr *WithDryRuns) PendingTransactionCount() (uint, error) {
+	return cwdr.bc.PendingTransactionCount()
+}
+
+func (cwdr *WithDryRuns) PendingNonceAt(account common.Address) (uint64, error) {
+	return cwdr.bc.PendingNonceAt(account)
+}
+
+func (cwdr *WithDryRuns) NonceAt(account common.Address) (uint64, error) {
+	return cwdr.bc.NonceAt(account)
+}
+
 func (cwdr *WithDryRuns) SendTransaction(tx *types.Transaction) error {
 	return cwdr.bc.SendTransaction(tx)
 }
@@ -0,0 +1,193 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Promise validation outcomes recorded by the AuditingPromiseValidator.
+const (
+	AuditOutcomeAccepted = "accepted"
+	AuditOutcomeRejected = "rejected"
+)
+
+// AuditLogger records validated promises.
+type AuditLogger interface {
+	RecordPromise(p Promise, validatedAt time.Time, outcome string) error
+}
+
+// auditEntry is a single line of the audit log.
+type auditEntry struct {
+	ValidatedAt time.Time `json:"validatedAt"`
+	Outcome     string    `json:"outcome"`
+	Hash        string    `json:"hash"`
+	Promise     Promise   `json:"promise"`
+}
+
+// FileAuditLogger appends promises as newline delimited JSON to a file.
+type FileAuditLogger struct {
+	path string
+	f    *os.File
+	now  func() time.Time
+	m    sync.Mutex
+}
+
+// NewFileAuditLogger returns a new audit logger appending to the file at the given path.
+func NewFileAuditLogger(path string) (*FileAuditLogger, error) {
+	l := &FileAuditLogger{
+		path: path,
+		now:  time.Now,
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *FileAuditLogger) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	l.f = f
+	return nil
+}
+
+// RecordPromise appends the promise to the audit log.
+func (l *FileAuditLogger) RecordPromise(p Promise, validatedAt time.Time, outcome string) error {
+	line, err := json.Marshal(auditEntry{
+		ValidatedAt: validatedAt.UTC(),
+		Outcome:     outcome,
+		Hash:        p.Hash(),
+		Promise:     p,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	if _, err := l.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+// Rotate moves the current audit log to a file suffixed with the
+// current timestamp and starts a new audit log.
+//
+// Existing rotated logs are never replaced, a counter is appended to the
+// name if rotated more than once a second. If rotating fails, the audit
+// log is reopened so following records are still appended to it.
+func (l *FileAuditLogger) Rotate() error {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	err := l.rotate()
+	if openErr := l.open(); openErr != nil && err == nil {
+		err = openErr
+	}
+	return err
+}
+
+func (l *FileAuditLogger) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+
+	rotated, err := l.reserveRotated()
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(l.path, rotated); err != nil {
+		os.Remove(rotated)
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	return nil
+}
+
+// reserveRotated exclusively creates an empty file with an unused rotated name
+// so that the audit log can be renamed over it without replacing other logs.
+func (l *FileAuditLogger) reserveRotated() (string, error) {
+	base := fmt.Sprintf("%s.%s", l.path, l.now().UTC().Format("20060102T150405Z"))
+	for n := 0; ; n++ {
+		name := base
+		if n > 0 {
+			name = fmt.Sprintf("%s.%d", base, n)
+		}
+
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to create rotated audit log: %w", err)
+		}
+		return name, f.Close()
+	}
+}
+
+// Close closes the audit log.
+func (l *FileAuditLogger) Close() error {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	return l.f.Close()
+}
+
+// AuditingPromiseValidator records every promise validation to the audit log.
+type AuditingPromiseValidator struct {
+	validator *PromiseValidator
+	logger    AuditLogger
+}
+
+// NewAuditingPromiseValidator returns a validator recording all validations using the given logger.
+func NewAuditingPromiseValidator(validator *PromiseValidator, logger AuditLogger) *AuditingPromiseValidator {
+	return &AuditingPromiseValidator{
+		validator: validator,
+		logger:    logger,
+	}
+}
+
+// Validate validates the promise and records the outcome.
+//
+// An error is returned for valid promises which could not be recorded.
+func (v *AuditingPromiseValidator) Validate(p Promise) error {
+	validationErr := v.validator.Validate(p)
+
+	outcome := AuditOutcomeAccepted
+	if validationErr != nil {
+		outcome = AuditOutcomeRejected
+	}
+
+	if err := v.logger.RecordPromise(p, time.Now(), outcome); err != nil {
+		if validationErr != nil {
+			return validationErr
+		}
+		return fmt.Errorf("failed to record promise: %w", err)
+	}
+
+	return validationErr
+}
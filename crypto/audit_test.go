@@ -0,0 +1,96 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditingPromiseValidator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	logger, err := NewFileAuditLogger(path)
+	assert.NoError(t, err)
+	defer logger.Close()
+
+	readOutcomes := func(path string) []string {
+		f, err := os.Open(path)
+		assert.NoError(t, err)
+		defer f.Close()
+
+		var outcomes []string
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var entry auditEntry
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+			outcomes = append(outcomes, entry.Outcome)
+		}
+		return outcomes
+	}
+
+	validator := NewAuditingPromiseValidator(
+		NewInstrumentedPromiseValidator(common.HexToAddress("0xf53acdd584ccb85ee4ec1590007ad3c16fdff057"), nil),
+		logger,
+	)
+
+	valid := getPromise("consumer")
+	invalid := getPromise("consumer")
+	invalid.Amount.SetInt64(12345)
+	assert.NoError(t, validator.Validate(valid))
+	assert.Error(t, validator.Validate(invalid))
+	assert.Equal(t, []string{AuditOutcomeAccepted, AuditOutcomeRejected}, readOutcomes(path))
+
+	t.Run("rotates log file", func(t *testing.T) {
+		logger.now = func() time.Time { return time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC) }
+		assert.NoError(t, logger.Rotate())
+		assert.NoError(t, validator.Validate(valid))
+
+		assert.Equal(t, []string{AuditOutcomeAccepted, AuditOutcomeRejected}, readOutcomes(path+".20210701T120000Z"))
+		assert.Equal(t, []string{AuditOutcomeAccepted}, readOutcomes(path))
+	})
+	t.Run("rotating twice a second keeps both logs", func(t *testing.T) {
+		assert.NoError(t, logger.Rotate())
+		assert.NoError(t, validator.Validate(valid))
+		assert.NoError(t, validator.Validate(valid))
+		assert.NoError(t, logger.Rotate())
+
+		assert.Equal(t, []string{AuditOutcomeAccepted, AuditOutcomeRejected}, readOutcomes(path+".20210701T120000Z"))
+		assert.Equal(t, []string{AuditOutcomeAccepted}, readOutcomes(path+".20210701T120000Z.1"))
+		assert.Equal(t, []string{AuditOutcomeAccepted, AuditOutcomeAccepted}, readOutcomes(path+".20210701T120000Z.2"))
+	})
+	t.Run("failed rotation reopens the log", func(t *testing.T) {
+		assert.NoError(t, os.Remove(path))
+		assert.Error(t, logger.Rotate())
+		assert.NoError(t, validator.Validate(valid))
+		assert.Equal(t, []string{AuditOutcomeAccepted}, readOutcomes(path))
+
+		_, err := os.Stat(path + ".20210701T120000Z.3")
+		assert.True(t, os.IsNotExist(err), "reserved rotated log should be removed")
+	})
+}
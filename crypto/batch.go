@@ -0,0 +1,97 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrBatchAborted is returned for promises which were not validated
+// because of an earlier failure when EarlyExit is enabled.
+var ErrBatchAborted = errors.New("batch validation aborted")
+
+// ErrSignerCountMismatch is returned if the amount of expected signers does not match the amount of promises.
+var ErrSignerCountMismatch = errors.New("expected signer count does not match promise count")
+
+// BatchValidateConfig configures batch promise validation.
+type BatchValidateConfig struct {
+	// WorkerCount is the amount of promises validated in parallel.
+	// If zero, runtime.NumCPU() is used.
+	WorkerCount int
+	// EarlyExit stops validating remaining promises after the first failure.
+	EarlyExit bool
+}
+
+// BatchValidatePromises validates promises in parallel using
+// runtime.NumCPU() workers and returns an error for each promise.
+//
+// Each promise is validated against the expected signer with the same index.
+func BatchValidatePromises(promises []Promise, expectedSigners []common.Address) []error {
+	return BatchValidatePromisesWithConfig(BatchValidateConfig{}, promises, expectedSigners)
+}
+
+// BatchValidatePromisesWithConfig validates promises in parallel as configured
+// and returns an error for each promise, nil meaning the promise is valid.
+func BatchValidatePromisesWithConfig(cfg BatchValidateConfig, promises []Promise, expectedSigners []common.Address) []error {
+	errs := make([]error, len(promises))
+	if len(promises) != len(expectedSigners) {
+		for i := range errs {
+			errs[i] = ErrSignerCountMismatch
+		}
+		return errs
+	}
+
+	workers := cfg.WorkerCount
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan int)
+	var failed int32
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if cfg.EarlyExit && atomic.LoadInt32(&failed) == 1 {
+					errs[i] = ErrBatchAborted
+					continue
+				}
+
+				// Every worker writes to a distinct index only.
+				errs[i] = promises[i].ValidatePromise(expectedSigners[i])
+				if errs[i] != nil {
+					atomic.StoreInt32(&failed, 1)
+				}
+			}
+		}()
+	}
+
+	for i := range promises {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return errs
+}
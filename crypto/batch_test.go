@@ -0,0 +1,86 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func batchOf(n int) ([]Promise, []common.Address) {
+	signer := common.HexToAddress("0xf53acdd584ccb85ee4ec1590007ad3c16fdff057")
+	promises := make([]Promise, n)
+	signers := make([]common.Address, n)
+	for i := range promises {
+		promises[i] = getPromise("consumer")
+		signers[i] = signer
+	}
+	return promises, signers
+}
+
+func TestBatchValidatePromises(t *testing.T) {
+	t.Run("returns an error per promise", func(t *testing.T) {
+		promises, signers := batchOf(10)
+		signers[3] = common.HexToAddress("0x1")
+		signers[7] = common.HexToAddress("0x1")
+
+		errs := BatchValidatePromises(promises, signers)
+		assert.Len(t, errs, 10)
+		for i, err := range errs {
+			if i == 3 || i == 7 {
+				assert.True(t, errors.Is(err, ErrPromiseSignerMismatch))
+				continue
+			}
+			assert.NoError(t, err)
+		}
+	})
+	t.Run("stops after the first failure with early exit", func(t *testing.T) {
+		promises, signers := batchOf(10)
+		signers[0] = common.HexToAddress("0x1")
+
+		errs := BatchValidatePromisesWithConfig(BatchValidateConfig{WorkerCount: 1, EarlyExit: true}, promises, signers)
+		assert.True(t, errors.Is(errs[0], ErrPromiseSignerMismatch))
+		for _, err := range errs[1:] {
+			assert.Equal(t, ErrBatchAborted, err)
+		}
+	})
+	t.Run("fails if signer count mismatches", func(t *testing.T) {
+		promises, signers := batchOf(3)
+		for _, err := range BatchValidatePromises(promises, signers[:2]) {
+			assert.Equal(t, ErrSignerCountMismatch, err)
+		}
+	})
+}
+
+func BenchmarkValidatePromises(b *testing.B) {
+	promises, signers := batchOf(1000)
+	b.Run("sequential", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for i := range promises {
+				_ = promises[i].ValidatePromise(signers[i])
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			BatchValidatePromises(promises, signers)
+		}
+	})
+}
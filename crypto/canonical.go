@@ -0,0 +1,54 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrMalformedPromise is returned if a promise can not be canonicalized.
+var ErrMalformedPromise = errors.New("malformed promise")
+
+// Canonicalize returns a copy of the promise in its normalized form.
+//
+// Channel ID and hashlock are left padded to 32 bytes and missing amounts
+// are set to zero, which is the form used when hashing the promise.
+// Promises which are equal in their canonical form produce the same hash.
+func (p Promise) Canonicalize() (Promise, error) {
+	if len(p.ChannelID) == 0 || len(p.ChannelID) > 32 {
+		return Promise{}, fmt.Errorf("channel ID must be 1 to 32 bytes long, got %d: %w", len(p.ChannelID), ErrMalformedPromise)
+	}
+	if len(p.Hashlock) > 32 {
+		return Promise{}, fmt.Errorf("hashlock must be at most 32 bytes long, got %d: %w", len(p.Hashlock), ErrMalformedPromise)
+	}
+
+	c := p
+	c.ChannelID = Pad(append([]byte(nil), p.ChannelID...), 32)
+	c.Hashlock = Pad(append([]byte(nil), p.Hashlock...), 32)
+	c.Amount = canonicalAmount(p.Amount)
+	c.Fee = canonicalAmount(p.Fee)
+	return c, nil
+}
+
+func canonicalAmount(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(v)
+}
@@ -0,0 +1,67 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromiseCanonicalize(t *testing.T) {
+	t.Run("channel ID case does not change the hash", func(t *testing.T) {
+		lower, err := NewPromise(1, "0x25ee58a7c6d6ae0b5ffb6d7ab8b2a1b1a7c34c45", big.NewInt(10), big.NewInt(1), "0xab", "")
+		assert.NoError(t, err)
+		mixed, err := NewPromise(1, "25EE58a7c6D6ae0b5ffb6d7AB8b2a1b1a7c34C45", big.NewInt(10), big.NewInt(1), "AB", "")
+		assert.NoError(t, err)
+
+		lc, err := lower.Canonicalize()
+		assert.NoError(t, err)
+		mc, err := mixed.Canonicalize()
+		assert.NoError(t, err)
+		assert.Equal(t, lc, mc)
+		assert.Equal(t, lc.GetHash(), mc.GetHash())
+		assert.Equal(t, lower.GetHash(), lc.GetHash(), "canonical form should not change the hash")
+	})
+	t.Run("short channel IDs are padded", func(t *testing.T) {
+		p := getPromise("consumer")
+		p.ChannelID = p.ChannelID[12:]
+		p.Fee = nil
+
+		c, err := p.Canonicalize()
+		assert.NoError(t, err)
+		assert.Len(t, c.ChannelID, 32)
+		assert.Len(t, c.Hashlock, 32)
+		assert.Equal(t, big.NewInt(0), c.Fee)
+		assert.Nil(t, p.Fee, "original promise should not be modified")
+	})
+	t.Run("malformed channel ID is rejected", func(t *testing.T) {
+		p := getPromise("consumer")
+		p.ChannelID = make([]byte, 33)
+		_, err := p.Canonicalize()
+		assert.True(t, errors.Is(err, ErrMalformedPromise))
+
+		p.ChannelID = nil
+		_, err = p.Canonicalize()
+		assert.True(t, errors.Is(err, ErrMalformedPromise))
+
+		_, err = NewPromise(1, "0xnothex", big.NewInt(10), big.NewInt(1), "0xab", "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,96 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrInvalidChannelID is returned if a channel ID is not a proper hex address.
+var ErrInvalidChannelID = errors.New("invalid channel ID")
+
+// ErrChannelIDChecksum is returned if a channel ID does not match its EIP-55 checksum.
+var ErrChannelIDChecksum = errors.New("channel ID checksum mismatch")
+
+// channelIDHexLength is the length of a hex encoded address channel ID without the prefix.
+const channelIDHexLength = 2 * common.AddressLength
+
+// ValidateChannelID validates that the channel ID is a hex encoded address
+// with an optional `0x` prefix. The checksum is not checked.
+func ValidateChannelID(channelID string) error {
+	s := channelID
+	if hasHexPrefix(s) {
+		s = s[2:]
+	}
+
+	if len(s) != channelIDHexLength {
+		return fmt.Errorf("%w %q: expected %d hex characters, got %d", ErrInvalidChannelID, channelID, channelIDHexLength, len(s))
+	}
+	if !isHex(s) {
+		return fmt.Errorf("%w %q: not a hex string", ErrInvalidChannelID, channelID)
+	}
+
+	return nil
+}
+
+// ValidateChannelIDChecksum validates the channel ID like ValidateChannelID
+// also requiring it to be in its EIP-55 checksummed form.
+func ValidateChannelIDChecksum(channelID string) error {
+	normalized, err := NormalizeChannelID(channelID)
+	if err != nil {
+		return err
+	}
+
+	s := channelID
+	if !hasHexPrefix(s) {
+		s = "0x" + s
+	}
+	if s[:2] != "0x" || s[2:] != normalized[2:] {
+		return fmt.Errorf("%w: got %q, expected %q", ErrChannelIDChecksum, channelID, normalized)
+	}
+
+	return nil
+}
+
+// NormalizeChannelID returns the EIP-55 checksummed form of the channel ID.
+func NormalizeChannelID(channelID string) (string, error) {
+	if err := ValidateChannelID(channelID); err != nil {
+		return "", err
+	}
+
+	return common.HexToAddress(channelID).Hex(), nil
+}
+
+// validatePromiseChannelID validates the channel ID of a promise.
+//
+// Consumer channel IDs are addresses, while provider channel IDs
+// are 32 byte hashes as generated by GenerateProviderChannelID.
+func validatePromiseChannelID(channelID []byte) error {
+	switch len(channelID) {
+	case common.AddressLength:
+		_, err := NormalizeChannelID(hex.EncodeToString(channelID))
+		return err
+	case common.HashLength:
+		return nil
+	default:
+		return fmt.Errorf("%w: expected %d or %d bytes, got %d", ErrInvalidChannelID, common.AddressLength, common.HashLength, len(channelID))
+	}
+}
@@ -0,0 +1,108 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const checksummedChannelID = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+
+func TestValidateChannelID(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		id   string
+	}{
+		{"checksummed", checksummedChannelID},
+		{"lower case", strings.ToLower(checksummedChannelID)},
+		{"no prefix", checksummedChannelID[2:]},
+		{"upper case prefix", "0X" + checksummedChannelID[2:]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NoError(t, ValidateChannelID(tc.id))
+
+			normalized, err := NormalizeChannelID(tc.id)
+			assert.NoError(t, err)
+			assert.Equal(t, checksummedChannelID, normalized)
+		})
+	}
+
+	for _, tc := range []struct {
+		name string
+		id   string
+	}{
+		{"empty", ""},
+		{"prefix only", "0x"},
+		{"too short", checksummedChannelID[:41]},
+		{"too long", checksummedChannelID + "00"},
+		{"odd length", checksummedChannelID + "0"},
+		{"hash length", "0x" + strings.Repeat("ab", 32)},
+		{"non hex", "0x" + strings.Repeat("zz", 20)},
+		{"non hex without prefix", strings.Repeat("g", 40)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, ValidateChannelID(tc.id), ErrInvalidChannelID)
+
+			_, err := NormalizeChannelID(tc.id)
+			assert.ErrorIs(t, err, ErrInvalidChannelID)
+		})
+	}
+}
+
+func TestValidateChannelIDChecksum(t *testing.T) {
+	assert.NoError(t, ValidateChannelIDChecksum(checksummedChannelID))
+	assert.NoError(t, ValidateChannelIDChecksum(checksummedChannelID[2:]))
+	assert.ErrorIs(t, ValidateChannelIDChecksum(strings.ToLower(checksummedChannelID)), ErrChannelIDChecksum)
+	assert.ErrorIs(t, ValidateChannelIDChecksum("0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"), ErrChannelIDChecksum)
+	assert.ErrorIs(t, ValidateChannelIDChecksum("0x123"), ErrInvalidChannelID)
+}
+
+func TestPromise_GetMessageErr(t *testing.T) {
+	p := getPromise("consumer")
+
+	message, err := p.GetMessageErr()
+	assert.NoError(t, err)
+	assert.Equal(t, p.GetMessage(), message)
+
+	p.ChannelID = common.HexToHash("0x1").Bytes()
+	_, err = p.GetMessageErr()
+	assert.NoError(t, err, "provider channel IDs are 32 byte hashes")
+
+	for _, id := range [][]byte{nil, {1}, make([]byte, 21), make([]byte, 33)} {
+		p.ChannelID = id
+		_, err := p.GetMessageErr()
+		assert.ErrorIs(t, err, ErrInvalidChannelID)
+		assert.NotPanics(t, func() { p.GetMessage() })
+		assert.NotPanics(t, func() { p.GetHash() })
+
+		_, err = p.GetHashErr()
+		assert.ErrorIs(t, err, ErrInvalidChannelID)
+		_, err = p.RecoverSigner()
+		assert.ErrorIs(t, err, ErrInvalidChannelID)
+		assert.Error(t, p.ValidatePromise(common.Address{}))
+
+		rw, err := NewReplayWindow(time.Minute, 10)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, rw.CheckAndRecord(p), ErrInvalidChannelID)
+	}
+	assert.NotPanics(t, func() { Promise{}.GetHash() })
+}
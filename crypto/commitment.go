@@ -0,0 +1,58 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"crypto/subtle"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrInvalidCommitmentAmount is returned if a promise amount can't be committed to.
+var ErrInvalidCommitmentAmount = errors.New("promise amount must be a non negative 256 bit number")
+
+// CommitmentScheme commits to promise amounts allowing to prove
+// holding a promise without revealing the amount.
+//
+// The commitment is a placeholder for a Pedersen commitment and is
+// calculated as `keccak256(abi.encode(amount, blinding))`.
+// Zero knowledge proofs of the commitment are not supported.
+type CommitmentScheme struct{}
+
+// Commit returns a commitment to the promise amount using the blinding factor.
+func (CommitmentScheme) Commit(p Promise, blinding [32]byte) ([32]byte, error) {
+	if p.Amount == nil || p.Amount.Sign() < 0 || p.Amount.BitLen() > 256 {
+		return [32]byte{}, ErrInvalidCommitmentAmount
+	}
+
+	var commitment [32]byte
+	copy(commitment[:], crypto.Keccak256(math.U256Bytes(new(big.Int).Set(p.Amount)), blinding[:]))
+	return commitment, nil
+}
+
+// Reveal returns true if the commitment was created for the promise amount using the blinding factor.
+func (cs CommitmentScheme) Reveal(commitment [32]byte, p Promise, blinding [32]byte) bool {
+	expected, err := cs.Commit(p, blinding)
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(expected[:], commitment[:]) == 1
+}
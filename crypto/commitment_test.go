@@ -0,0 +1,57 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitmentScheme(t *testing.T) {
+	var cs CommitmentScheme
+	p := getPromise("consumer")
+	blinding := common.HexToHash("0xc0ffee")
+
+	commitment, err := cs.Commit(p, blinding)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.Keccak256(math.U256Bytes(big.NewInt(1401)), blinding[:]), commitment[:])
+	assert.Equal(t, "1401", p.Amount.String(), "amount should not be modified")
+	assert.True(t, cs.Reveal(commitment, p, blinding))
+
+	t.Run("modified amount", func(t *testing.T) {
+		modified := p
+		modified.Amount = big.NewInt(1402)
+		assert.False(t, cs.Reveal(commitment, modified, blinding))
+	})
+	t.Run("different blinding", func(t *testing.T) {
+		assert.False(t, cs.Reveal(commitment, p, common.HexToHash("0xbad")))
+	})
+	t.Run("invalid amount", func(t *testing.T) {
+		for _, amount := range []*big.Int{nil, big.NewInt(-1), new(big.Int).Lsh(big.NewInt(1), 256)} {
+			invalid := p
+			invalid.Amount = amount
+			_, err := cs.Commit(invalid, blinding)
+			assert.ErrorIs(t, err, ErrInvalidCommitmentAmount)
+			assert.False(t, cs.Reveal(commitment, invalid, blinding))
+		}
+	})
+}
@@ -0,0 +1,142 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CompactPromiseVersion is the version of the compact promise encoding.
+const CompactPromiseVersion uint8 = 1
+
+// CompactPromiseSize is the length of a compactly encoded promise:
+// version, channel address, amount, fee, hashlock and signature.
+const CompactPromiseSize = 1 + common.AddressLength + 8 + 8 + 32 + 65
+
+// ErrCompactEncoding is returned if a promise can't be compactly encoded or decoded.
+var ErrCompactEncoding = errors.New("invalid compact promise encoding")
+
+// SignedPromise is a signed promise which can be sent over the wire in a compact binary form.
+//
+// Only V1 promises of consumer channels are supported. Chain ID is not
+// encoded and should be known by both parties from the context.
+type SignedPromise struct {
+	Promise
+}
+
+// MarshalCompact encodes the promise in a fixed layout of CompactPromiseSize bytes.
+func (sp SignedPromise) MarshalCompact() ([]byte, error) {
+	return sp.appendCompact(make([]byte, 0, CompactPromiseSize))
+}
+
+// appendCompact appends the compact encoding of the promise to data.
+func (sp SignedPromise) appendCompact(data []byte) ([]byte, error) {
+	p := sp.Promise
+	if p.Version > PromiseVersionV1 || p.ExpiresAt != 0 || p.ServiceType != "" {
+		return nil, fmt.Errorf("only v1 promises without expiration and service type can be encoded: %w", ErrCompactEncoding)
+	}
+
+	channel := Pad(p.ChannelID, 32)
+	if len(channel) != 32 || !bytes.Equal(channel[:32-common.AddressLength], make([]byte, 32-common.AddressLength)) {
+		return nil, fmt.Errorf("channel ID must be an address: %w", ErrCompactEncoding)
+	}
+
+	amount, err := compactUint64(p.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("amount %w", err)
+	}
+	fee, err := compactUint64(p.Fee)
+	if err != nil {
+		return nil, fmt.Errorf("fee %w", err)
+	}
+
+	if len(p.Hashlock) > 32 {
+		return nil, fmt.Errorf("hashlock must be at most 32 bytes: %w", ErrCompactEncoding)
+	}
+	if len(p.Signature) != 65 {
+		return nil, fmt.Errorf("signature must be 65 bytes: %w", ErrCompactEncoding)
+	}
+
+	data = append(data, CompactPromiseVersion)
+	data = append(data, channel[32-common.AddressLength:]...)
+	data = append(data, amount...)
+	data = append(data, fee...)
+	data = append(data, Pad(p.Hashlock, 32)...)
+	data = append(data, p.Signature...)
+	return data, nil
+}
+
+// UnmarshalCompact decodes a compactly encoded promise.
+//
+// The channel ID is decoded as a 20 byte address.
+// Fields which are not part of the encoding are left untouched.
+func (sp *SignedPromise) UnmarshalCompact(data []byte) error {
+	if len(data) != CompactPromiseSize {
+		return fmt.Errorf("got %d bytes, expected %d: %w", len(data), CompactPromiseSize, ErrCompactEncoding)
+	}
+	if data[0] != CompactPromiseVersion {
+		return fmt.Errorf("unsupported version %d: %w", data[0], ErrCompactEncoding)
+	}
+
+	data = data[1:]
+	sp.ChannelID = append([]byte(nil), data[:common.AddressLength]...)
+	data = data[common.AddressLength:]
+	sp.Amount = new(big.Int).SetUint64(binary.BigEndian.Uint64(data[:8]))
+	sp.Fee = new(big.Int).SetUint64(binary.BigEndian.Uint64(data[8:16]))
+	sp.Hashlock = append([]byte(nil), data[16:48]...)
+	sp.Signature = append([]byte(nil), data[48:]...)
+	return nil
+}
+
+// MarshalBase64URL encodes the promise compactly
+// as unpadded base64 safe to use in URLs.
+func (sp SignedPromise) MarshalBase64URL() (string, error) {
+	data, err := sp.MarshalCompact()
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// UnmarshalBase64URL decodes a promise encoded by MarshalBase64URL.
+func (sp *SignedPromise) UnmarshalBase64URL(s string) error {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("%v: %w", err, ErrCompactEncoding)
+	}
+	return sp.UnmarshalCompact(data)
+}
+
+func compactUint64(v *big.Int) ([]byte, error) {
+	b := make([]byte, 8)
+	if v == nil {
+		return b, nil
+	}
+	if v.Sign() < 0 || !v.IsUint64() {
+		return nil, fmt.Errorf("does not fit in 8 bytes: %w", ErrCompactEncoding)
+	}
+
+	binary.BigEndian.PutUint64(b, v.Uint64())
+	return b, nil
+}
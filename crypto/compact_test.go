@@ -0,0 +1,83 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignedPromiseCompact(t *testing.T) {
+	t.Run("round trips promise", func(t *testing.T) {
+		original := SignedPromise{Promise: getPromise("consumer")}
+		original.ChannelID = common.BytesToAddress(original.ChannelID).Bytes()
+		original.Hashlock = Pad(original.Hashlock, 32)
+
+		data, err := original.MarshalCompact()
+		assert.NoError(t, err)
+		assert.Len(t, data, 134)
+		assert.Equal(t, CompactPromiseSize, len(data))
+
+		decoded := SignedPromise{Promise: Promise{ChainID: original.ChainID}}
+		assert.NoError(t, decoded.UnmarshalCompact(data))
+		assert.Equal(t, original.ChannelID, decoded.ChannelID)
+		assert.Equal(t, original, decoded)
+		assert.Equal(t, original.GetHash(), decoded.GetHash())
+	})
+	t.Run("decodes padded channel IDs as addresses", func(t *testing.T) {
+		original := SignedPromise{Promise: getPromise("consumer")}
+		original.Hashlock = Pad(original.Hashlock, 32)
+
+		data, err := original.MarshalCompact()
+		assert.NoError(t, err)
+
+		var decoded SignedPromise
+		assert.NoError(t, decoded.UnmarshalCompact(data))
+		assert.Equal(t, original.ChannelID[32-common.AddressLength:], decoded.ChannelID)
+		assert.Len(t, decoded.ChannelID, common.AddressLength)
+	})
+	t.Run("rejects promises which can't be encoded", func(t *testing.T) {
+		provider := SignedPromise{Promise: getPromise("provider")}
+		_, err := provider.MarshalCompact()
+		assert.True(t, errors.Is(err, ErrCompactEncoding), "provider channel ID is not an address")
+
+		large := SignedPromise{Promise: getPromise("consumer")}
+		large.Amount = new(big.Int).Lsh(big.NewInt(1), 64)
+		_, err = large.MarshalCompact()
+		assert.True(t, errors.Is(err, ErrCompactEncoding))
+
+		typed := SignedPromise{Promise: getPromise("consumer")}
+		typed.ServiceType = "wireguard"
+		_, err = typed.MarshalCompact()
+		assert.True(t, errors.Is(err, ErrCompactEncoding))
+	})
+	t.Run("strictly checks length and version", func(t *testing.T) {
+		data, err := SignedPromise{Promise: getPromise("consumer")}.MarshalCompact()
+		assert.NoError(t, err)
+
+		var sp SignedPromise
+		assert.True(t, errors.Is(sp.UnmarshalCompact(data[:133]), ErrCompactEncoding))
+		assert.True(t, errors.Is(sp.UnmarshalCompact(append(data, 0)), ErrCompactEncoding))
+
+		data[0] = 2
+		assert.True(t, errors.Is(sp.UnmarshalCompact(data), ErrCompactEncoding))
+	})
+}
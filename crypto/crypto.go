@@ -26,8 +26,21 @@ import (
 )
 
 // RecoverAddress recovers the address from message and signature
-func RecoverAddress(message []byte, signature []byte) (common.Address, error) {
-	publicKey, err := crypto.Ecrecover(crypto.Keccak256(message), signature)
+//
+// The message is hashed according to the given recovery mode,
+// RecoveryModeRaw is used if none is given.
+func RecoverAddress(message []byte, signature []byte, mode ...RecoveryMode) (common.Address, error) {
+	m := RecoveryModeRaw
+	if len(mode) > 0 {
+		m = mode[0]
+	}
+
+	hash, err := m.hash(message)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	publicKey, err := crypto.Ecrecover(hash, signature)
 	if err != nil {
 		return common.Address{}, err
 	}
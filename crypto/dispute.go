@@ -0,0 +1,112 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DisputeRecordMaxAge is how long a dispute record is considered fresh.
+const DisputeRecordMaxAge = 24 * time.Hour
+
+// disputeClockSkew is how far in the future a dispute record timestamp is accepted.
+const disputeClockSkew = time.Minute
+
+// ErrDisputeRecordSignerMismatch is returned if the dispute record is not signed by it's reporter.
+var ErrDisputeRecordSignerMismatch = errors.New("dispute record is not signed by the reporter")
+
+// ErrDisputeRecordStale is returned if the dispute record timestamp is too old or in the future.
+var ErrDisputeRecordStale = errors.New("dispute record timestamp is not fresh")
+
+// DisputeRecord is a signed statement of a reporter claiming a promise was invalid.
+// It can be used for off-chain dispute resolution.
+type DisputeRecord struct {
+	Promise           Promise
+	Reason            string
+	ReporterAddress   common.Address
+	Timestamp         int64
+	ReporterSignature []byte
+}
+
+// CreateDisputeRecord creates a dispute record for the promise signed by the reporter.
+func CreateDisputeRecord(p Promise, reason string, reporter *ecdsa.PrivateKey) (*DisputeRecord, error) {
+	if err := validatePromiseChannelID(p.ChannelID); err != nil {
+		return nil, err
+	}
+
+	dr := &DisputeRecord{
+		Promise:         p,
+		Reason:          reason,
+		ReporterAddress: crypto.PubkeyToAddress(reporter.PublicKey),
+		Timestamp:       time.Now().Unix(),
+	}
+
+	signature, err := crypto.Sign(dr.hash(), reporter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign dispute record: %w", err)
+	}
+	if err := ReformatSignatureVForBC(signature); err != nil {
+		return nil, fmt.Errorf("failed to reformat dispute record signature: %w", err)
+	}
+
+	dr.ReporterSignature = signature
+	return dr, nil
+}
+
+// VerifyDisputeRecord verifies that the dispute record is signed by the reporter and is fresh.
+func VerifyDisputeRecord(dr *DisputeRecord) error {
+	if err := validatePromiseChannelID(dr.Promise.ChannelID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	created := time.Unix(dr.Timestamp, 0)
+	if created.After(now.Add(disputeClockSkew)) || now.Sub(created) > DisputeRecordMaxAge {
+		return fmt.Errorf("timestamp %d: %w", dr.Timestamp, ErrDisputeRecordStale)
+	}
+
+	signature := append([]byte(nil), dr.ReporterSignature...)
+	if err := ReformatSignatureVForRecovery(signature); err != nil {
+		return fmt.Errorf("invalid dispute record signature: %w", err)
+	}
+
+	pubKey, err := crypto.SigToPub(dr.hash(), signature)
+	if err != nil {
+		return fmt.Errorf("failed to recover dispute record signer: %w", err)
+	}
+
+	if recovered := crypto.PubkeyToAddress(*pubKey); recovered != dr.ReporterAddress {
+		return fmt.Errorf("got %s, expected %s: %w", recovered.Hex(), dr.ReporterAddress.Hex(), ErrDisputeRecordSignerMismatch)
+	}
+
+	return nil
+}
+
+// hash returns the keccak hash of the promise hash, reason and timestamp.
+func (dr *DisputeRecord) hash() []byte {
+	timestamp := make([]byte, 8)
+	binary.BigEndian.PutUint64(timestamp, uint64(dr.Timestamp))
+
+	return crypto.Keccak256(dr.Promise.GetHash(), []byte(dr.Reason), timestamp)
+}
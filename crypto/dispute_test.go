@@ -0,0 +1,60 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisputeRecord(t *testing.T) {
+	reporter, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	t.Run("wrongly flagged valid promise creates a valid record", func(t *testing.T) {
+		promise := getPromise("consumer")
+		assert.True(t, promise.IsPromiseValid(common.HexToAddress("0xf53acdd584ccb85ee4ec1590007ad3c16fdff057")))
+
+		dr, err := CreateDisputeRecord(promise, "bad signature", reporter)
+		assert.NoError(t, err)
+		assert.Equal(t, crypto.PubkeyToAddress(reporter.PublicKey), dr.ReporterAddress)
+		assert.Len(t, dr.ReporterSignature, 65)
+		assert.NoError(t, VerifyDisputeRecord(dr))
+	})
+	t.Run("tampering invalidates the record", func(t *testing.T) {
+		dr, err := CreateDisputeRecord(getPromise("consumer"), "expired", reporter)
+		assert.NoError(t, err)
+
+		dr.Reason = "wrong channel"
+		assert.True(t, errors.Is(VerifyDisputeRecord(dr), ErrDisputeRecordSignerMismatch))
+	})
+	t.Run("stale records are rejected", func(t *testing.T) {
+		dr, err := CreateDisputeRecord(getPromise("consumer"), "expired", reporter)
+		assert.NoError(t, err)
+
+		dr.Timestamp = time.Now().Add(-DisputeRecordMaxAge - time.Minute).Unix()
+		assert.True(t, errors.Is(VerifyDisputeRecord(dr), ErrDisputeRecordStale))
+
+		dr.Timestamp = time.Now().Add(time.Hour).Unix()
+		assert.True(t, errors.Is(VerifyDisputeRecord(dr), ErrDisputeRecordStale))
+	})
+}
@@ -0,0 +1,75 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/hkdf"
+)
+
+// ErrInvalidECDHKey is returned if a key can't be used for the ECDH key exchange.
+var ErrInvalidECDHKey = errors.New("invalid secp256k1 key")
+
+// channelKeyInfo is the HKDF info prefix of derived channel keys.
+const channelKeyInfo = "mysterium channel key "
+
+// ECDHExchange returns the shared secret of the local private key and the remote
+// public key on the secp256k1 curve. The secret is the x coordinate of the shared point.
+func ECDHExchange(localKey *ecdsa.PrivateKey, remotePublicKey *ecdsa.PublicKey) ([32]byte, error) {
+	curve := crypto.S256()
+	if localKey == nil || localKey.D == nil || localKey.D.Sign() <= 0 || localKey.D.Cmp(curve.Params().N) >= 0 {
+		return [32]byte{}, fmt.Errorf("%w: local key is not a valid private key", ErrInvalidECDHKey)
+	}
+	if remotePublicKey == nil || remotePublicKey.X == nil || remotePublicKey.Y == nil || !curve.IsOnCurve(remotePublicKey.X, remotePublicKey.Y) {
+		return [32]byte{}, fmt.Errorf("%w: remote key is not on the curve", ErrInvalidECDHKey)
+	}
+
+	x, _ := curve.ScalarMult(remotePublicKey.X, remotePublicKey.Y, math.PaddedBigBytes(localKey.D, 32))
+	if x.Sign() == 0 {
+		return [32]byte{}, fmt.Errorf("%w: shared point is at infinity", ErrInvalidECDHKey)
+	}
+
+	var secret [32]byte
+	copy(secret[:], math.PaddedBigBytes(x, 32))
+	return secret, nil
+}
+
+// DeriveChannelKey derives a key for the given channel out of the
+// shared secret returned by ECDHExchange using HKDF-SHA256.
+func DeriveChannelKey(sharedSecret [32]byte, channelID string) ([32]byte, error) {
+	if channelID == "" {
+		return [32]byte{}, errors.New("channel ID must be provided")
+	}
+	if sharedSecret == ([32]byte{}) {
+		return [32]byte{}, errors.New("shared secret must not be empty")
+	}
+
+	var key [32]byte
+	kdf := hkdf.New(sha256.New, sharedSecret[:], nil, []byte(channelKeyInfo+channelID))
+	if _, err := io.ReadFull(kdf, key[:]); err != nil {
+		return [32]byte{}, fmt.Errorf("failed to derive channel key: %w", err)
+	}
+
+	return key, nil
+}
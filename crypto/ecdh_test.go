@@ -0,0 +1,84 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECDHExchange(t *testing.T) {
+	alice, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	bob, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	aliceSecret, err := ECDHExchange(alice, &bob.PublicKey)
+	assert.NoError(t, err)
+	bobSecret, err := ECDHExchange(bob, &alice.PublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, aliceSecret, bobSecret)
+	assert.NotEqual(t, [32]byte{}, aliceSecret)
+
+	eve, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	eveSecret, err := ECDHExchange(eve, &bob.PublicKey)
+	assert.NoError(t, err)
+	assert.NotEqual(t, aliceSecret, eveSecret)
+
+	t.Run("invalid keys", func(t *testing.T) {
+		p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.NoError(t, err)
+
+		_, err = ECDHExchange(alice, &p256.PublicKey)
+		assert.ErrorIs(t, err, ErrInvalidECDHKey, "public key of another curve")
+		_, err = ECDHExchange(alice, nil)
+		assert.ErrorIs(t, err, ErrInvalidECDHKey)
+		_, err = ECDHExchange(nil, &bob.PublicKey)
+		assert.ErrorIs(t, err, ErrInvalidECDHKey)
+	})
+}
+
+func TestDeriveChannelKey(t *testing.T) {
+	alice, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	bob, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	secret, err := ECDHExchange(alice, &bob.PublicKey)
+	assert.NoError(t, err)
+
+	first, err := DeriveChannelKey(secret, "0xd2c94475763fa7e81076ab0bde4dc4b902191498")
+	assert.NoError(t, err)
+	again, err := DeriveChannelKey(secret, "0xd2c94475763fa7e81076ab0bde4dc4b902191498")
+	assert.NoError(t, err)
+	assert.Equal(t, first, again)
+
+	second, err := DeriveChannelKey(secret, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	assert.NotEqual(t, secret, first)
+
+	_, err = DeriveChannelKey(secret, "")
+	assert.Error(t, err)
+	_, err = DeriveChannelKey([32]byte{}, "channel")
+	assert.Error(t, err)
+}
@@ -0,0 +1,187 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// eip712PromiseType is the primary type name of exported promises.
+const eip712PromiseType = "Promise"
+
+// ErrInvalidEIP712JSON is returned if EIP-712 JSON does not describe a promise.
+var ErrInvalidEIP712JSON = errors.New("invalid EIP-712 promise JSON")
+
+// EIP712Domain is the EIP-712 domain promises are exported in.
+type EIP712Domain struct {
+	Name              string         `json:"name"`
+	Version           string         `json:"version"`
+	ChainID           int64          `json:"chainId"`
+	VerifyingContract common.Address `json:"verifyingContract"`
+}
+
+type eip712Type struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type eip712Promise struct {
+	Types       map[string][]eip712Type `json:"types"`
+	PrimaryType string                  `json:"primaryType"`
+	Domain      EIP712Domain            `json:"domain"`
+	Message     eip712PromiseMessage    `json:"message"`
+	// Signature is not part of EIP-712 and is ignored by wallets.
+	Signature hexutil.Bytes `json:"signature,omitempty"`
+}
+
+// eip712PromiseMessage holds promise fields with uint256 values
+// encoded as decimal strings.
+type eip712PromiseMessage struct {
+	Version     uint8         `json:"version"`
+	ChannelID   hexutil.Bytes `json:"channelId"`
+	ChainID     string        `json:"chainId"`
+	Amount      string        `json:"amount"`
+	Fee         string        `json:"fee"`
+	Hashlock    hexutil.Bytes `json:"hashlock"`
+	ExpiresAt   string        `json:"expiresAt"`
+	ServiceType string        `json:"serviceType"`
+}
+
+var eip712PromiseTypes = map[string][]eip712Type{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	eip712PromiseType: {
+		{Name: "version", Type: "uint8"},
+		{Name: "channelId", Type: "bytes32"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "amount", Type: "uint256"},
+		{Name: "fee", Type: "uint256"},
+		{Name: "hashlock", Type: "bytes32"},
+		{Name: "expiresAt", Type: "uint256"},
+		{Name: "serviceType", Type: "string"},
+	},
+}
+
+// ExportAsEIP712JSON returns the promise as EIP-712 typed data JSON
+// which can be passed to `eth_signTypedData` and displayed by wallets.
+//
+// The promise is exported in its canonical form. If the promise is signed,
+// the signature is added as a top level `signature` field.
+func ExportAsEIP712JSON(p Promise, domain EIP712Domain) ([]byte, error) {
+	c, err := p.Canonicalize()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(eip712Promise{
+		Types:       eip712PromiseTypes,
+		PrimaryType: eip712PromiseType,
+		Domain:      domain,
+		Message: eip712PromiseMessage{
+			Version:     c.Version,
+			ChannelID:   c.ChannelID,
+			ChainID:     big.NewInt(c.ChainID).String(),
+			Amount:      c.Amount.String(),
+			Fee:         c.Fee.String(),
+			Hashlock:    c.Hashlock,
+			ExpiresAt:   big.NewInt(c.ExpiresAt).String(),
+			ServiceType: c.ServiceType,
+		},
+		Signature: c.Signature,
+	})
+}
+
+// ImportFromEIP712JSON parses EIP-712 typed data JSON created by ExportAsEIP712JSON
+// returning the promise and its signature. Signature is nil if the JSON has none.
+func ImportFromEIP712JSON(data []byte) (*Promise, []byte, error) {
+	var typed eip712Promise
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return nil, nil, fmt.Errorf("%v: %w", err, ErrInvalidEIP712JSON)
+	}
+	if typed.PrimaryType != eip712PromiseType {
+		return nil, nil, fmt.Errorf("primary type %q is not %q: %w", typed.PrimaryType, eip712PromiseType, ErrInvalidEIP712JSON)
+	}
+
+	msg := typed.Message
+	if len(msg.ChannelID) != 32 || len(msg.Hashlock) != 32 {
+		return nil, nil, fmt.Errorf("channel ID and hashlock must be 32 bytes: %w", ErrInvalidEIP712JSON)
+	}
+
+	chainID, err := parseEIP712Int64("chainId", msg.ChainID)
+	if err != nil {
+		return nil, nil, err
+	}
+	expiresAt, err := parseEIP712Int64("expiresAt", msg.ExpiresAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	amount, err := parseEIP712Uint256("amount", msg.Amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	fee, err := parseEIP712Uint256("fee", msg.Fee)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sig []byte
+	if len(typed.Signature) > 0 {
+		sig = []byte(typed.Signature)
+	}
+
+	return &Promise{
+		Version:     msg.Version,
+		ChannelID:   []byte(msg.ChannelID),
+		ChainID:     chainID,
+		Amount:      amount,
+		Fee:         fee,
+		Hashlock:    []byte(msg.Hashlock),
+		Signature:   sig,
+		ExpiresAt:   expiresAt,
+		ServiceType: msg.ServiceType,
+	}, sig, nil
+}
+
+func parseEIP712Uint256(name, value string) (*big.Int, error) {
+	v, ok := math.ParseBig256(value)
+	if !ok || v.Sign() < 0 {
+		return nil, fmt.Errorf("%s %q is not a uint256: %w", name, value, ErrInvalidEIP712JSON)
+	}
+	return v, nil
+}
+
+func parseEIP712Int64(name, value string) (int64, error) {
+	v, err := parseEIP712Uint256(name, value)
+	if err != nil {
+		return 0, err
+	}
+	if !v.IsInt64() {
+		return 0, fmt.Errorf("%s %q overflows int64: %w", name, value, ErrInvalidEIP712JSON)
+	}
+	return v.Int64(), nil
+}
@@ -0,0 +1,83 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEIP712JSON(t *testing.T) {
+	domain := EIP712Domain{
+		Name:              "Mysterium",
+		Version:           "1",
+		ChainID:           1,
+		VerifyingContract: common.HexToAddress("0x599d43715DF3070f83355D9D90AE62c159E62A75"),
+	}
+	p := getPromise("consumer")
+	p.Version = PromiseVersionV2
+	p.ExpiresAt = 1700000000
+	p.ServiceType = "wireguard"
+	p.Amount = new(big.Int).Lsh(big.NewInt(1), 200)
+
+	data, err := ExportAsEIP712JSON(p, domain)
+	assert.NoError(t, err)
+
+	t.Run("conforms to EIP-712 structure", func(t *testing.T) {
+		var typed map[string]json.RawMessage
+		assert.NoError(t, json.Unmarshal(data, &typed))
+		for _, key := range []string{"types", "primaryType", "domain", "message"} {
+			assert.Contains(t, typed, key)
+		}
+
+		var types map[string][]map[string]string
+		assert.NoError(t, json.Unmarshal(typed["types"], &types))
+		assert.Contains(t, types, "EIP712Domain")
+		assert.Contains(t, types, "Promise")
+		assert.JSONEq(t, `"Promise"`, string(typed["primaryType"]))
+		assert.JSONEq(t, `{"name":"Mysterium","version":"1","chainId":1,"verifyingContract":"0x599d43715df3070f83355d9d90ae62c159e62a75"}`, string(typed["domain"]))
+	})
+	t.Run("round trips", func(t *testing.T) {
+		imported, sig, err := ImportFromEIP712JSON(data)
+		assert.NoError(t, err)
+		assert.Equal(t, p.Signature, sig)
+		assert.Equal(t, p.Version, imported.Version)
+		assert.Equal(t, p.ChannelID, imported.ChannelID)
+		assert.Equal(t, p.ChainID, imported.ChainID)
+		assert.Equal(t, p.Amount, imported.Amount)
+		assert.Equal(t, p.Fee, imported.Fee)
+		assert.Equal(t, p.Hashlock, imported.Hashlock)
+		assert.Equal(t, p.ExpiresAt, imported.ExpiresAt)
+		assert.Equal(t, p.ServiceType, imported.ServiceType)
+		assert.True(t, p.SemanticEquals(*imported))
+	})
+	t.Run("rejects other types", func(t *testing.T) {
+		for _, data := range []string{
+			`not json`,
+			`{"primaryType":"Mail","message":{}}`,
+			`{"primaryType":"Promise","message":{"channelId":"0x01","hashlock":"0x02"}}`,
+		} {
+			_, _, err := ImportFromEIP712JSON([]byte(data))
+			assert.True(t, errors.Is(err, ErrInvalidEIP712JSON), data)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrFeeProposalExpired is returned when accepting an expired fee proposal.
+var ErrFeeProposalExpired = errors.New("fee proposal has expired")
+
+// ErrFeeAcceptanceSignerMismatch is returned if a fee acceptance is not signed by the expected client.
+var ErrFeeAcceptanceSignerMismatch = errors.New("fee acceptance is not signed by the expected client")
+
+// FeeProposal is a fee offered by a service provider which
+// the client has to counter-sign before the session starts.
+type FeeProposal struct {
+	ServiceType       string
+	ProposedFee       uint64
+	ExpiresAt         int64
+	ProviderSignature []byte
+}
+
+// CreateFeeProposal creates a fee proposal valid for the given ttl signed by the provider.
+func CreateFeeProposal(serviceType string, fee uint64, ttl time.Duration, provider *ecdsa.PrivateKey) (*FeeProposal, error) {
+	fp := &FeeProposal{
+		ServiceType: serviceType,
+		ProposedFee: fee,
+		ExpiresAt:   time.Now().Add(ttl).Unix(),
+	}
+
+	signature, err := signForBC(fp.hash(), provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign fee proposal: %w", err)
+	}
+
+	fp.ProviderSignature = signature
+	return fp, nil
+}
+
+// Provider recovers the address of the provider which signed the proposal.
+func (fp *FeeProposal) Provider() (common.Address, error) {
+	provider, err := recoverFromBC(fp.hash(), fp.ProviderSignature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid fee proposal signature: %w", err)
+	}
+
+	return provider, nil
+}
+
+// AcceptFeeProposal verifies the provider signature and expiry of the
+// proposal and returns the counter-signature of the client.
+func AcceptFeeProposal(proposal *FeeProposal, client *ecdsa.PrivateKey) ([]byte, error) {
+	if _, err := proposal.Provider(); err != nil {
+		return nil, err
+	}
+	if time.Now().Unix() > proposal.ExpiresAt {
+		return nil, fmt.Errorf("expired at %d: %w", proposal.ExpiresAt, ErrFeeProposalExpired)
+	}
+
+	signature, err := signForBC(proposal.acceptanceHash(), client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign fee acceptance: %w", err)
+	}
+
+	return signature, nil
+}
+
+// VerifyFeeAcceptance verifies that the proposal is signed by a provider
+// and counter-signed by the expected client.
+//
+// Expiry is not checked as it only applies to accepting the proposal.
+func VerifyFeeAcceptance(proposal *FeeProposal, clientSig []byte, expectedClient common.Address) error {
+	if _, err := proposal.Provider(); err != nil {
+		return err
+	}
+
+	client, err := recoverFromBC(proposal.acceptanceHash(), clientSig)
+	if err != nil {
+		return fmt.Errorf("invalid fee acceptance signature: %w", err)
+	}
+	if client != expectedClient {
+		return fmt.Errorf("got %s, expected %s: %w", client.Hex(), expectedClient.Hex(), ErrFeeAcceptanceSignerMismatch)
+	}
+
+	return nil
+}
+
+// hash returns the keccak hash of the service type, fee and expiration.
+func (fp *FeeProposal) hash() []byte {
+	fee := make([]byte, 8)
+	binary.BigEndian.PutUint64(fee, fp.ProposedFee)
+	expiresAt := make([]byte, 8)
+	binary.BigEndian.PutUint64(expiresAt, uint64(fp.ExpiresAt))
+
+	return crypto.Keccak256([]byte(fp.ServiceType), fee, expiresAt)
+}
+
+// acceptanceHash returns the hash signed by the client, binding the acceptance to the provider signature.
+func (fp *FeeProposal) acceptanceHash() []byte {
+	return crypto.Keccak256(fp.hash(), fp.ProviderSignature)
+}
+
+func signForBC(hash []byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	signature, err := crypto.Sign(hash, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := ReformatSignatureVForBC(signature); err != nil {
+		return nil, err
+	}
+
+	return signature, nil
+}
+
+func recoverFromBC(hash, signature []byte) (common.Address, error) {
+	signature = append([]byte(nil), signature...)
+	if err := ReformatSignatureVForRecovery(signature); err != nil {
+		return common.Address{}, err
+	}
+
+	pubKey, err := crypto.SigToPub(hash, signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
@@ -0,0 +1,66 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeProposalHandshake(t *testing.T) {
+	provider, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	client, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	clientAddress := crypto.PubkeyToAddress(client.PublicKey)
+
+	proposal, err := CreateFeeProposal("wireguard", 1000, time.Minute, provider)
+	assert.NoError(t, err)
+
+	recovered, err := proposal.Provider()
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(provider.PublicKey), recovered)
+
+	acceptance, err := AcceptFeeProposal(proposal, client)
+	assert.NoError(t, err)
+	assert.NoError(t, VerifyFeeAcceptance(proposal, acceptance, clientAddress))
+
+	t.Run("rejects acceptance of other client", func(t *testing.T) {
+		err := VerifyFeeAcceptance(proposal, acceptance, crypto.PubkeyToAddress(provider.PublicKey))
+		assert.True(t, errors.Is(err, ErrFeeAcceptanceSignerMismatch))
+	})
+	t.Run("rejects tampered proposal", func(t *testing.T) {
+		tampered := *proposal
+		tampered.ProposedFee = 1
+		assert.Error(t, VerifyFeeAcceptance(&tampered, acceptance, clientAddress))
+	})
+	t.Run("rejects accepting expired proposal", func(t *testing.T) {
+		expired, err := CreateFeeProposal("wireguard", 1000, -time.Minute, provider)
+		assert.NoError(t, err)
+
+		_, err = AcceptFeeProposal(expired, client)
+		assert.True(t, errors.Is(err, ErrFeeProposalExpired))
+	})
+	t.Run("rejects proposal without signature", func(t *testing.T) {
+		_, err := AcceptFeeProposal(&FeeProposal{ServiceType: "wireguard", ExpiresAt: time.Now().Add(time.Minute).Unix()}, client)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,72 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// FeeSchedule holds the minimal promise fee keyed by service type.
+type FeeSchedule map[string]uint64
+
+// ErrUnknownServiceType is returned if a service type is not present in the fee schedule.
+var ErrUnknownServiceType = errors.New("service type not found in fee schedule")
+
+// ErrFeeTooLow is returned if a promise fee is lower than required by the fee schedule.
+var ErrFeeTooLow = errors.New("promise fee is lower than expected")
+
+// ErrServiceTypeNotSigned is returned if a promise version does not sign its service type.
+var ErrServiceTypeNotSigned = errors.New("promise version does not sign the service type")
+
+// ExpectedFee returns the fee expected for the promise service type.
+func (p Promise) ExpectedFee(schedule FeeSchedule) (uint64, error) {
+	fee, ok := schedule[p.ServiceType]
+	if !ok {
+		return 0, fmt.Errorf("service type %q: %w", p.ServiceType, ErrUnknownServiceType)
+	}
+
+	return fee, nil
+}
+
+// ValidatePromiseWithSchedule validates if the promise is signed by the expected signer
+// and that it's fee satisfies the fee schedule of its service type.
+//
+// Only promises of PromiseVersionV2 and later are accepted as older versions do not sign the service type.
+func (p Promise) ValidatePromiseWithSchedule(expectedSigner common.Address, schedule FeeSchedule) error {
+	if p.Version < PromiseVersionV2 {
+		return fmt.Errorf("version %d: %w", p.Version, ErrServiceTypeNotSigned)
+	}
+
+	if err := p.ValidatePromise(expectedSigner); err != nil {
+		return err
+	}
+
+	expected, err := p.ExpectedFee(schedule)
+	if err != nil {
+		return err
+	}
+
+	if p.Fee == nil || p.Fee.Cmp(new(big.Int).SetUint64(expected)) < 0 {
+		return fmt.Errorf("got %v, expected at least %d for service type %q: %w", p.Fee, expected, p.ServiceType, ErrFeeTooLow)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePromiseWithSchedule(t *testing.T) {
+	dir, ks := tmpKeyStore(t, false)
+	defer os.RemoveAll(dir)
+
+	account, err := ks.ImportECDSA(getPrivKey("consumer"), "")
+	assert.NoError(t, err)
+	assert.NoError(t, ks.Unlock(account, ""))
+
+	schedule := FeeSchedule{"wireguard": 100, "scraping": 500}
+	newPromise := func(serviceType string, fee int64) Promise {
+		p := getPromise("consumer")
+		p.Version = PromiseVersionV2
+		p.ExpiresAt = 1700000000
+		p.ServiceType = serviceType
+		p.Fee = big.NewInt(fee)
+		assert.NoError(t, p.Sign(ks, account.Address))
+		return p
+	}
+
+	t.Run("service type is part of the signed message", func(t *testing.T) {
+		p := newPromise("wireguard", 100)
+		assert.NoError(t, p.ValidatePromise(account.Address))
+
+		p.ServiceType = "scraping"
+		assert.True(t, errors.Is(p.ValidatePromise(account.Address), ErrPromiseSignerMismatch))
+	})
+	t.Run("service type is not part of the V1 message", func(t *testing.T) {
+		p := getPromise("consumer")
+		message := p.GetMessageV1()
+
+		p.ServiceType = "wireguard"
+		assert.Equal(t, message, p.GetMessageV1())
+	})
+	t.Run("rejects versions not signing the service type", func(t *testing.T) {
+		p := newPromise("wireguard", 100)
+		p.Version = PromiseVersionV1
+		assert.NoError(t, p.Sign(ks, account.Address))
+
+		err := p.ValidatePromiseWithSchedule(account.Address, schedule)
+		assert.True(t, errors.Is(err, ErrServiceTypeNotSigned))
+	})
+	t.Run("accepts fees satisfying the schedule", func(t *testing.T) {
+		assert.NoError(t, newPromise("wireguard", 100).ValidatePromiseWithSchedule(account.Address, schedule))
+		assert.NoError(t, newPromise("scraping", 600).ValidatePromiseWithSchedule(account.Address, schedule))
+	})
+	t.Run("rejects under charged fee even with a valid signature", func(t *testing.T) {
+		p := newPromise("scraping", 100)
+		assert.NoError(t, p.ValidatePromise(account.Address))
+
+		err := p.ValidatePromiseWithSchedule(account.Address, schedule)
+		assert.True(t, errors.Is(err, ErrFeeTooLow))
+	})
+	t.Run("rejects unknown service types", func(t *testing.T) {
+		fee, err := newPromise("residential", 100).ExpectedFee(schedule)
+		assert.True(t, errors.Is(err, ErrUnknownServiceType))
+		assert.Equal(t, uint64(0), fee)
+	})
+}
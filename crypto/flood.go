@@ -0,0 +1,146 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// FloodStats holds promise flood statistics of a single channel.
+type FloodStats struct {
+	AllowedCount uint64
+	BlockedCount uint64
+	IsBlocked    bool
+}
+
+// FloodDetector limits the amount of promises accepted per channel
+// using a token bucket for every channel.
+type FloodDetector struct {
+	MaxPerSecond int
+	// BurstSize is the amount of promises allowed at once.
+	// If not given it defaults to MaxPerSecond.
+	BurstSize int
+	// BlockDuration is optional and makes channels exceeding
+	// the limit get blocked for the given duration.
+	BlockDuration time.Duration
+
+	channels map[string]*floodChannel
+	now      func() time.Time
+	m        sync.Mutex
+}
+
+type floodChannel struct {
+	limiter      *rate.Limiter
+	blockedUntil time.Time
+	stats        FloodStats
+}
+
+// NewFloodDetector returns a new flood detector allowing maxPerSecond promises per channel.
+func NewFloodDetector(maxPerSecond, burstSize int) *FloodDetector {
+	return &FloodDetector{
+		MaxPerSecond: maxPerSecond,
+		BurstSize:    burstSize,
+		channels:     make(map[string]*floodChannel),
+		now:          time.Now,
+	}
+}
+
+// Allow returns true if another promise can be accepted for the channel.
+func (fd *FloodDetector) Allow(channelID string) bool {
+	fd.m.Lock()
+	defer fd.m.Unlock()
+
+	fd.init()
+	now := fd.now()
+	ch := fd.channel(channelID)
+	if ch.isBlocked(now) {
+		ch.stats.BlockedCount++
+		return false
+	}
+
+	if !ch.limiter.AllowN(now, 1) {
+		ch.stats.BlockedCount++
+		if fd.BlockDuration > 0 {
+			ch.blockedUntil = now.Add(fd.BlockDuration)
+		}
+		return false
+	}
+
+	ch.stats.AllowedCount++
+	return true
+}
+
+// Block rejects all promises of the channel until the given time.
+func (fd *FloodDetector) Block(channelID string, until time.Time) {
+	fd.m.Lock()
+	defer fd.m.Unlock()
+
+	fd.init()
+	fd.channel(channelID).blockedUntil = until
+}
+
+// Stats returns flood statistics of every channel seen.
+func (fd *FloodDetector) Stats() map[string]FloodStats {
+	fd.m.Lock()
+	defer fd.m.Unlock()
+
+	fd.init()
+	now := fd.now()
+	res := make(map[string]FloodStats, len(fd.channels))
+	for id, ch := range fd.channels {
+		stats := ch.stats
+		stats.IsBlocked = ch.isBlocked(now)
+		res[id] = stats
+	}
+
+	return res
+}
+
+// init allows using a FloodDetector created without NewFloodDetector.
+// Caller must hold the lock.
+func (fd *FloodDetector) init() {
+	if fd.channels == nil {
+		fd.channels = make(map[string]*floodChannel)
+	}
+	if fd.now == nil {
+		fd.now = time.Now
+	}
+}
+
+// channel returns the state of the channel creating it if needed.
+// Caller must hold the lock.
+func (fd *FloodDetector) channel(channelID string) *floodChannel {
+	ch, ok := fd.channels[channelID]
+	if !ok {
+		burst := fd.BurstSize
+		if burst <= 0 {
+			burst = fd.MaxPerSecond
+		}
+
+		ch = &floodChannel{limiter: rate.NewLimiter(rate.Limit(fd.MaxPerSecond), burst)}
+		fd.channels[channelID] = ch
+	}
+
+	return ch
+}
+
+func (ch *floodChannel) isBlocked(now time.Time) bool {
+	return now.Before(ch.blockedUntil)
+}
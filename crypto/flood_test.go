@@ -0,0 +1,92 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFloodDetector(t *testing.T) {
+	start := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	newDetector := func() (*FloodDetector, *time.Time) {
+		now := start
+		fd := NewFloodDetector(100, 1)
+		fd.now = func() time.Time { return now }
+		return fd, &now
+	}
+
+	t.Run("blocks promises above the limit", func(t *testing.T) {
+		fd, now := newDetector()
+
+		allowed := 0
+		for n := 0; n < 1000; n++ {
+			if fd.Allow("flooding") {
+				allowed++
+			}
+			*now = now.Add(time.Millisecond)
+		}
+
+		assert.InDelta(t, 100, allowed, 1)
+		stats := fd.Stats()["flooding"]
+		assert.Equal(t, uint64(allowed), stats.AllowedCount)
+		assert.Equal(t, uint64(1000-allowed), stats.BlockedCount)
+		assert.False(t, stats.IsBlocked)
+	})
+	t.Run("channels have independent limits", func(t *testing.T) {
+		fd, _ := newDetector()
+
+		assert.True(t, fd.Allow("first"))
+		assert.False(t, fd.Allow("first"))
+		assert.True(t, fd.Allow("second"))
+		assert.Len(t, fd.Stats(), 2)
+	})
+	t.Run("manually blocked channel is unblocked after window", func(t *testing.T) {
+		fd, now := newDetector()
+		fd.Block("blocked", start.Add(time.Minute))
+
+		*now = start.Add(30 * time.Second)
+		assert.False(t, fd.Allow("blocked"))
+		assert.True(t, fd.Stats()["blocked"].IsBlocked)
+		assert.True(t, fd.Allow("other"))
+
+		*now = start.Add(time.Minute)
+		assert.True(t, fd.Allow("blocked"))
+		assert.Equal(t, FloodStats{AllowedCount: 1, BlockedCount: 1}, fd.Stats()["blocked"])
+	})
+	t.Run("flooding channel is blocked for block duration", func(t *testing.T) {
+		fd, now := newDetector()
+		fd.BlockDuration = time.Second
+
+		assert.True(t, fd.Allow("flooding"))
+		assert.False(t, fd.Allow("flooding"))
+
+		*now = start.Add(500 * time.Millisecond)
+		assert.False(t, fd.Allow("flooding"), "channel should stay blocked although tokens are available")
+		assert.True(t, fd.Stats()["flooding"].IsBlocked)
+
+		*now = start.Add(time.Second)
+		assert.True(t, fd.Allow("flooding"))
+	})
+	t.Run("zero value is usable", func(t *testing.T) {
+		fd := &FloodDetector{MaxPerSecond: 1}
+		assert.True(t, fd.Allow("channel"))
+		assert.False(t, fd.Allow("channel"))
+	})
+}
@@ -0,0 +1,106 @@
+//go:build go1.18
+// +build go1.18
+
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fuzzSeedPromises returns compact encodings of valid and edge case promises.
+func fuzzSeedPromises(f *testing.F) [][]byte {
+	valid := SignedPromise{Promise: getPromise("consumer")}
+	data, err := valid.MarshalCompact()
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	zero := make([]byte, CompactPromiseSize)
+	zero[0] = CompactPromiseVersion
+
+	max := make([]byte, CompactPromiseSize)
+	for i := range max {
+		max[i] = 0xff
+	}
+	max[0] = CompactPromiseVersion
+
+	badVersion := append([]byte(nil), data...)
+	badVersion[0] = CompactPromiseVersion + 1
+
+	return [][]byte{data, zero, max, badVersion, data[:CompactPromiseSize-1], append(data, 0), {}}
+}
+
+// fuzzDecodedPromise exercises the decoded promise, none of which should panic.
+func fuzzDecodedPromise(t *testing.T, sp SignedPromise) {
+	sp.GetMessage()
+	sp.GetHash()
+	if err := sp.ValidatePromise(common.Address{}); err == nil {
+		t.Fatal("promise should not be valid for the zero address")
+	}
+}
+
+func FuzzPromiseUnmarshalCompact(f *testing.F) {
+	for _, seed := range fuzzSeedPromises(f) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var sp SignedPromise
+		if err := sp.UnmarshalCompact(data); err != nil {
+			return
+		}
+		fuzzDecodedPromise(t, sp)
+	})
+}
+
+func FuzzPromiseBase64URL(f *testing.F) {
+	for _, seed := range fuzzSeedPromises(f) {
+		var sp SignedPromise
+		if err := sp.UnmarshalCompact(seed); err != nil {
+			continue
+		}
+		encoded, err := sp.MarshalBase64URL()
+		if err != nil {
+			continue
+		}
+		f.Add(encoded)
+	}
+	f.Add("")
+	f.Add("not base64!")
+	f.Add("AQ==")
+
+	f.Fuzz(func(t *testing.T, encoded string) {
+		var sp SignedPromise
+		if err := sp.UnmarshalBase64URL(encoded); err != nil {
+			return
+		}
+		fuzzDecodedPromise(t, sp)
+
+		reencoded, err := sp.MarshalBase64URL()
+		if err != nil {
+			t.Fatalf("decoded promise should encode: %v", err)
+		}
+		var again SignedPromise
+		if err := again.UnmarshalBase64URL(reencoded); err != nil {
+			t.Fatalf("reencoded promise should decode: %v", err)
+		}
+	})
+}
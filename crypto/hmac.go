@@ -0,0 +1,91 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// HMACPromise is a payment promise authenticated with a HMAC-SHA256 code.
+//
+// It can be used instead of an ECDSA signature between parties which share a secret key
+// where recovering the signer of every promise is too expensive.
+type HMACPromise struct {
+	Promise
+	HMACSignature []byte
+	HMACKeyID     string
+}
+
+// HMACKeyStore returns shared keys by their ID, allowing keys to be rotated.
+type HMACKeyStore interface {
+	GetKey(keyID string) ([]byte, error)
+}
+
+// CreateHMACSignature returns the HMAC-SHA256 of the promise message using the given key.
+func (p HMACPromise) CreateHMACSignature(key []byte) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, errors.New("hmac key must not be empty")
+	}
+
+	message, err := p.GetMessageErr()
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(message)
+	return mac.Sum(nil), nil
+}
+
+// SignHMAC signs the promise using the given key and records the key ID.
+func (p *HMACPromise) SignHMAC(keyID string, key []byte) error {
+	signature, err := p.CreateHMACSignature(key)
+	if err != nil {
+		return err
+	}
+
+	p.HMACSignature = signature
+	p.HMACKeyID = keyID
+	return nil
+}
+
+// ValidateHMACPromise validates if the promise is authenticated with the given key.
+func (p HMACPromise) ValidateHMACPromise(key []byte) bool {
+	expected, err := p.CreateHMACSignature(key)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(expected, p.HMACSignature)
+}
+
+// ValidateHMACPromiseWithKeyStore validates the promise using the key referenced by its key ID.
+func (p HMACPromise) ValidateHMACPromiseWithKeyStore(ks HMACKeyStore) error {
+	key, err := ks.GetKey(p.HMACKeyID)
+	if err != nil {
+		return fmt.Errorf("failed to get hmac key %q: %w", p.HMACKeyID, err)
+	}
+
+	if !p.ValidateHMACPromise(key) {
+		return fmt.Errorf("invalid hmac signature for key %q", p.HMACKeyID)
+	}
+
+	return nil
+}
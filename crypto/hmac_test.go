@@ -0,0 +1,75 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mapKeyStore map[string][]byte
+
+func (m mapKeyStore) GetKey(keyID string) ([]byte, error) {
+	key, ok := m[keyID]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return key, nil
+}
+
+func TestHMACPromise(t *testing.T) {
+	key := []byte("secret")
+
+	t.Run("validates with the same key", func(t *testing.T) {
+		p := HMACPromise{Promise: getPromise("consumer")}
+		assert.NoError(t, p.SignHMAC("k1", key))
+
+		assert.True(t, p.ValidateHMACPromise(key))
+		assert.False(t, p.ValidateHMACPromise([]byte("other")))
+		assert.False(t, p.ValidateHMACPromise(nil))
+	})
+	t.Run("tampered amount invalidates the mac", func(t *testing.T) {
+		p := HMACPromise{Promise: getPromise("consumer")}
+		assert.NoError(t, p.SignHMAC("k1", key))
+
+		p.Amount = new(big.Int).Add(p.Amount, big.NewInt(1))
+		assert.False(t, p.ValidateHMACPromise(key))
+	})
+	t.Run("validates with a rotated key using key ID", func(t *testing.T) {
+		ks := mapKeyStore{"k1": []byte("old"), "k2": []byte("new")}
+
+		old := HMACPromise{Promise: getPromise("consumer")}
+		assert.NoError(t, old.SignHMAC("k1", ks["k1"]))
+		current := HMACPromise{Promise: getPromise("provider")}
+		assert.NoError(t, current.SignHMAC("k2", ks["k2"]))
+
+		assert.NoError(t, old.ValidateHMACPromiseWithKeyStore(ks))
+		assert.NoError(t, current.ValidateHMACPromiseWithKeyStore(ks))
+
+		old.HMACKeyID = "k2"
+		assert.Error(t, old.ValidateHMACPromiseWithKeyStore(ks))
+		old.HMACKeyID = "missing"
+		assert.Error(t, old.ValidateHMACPromiseWithKeyStore(ks))
+	})
+	t.Run("empty key is rejected", func(t *testing.T) {
+		p := HMACPromise{Promise: getPromise("consumer")}
+		assert.Error(t, p.SignHMAC("k1", nil))
+	})
+}
@@ -122,8 +122,8 @@ func (m ExchangeMessage) GetMessage() []byte {
 	binary.BigEndian.PutUint64(b, uint64(m.ChainID))
 	message = append(message, Pad(b, 32)...)
 	message = append(message, m.Promise.GetHash()...)
-	message = append(message, Pad(math.U256(m.AgreementID).Bytes(), 32)...)
-	message = append(message, Pad(math.U256(m.AgreementTotal).Bytes(), 32)...)
+	message = append(message, Pad(math.U256(canonicalAmount(m.AgreementID)).Bytes(), 32)...)
+	message = append(message, Pad(math.U256(canonicalAmount(m.AgreementTotal)).Bytes(), 32)...)
 	message = append(message, common.HexToAddress(m.Provider).Bytes()...)
 
 	// TODO: once all the consumers upgrade, this check needs to go to
@@ -149,6 +149,10 @@ func (m ExchangeMessage) CreateSignature(ks hashSigner, signer common.Address) (
 
 // RecoverConsumerIdentity recovers the identity from the given request
 func (m ExchangeMessage) RecoverConsumerIdentity() (common.Address, error) {
+	if err := validatePromiseChannelID(m.Promise.ChannelID); err != nil {
+		return common.Address{}, err
+	}
+
 	signature := m.GetSignatureBytesRaw()
 
 	err := ReformatSignatureVForRecovery(signature)
@@ -59,6 +59,11 @@ func TestExchangeMessageValidation(t *testing.T) {
 
 	wrongSigner := common.HexToAddress("0xf10021ba3b10d023e671668d20daeff821561d09")
 	assert.False(t, message.IsMessageValid(wrongSigner))
+
+	message.Promise.ChannelID = []byte{1, 2, 3}
+	assert.NotPanics(t, func() {
+		assert.False(t, message.IsMessageValid(expectedSigner), "malformed channel ID should be rejected")
+	})
 }
 
 func TestCreateExchangeMessage(t *testing.T) {
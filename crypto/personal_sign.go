@@ -0,0 +1,64 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrPersonalSignSignerMismatch is returned if a personal sign signature was not made by the expected address.
+var ErrPersonalSignSignerMismatch = errors.New("personal sign signature signer mismatch")
+
+// PersonalSign signs the message using the Ethereum signed message prefix
+// producing a signature compatible with `personal_sign` wallets.
+//
+// Returned signature V is in the Ethereum format (27/28).
+func PersonalSign(message []byte, ks hashSigner, signer common.Address) ([]byte, error) {
+	sig, err := ks.SignHash(accounts.Account{Address: signer}, accounts.TextHash(message))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign message: %w", err)
+	}
+
+	if err := ReformatSignatureVForBC(sig); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// VerifyPersonalSign returns an error if the signature of the
+// message was not made by the expected address using `personal_sign`.
+func VerifyPersonalSign(message []byte, sig []byte, expectedAddr common.Address) error {
+	normalized := make([]byte, len(sig))
+	copy(normalized, sig)
+	if err := ReformatSignatureVForRecovery(normalized); err != nil {
+		return err
+	}
+
+	recovered, err := RecoverAddress(message, normalized, RecoveryModePrefix)
+	if err != nil {
+		return fmt.Errorf("failed to recover signer: %w", err)
+	}
+	if recovered != expectedAddr {
+		return fmt.Errorf("expected %s, got %s: %w", expectedAddr.Hex(), recovered.Hex(), ErrPersonalSignSignerMismatch)
+	}
+
+	return nil
+}
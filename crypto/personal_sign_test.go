@@ -0,0 +1,57 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersonalSign(t *testing.T) {
+	dir, ks := tmpKeyStore(t, false)
+	defer os.RemoveAll(dir)
+
+	account, err := ks.ImportECDSA(getPrivKey("consumer"), "")
+	assert.NoError(t, err)
+	assert.NoError(t, ks.Unlock(account, ""))
+
+	message := []byte("Sign in to Mysterium")
+	sig, err := PersonalSign(message, ks, account.Address)
+	assert.NoError(t, err)
+	assert.Len(t, sig, 65)
+	assert.Contains(t, []byte{27, 28}, sig[64])
+
+	assert.NoError(t, VerifyPersonalSign(message, sig, account.Address))
+	assert.ErrorIs(t, VerifyPersonalSign(message, sig, common.HexToAddress("0x1")), ErrPersonalSignSignerMismatch)
+	assert.ErrorIs(t, VerifyPersonalSign([]byte("other message"), sig, account.Address), ErrPersonalSignSignerMismatch)
+
+	t.Run("reference implementation", func(t *testing.T) {
+		prefixed := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)
+		hash := crypto.Keccak256([]byte(prefixed))
+
+		recoverySig := append([]byte{}, sig...)
+		recoverySig[64] -= 27
+		pub, err := crypto.SigToPub(hash, recoverySig)
+		assert.NoError(t, err)
+		assert.Equal(t, account.Address, crypto.PubkeyToAddress(*pub))
+	})
+}
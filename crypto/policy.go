@@ -0,0 +1,156 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrServiceTypeNotAllowed is returned if the promise service type is not allowed by the policy.
+var ErrServiceTypeNotAllowed = errors.New("promise service type is not allowed")
+
+// Policy holds the rules promises of a channel are validated against.
+type Policy struct {
+	AuthorizedSigners []common.Address
+	MinAmount         uint64
+	// MaxFee is the highest accepted fee. Zero disables the check.
+	MaxFee uint64
+	// AllowedServiceTypes lists accepted service types. Empty list allows any.
+	AllowedServiceTypes []string
+}
+
+// PolicyProvider returns the current policy of a channel.
+//
+// Channels are keyed by their EIP-55 checksummed address as returned by
+// NormalizeChannelID for 20 byte channel IDs and by their 0x prefixed
+// lowercase hex form for 32 byte ones.
+type PolicyProvider interface {
+	GetPolicy(channelID string) (Policy, error)
+}
+
+// DynamicValidatePromise validates the promise against the current policy of its channel.
+func DynamicValidatePromise(p Promise, provider PolicyProvider) error {
+	if err := p.ValidatePromiseSize(); err != nil {
+		return err
+	}
+
+	channelID, err := policyChannelID(p.ChannelID)
+	if err != nil {
+		return err
+	}
+	policy, err := provider.GetPolicy(channelID)
+	if err != nil {
+		return fmt.Errorf("failed to get policy of channel %s: %w", channelID, err)
+	}
+
+	signer, err := p.RecoverSigner()
+	if err != nil {
+		return fmt.Errorf("failed to recover promise signer: %w", err)
+	}
+	if !containsAddress(policy.AuthorizedSigners, signer) {
+		return fmt.Errorf("signer %s is not authorized: %w", signer.Hex(), ErrPromiseSignerMismatch)
+	}
+
+	if p.Amount == nil || p.Amount.Cmp(new(big.Int).SetUint64(policy.MinAmount)) < 0 {
+		return fmt.Errorf("got %v, expected at least %d: %w", p.Amount, policy.MinAmount, ErrPromiseAmountOutOfRange)
+	}
+	if policy.MaxFee > 0 && p.Fee != nil && p.Fee.Cmp(new(big.Int).SetUint64(policy.MaxFee)) > 0 {
+		return fmt.Errorf("got %v, expected at most %d: %w", p.Fee, policy.MaxFee, ErrPromiseFeeTooHigh)
+	}
+	if len(policy.AllowedServiceTypes) > 0 && !containsString(policy.AllowedServiceTypes, p.ServiceType) {
+		return fmt.Errorf("service type %q: %w", p.ServiceType, ErrServiceTypeNotAllowed)
+	}
+
+	return nil
+}
+
+// CachingPolicyProvider caches policies of a backend provider for the given TTL.
+type CachingPolicyProvider struct {
+	backend PolicyProvider
+	ttl     time.Duration
+
+	entries map[string]cachedPolicy
+	now     func() time.Time
+	m       sync.Mutex
+}
+
+type cachedPolicy struct {
+	policy    Policy
+	fetchedAt time.Time
+}
+
+// NewCachingPolicyProvider returns a new provider caching backend policies for ttl.
+func NewCachingPolicyProvider(backend PolicyProvider, ttl time.Duration) *CachingPolicyProvider {
+	return &CachingPolicyProvider{
+		backend: backend,
+		ttl:     ttl,
+		entries: make(map[string]cachedPolicy),
+		now:     time.Now,
+	}
+}
+
+// GetPolicy returns the cached policy of the channel fetching it from the backend if expired.
+func (c *CachingPolicyProvider) GetPolicy(channelID string) (Policy, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	now := c.now()
+	if entry, ok := c.entries[channelID]; ok && now.Sub(entry.fetchedAt) < c.ttl {
+		return entry.policy, nil
+	}
+
+	policy, err := c.backend.GetPolicy(channelID)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	c.entries[channelID] = cachedPolicy{policy: policy, fetchedAt: now}
+	return policy, nil
+}
+
+// policyChannelID returns the key the channel policy is looked up by.
+func policyChannelID(channelID []byte) (string, error) {
+	if len(channelID) == common.AddressLength {
+		return NormalizeChannelID(hex.EncodeToString(channelID))
+	}
+	return common.BytesToHash(channelID).Hex(), nil
+}
+
+func containsAddress(addresses []common.Address, addr common.Address) bool {
+	for _, a := range addresses {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// mapPolicyProvider holds policies keyed by channel ID.
+type mapPolicyProvider struct {
+	policies map[string]Policy
+	calls    int
+}
+
+func (m *mapPolicyProvider) GetPolicy(channelID string) (Policy, error) {
+	m.calls++
+	policy, ok := m.policies[channelID]
+	if !ok {
+		return Policy{}, errors.New("unknown channel")
+	}
+	return policy, nil
+}
+
+func TestDynamicValidatePromise(t *testing.T) {
+	p := getPromise("consumer")
+	channelID := "0x000000000000000000000000d2c94475763fa7e81076ab0bde4dc4b902191498"
+	signer := common.HexToAddress("0xf53acdd584ccb85ee4ec1590007ad3c16fdff057")
+
+	backend := &mapPolicyProvider{policies: map[string]Policy{
+		channelID: {AuthorizedSigners: []common.Address{signer}, MinAmount: 1000},
+	}}
+	assert.NoError(t, DynamicValidatePromise(p, backend))
+
+	t.Run("rejects by policy rules", func(t *testing.T) {
+		rules := []struct {
+			policy Policy
+			err    error
+		}{
+			{Policy{AuthorizedSigners: []common.Address{{1}}}, ErrPromiseSignerMismatch},
+			{Policy{AuthorizedSigners: []common.Address{signer}, MinAmount: 1402}, ErrPromiseAmountOutOfRange},
+			{Policy{AuthorizedSigners: []common.Address{signer}, AllowedServiceTypes: []string{"wireguard"}}, ErrServiceTypeNotAllowed},
+		}
+		for _, r := range rules {
+			err := DynamicValidatePromise(p, &mapPolicyProvider{policies: map[string]Policy{channelID: r.policy}})
+			assert.True(t, errors.Is(err, r.err), err)
+		}
+
+		withFee := p
+		withFee.Fee = big.NewInt(100)
+		// Signature no longer matches, so authorize whoever is recovered.
+		recovered, err := withFee.RecoverSigner()
+		assert.NoError(t, err)
+		err = DynamicValidatePromise(withFee, &mapPolicyProvider{policies: map[string]Policy{
+			channelID: {AuthorizedSigners: []common.Address{recovered}, MaxFee: 10},
+		}})
+		assert.True(t, errors.Is(err, ErrPromiseFeeTooHigh))
+	})
+	t.Run("address channels are looked up by checksummed address", func(t *testing.T) {
+		short := p
+		short.ChannelID = common.HexToAddress(channelID).Bytes()
+		// Signature no longer matches, so authorize whoever is recovered.
+		recovered, err := short.RecoverSigner()
+		assert.NoError(t, err)
+
+		err = DynamicValidatePromise(short, &mapPolicyProvider{policies: map[string]Policy{
+			"0xD2C94475763fa7e81076AB0BDe4dc4B902191498": {AuthorizedSigners: []common.Address{recovered}},
+		}})
+		assert.NoError(t, err)
+	})
+	t.Run("policy changes apply once cache expires", func(t *testing.T) {
+		backend.calls = 0
+		cached := NewCachingPolicyProvider(backend, time.Minute)
+		now := time.Now()
+		cached.now = func() time.Time { return now }
+
+		for n := 0; n < 3; n++ {
+			assert.NoError(t, DynamicValidatePromise(p, cached))
+		}
+		assert.Equal(t, 1, backend.calls)
+
+		backend.policies[channelID] = Policy{AuthorizedSigners: []common.Address{{1}}}
+		assert.NoError(t, DynamicValidatePromise(p, cached), "cached policy should still be used")
+
+		now = now.Add(time.Minute)
+		assert.True(t, errors.Is(DynamicValidatePromise(p, cached), ErrPromiseSignerMismatch))
+		assert.Equal(t, 2, backend.calls)
+	})
+}
@@ -17,6 +17,7 @@
 package crypto
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
@@ -32,6 +33,9 @@ import (
 
 // Promise is payment promise object
 type Promise struct {
+	// Version is the message format version of the promise.
+	// Zero value is treated as PromiseVersionV1.
+	Version   uint8
 	ChannelID []byte
 	ChainID   int64
 	Amount    *big.Int
@@ -39,6 +43,15 @@ type Promise struct {
 	Hashlock  []byte
 	R         []byte
 	Signature []byte
+	// ExpiresAt is a unix timestamp after which the promise should no longer be accepted.
+	// It is only part of the signed message starting with PromiseVersionV2.
+	ExpiresAt int64
+	// ServiceType is the type of service the promise is paying for.
+	// It is only part of the signed message starting with PromiseVersionV2.
+	ServiceType string
+	// Commitment is an optional commitment to the promise amount created by CommitmentScheme.
+	// It is not part of the signed message.
+	Commitment [32]byte
 }
 
 // CreatePromise creates and signs new payment promise
@@ -182,14 +195,42 @@ func (p *Promise) Sign(ks *keystore.KeyStore, signer common.Address) error {
 }
 
 // GetMessage forms the message of payment promise
+// using the message format of the promise version.
+//
+// The channel ID is not validated, use GetMessageErr for promises received from peers.
 func (p Promise) GetMessage() []byte {
+	switch p.Version {
+	case PromiseVersionV2:
+		return p.GetMessageV2()
+	default:
+		return p.GetMessageV1()
+	}
+}
+
+// GetMessageErr forms the message of payment promise
+// using the message format of the promise version.
+//
+// An error is returned if the channel ID is invalid.
+func (p Promise) GetMessageErr() ([]byte, error) {
+	if err := validatePromiseChannelID(p.ChannelID); err != nil {
+		return nil, err
+	}
+
+	return p.GetMessage(), nil
+}
+
+// GetMessageV1 forms the message of payment promise using the original format
+// which is understood by the smart contracts.
+//
+// V1 messages carry no version prefix to stay compatible with already issued promises.
+func (p Promise) GetMessageV1() []byte {
 	message := []byte{}
 	b := make([]byte, 8)
 	binary.BigEndian.PutUint64(b, uint64(p.ChainID))
 	message = append(message, Pad(b, 32)...)
 	message = append(message, Pad(p.ChannelID, 32)...)
-	message = append(message, Pad(math.U256(p.Amount).Bytes(), 32)...)
-	message = append(message, Pad(math.U256(p.Fee).Bytes(), 32)...)
+	message = append(message, Pad(math.U256(canonicalAmount(p.Amount)).Bytes(), 32)...)
+	message = append(message, Pad(math.U256(canonicalAmount(p.Fee)).Bytes(), 32)...)
 	message = append(message, Pad(p.Hashlock, 32)...)
 	return message
 }
@@ -199,9 +240,49 @@ func (p Promise) GetHash() []byte {
 	return crypto.Keccak256(p.GetMessage())
 }
 
+// GetHashErr returns a keccak of payment promise message.
+//
+// An error is returned if the channel ID is invalid.
+func (p Promise) GetHashErr() ([]byte, error) {
+	message, err := p.GetMessageErr()
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.Keccak256(message), nil
+}
+
+// Hash returns hex encoded promise hash which can be used as a map key.
+func (p Promise) Hash() string {
+	return hex.EncodeToString(p.GetHash())
+}
+
+// SemanticEquals returns true if both promises hold the same values ignoring their signatures.
+func (p Promise) SemanticEquals(other Promise) bool {
+	return p.Version == other.Version &&
+		p.ChainID == other.ChainID &&
+		bytes.Equal(p.ChannelID, other.ChannelID) &&
+		bigIntEqual(p.Amount, other.Amount) &&
+		bigIntEqual(p.Fee, other.Fee) &&
+		bytes.Equal(p.Hashlock, other.Hashlock) &&
+		bytes.Equal(p.R, other.R) &&
+		p.ExpiresAt == other.ExpiresAt &&
+		p.ServiceType == other.ServiceType &&
+		p.Commitment == other.Commitment
+}
+
+// bigIntEqual compares two numbers treating nil as zero.
+func bigIntEqual(a, b *big.Int) bool {
+	return canonicalAmount(a).Cmp(canonicalAmount(b)) == 0
+}
+
 // CreateSignature signs promise using keystore
 func (p Promise) CreateSignature(ks hashSigner, signer common.Address) ([]byte, error) {
-	message := p.GetMessage()
+	message, err := p.GetMessageErr()
+	if err != nil {
+		return nil, err
+	}
+
 	hash := crypto.Keccak256(message)
 	return ks.SignHash(
 		accounts.Account{Address: signer},
@@ -224,7 +305,12 @@ func (p Promise) IsPromiseValid(expectedSigner common.Address) bool {
 		return false
 	}
 
-	recoveredSigner, err := RecoverAddress(p.GetMessage(), sig)
+	message, err := p.GetMessageErr()
+	if err != nil {
+		return false
+	}
+
+	recoveredSigner, err := RecoverAddress(message, sig)
 	if err != nil {
 		return false
 	}
@@ -232,8 +318,48 @@ func (p Promise) IsPromiseValid(expectedSigner common.Address) bool {
 	return recoveredSigner == expectedSigner
 }
 
+// ErrPromiseSignerMismatch is returned if a promise is not signed by the expected signer.
+var ErrPromiseSignerMismatch = errors.New("promise is not signed by the expected signer")
+
+// ValidatePromise validates if the promise is signed by the expected signer
+// returning the reason if it's not.
+func (p Promise) ValidatePromise(expectedSigner common.Address) error {
+	if err := p.ValidatePromiseSize(); err != nil {
+		return err
+	}
+	if err := NormalizePromiseSignature(&p); err != nil {
+		return err
+	}
+
+	recoveredSigner, err := p.RecoverSigner()
+	if err != nil {
+		return fmt.Errorf("failed to recover promise signer: %w", err)
+	}
+
+	if recoveredSigner != expectedSigner {
+		return fmt.Errorf("got %s, expected %s: %w", recoveredSigner.Hex(), expectedSigner.Hex(), ErrPromiseSignerMismatch)
+	}
+
+	return nil
+}
+
 // RecoverSigner recovers signer address out of promise signature
+//
+// Signatures with V in both recovery (0/1) and Ethereum (27/28) formats are supported.
 func (p Promise) RecoverSigner() (common.Address, error) {
+	if err := NormalizePromiseSignature(&p); err != nil {
+		return common.Address{}, err
+	}
+
+	message, err := p.GetMessageErr()
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	return p.recoverSigner(message)
+}
+
+func (p Promise) recoverSigner(message []byte) (common.Address, error) {
 	sig := make([]byte, 65)
 	copy(sig, p.Signature)
 
@@ -241,5 +367,5 @@ func (p Promise) RecoverSigner() (common.Address, error) {
 	if err != nil {
 		return common.Address{}, err
 	}
-	return RecoverAddress(p.GetMessage(), sig)
+	return RecoverAddress(message, sig)
 }
@@ -0,0 +1,93 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// String returns a single line summary of the promise for debugging.
+func (p Promise) String() string {
+	return fmt.Sprintf("Promise{channel=%s, amount=%s, fee=%s, hashlock=%s, exp=%s}",
+		hexutil.Encode(p.ChannelID),
+		p.Amount,
+		p.Fee,
+		hexutil.Encode(p.Hashlock),
+		formatPromiseExpiry(p.ExpiresAt),
+	)
+}
+
+// PromiseTable formats the given promises as a text table for debugging.
+//
+// A promise is reported as valid if it is within size limits, not expired
+// and its signature can be recovered. The signer itself is not checked.
+func PromiseTable(promises []Promise) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "Index\tChannelID\tAmount\tFee\tHashlock\tExpiresAt\tValid")
+	for i, p := range promises {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%t\n",
+			i,
+			hexutil.Encode(p.ChannelID),
+			p.Amount,
+			p.Fee,
+			shortHex(p.Hashlock, 8),
+			formatPromiseExpiry(p.ExpiresAt),
+			isPromiseWellFormed(p),
+		)
+	}
+
+	w.Flush()
+	return buf.String()
+}
+
+func formatPromiseExpiry(expiresAt int64) string {
+	if expiresAt == 0 {
+		return "none"
+	}
+
+	return time.Unix(expiresAt, 0).UTC().Format(time.RFC3339)
+}
+
+// shortHex returns the first n hex characters of the given bytes.
+func shortHex(b []byte, n int) string {
+	h := strings.TrimPrefix(hexutil.Encode(b), "0x")
+	if len(h) > n {
+		h = h[:n]
+	}
+
+	return "0x" + h
+}
+
+func isPromiseWellFormed(p Promise) bool {
+	if p.ValidatePromiseSize() != nil {
+		return false
+	}
+	if p.ExpiresAt != 0 && time.Now().Unix() > p.ExpiresAt {
+		return false
+	}
+
+	_, err := p.RecoverSigner()
+	return err == nil
+}
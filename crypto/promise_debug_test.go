@@ -0,0 +1,68 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromise_String(t *testing.T) {
+	p := getPromise("consumer")
+	p.ExpiresAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+	s := p.String()
+	assert.True(t, strings.HasPrefix(s, "Promise{"), s)
+	assert.Contains(t, s, "channel=0x000000000000000000000000d2c94475763fa7e81076ab0bde4dc4b902191498")
+	assert.Contains(t, s, "amount=1401")
+	assert.Contains(t, s, "fee=0")
+	assert.Contains(t, s, "hashlock=0x")
+	assert.Contains(t, s, "exp=2024-01-01T00:00:00Z")
+	assert.NotContains(t, s, "\n")
+
+	p.ExpiresAt = 0
+	assert.Contains(t, p.String(), "exp=none")
+}
+
+func TestPromiseTable(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		table := PromiseTable(nil)
+		lines := strings.Split(strings.TrimSpace(table), "\n")
+		assert.Len(t, lines, 1)
+		assert.Equal(t, []string{"Index", "ChannelID", "Amount", "Fee", "Hashlock", "ExpiresAt", "Valid"}, strings.Fields(lines[0]))
+	})
+	t.Run("rows", func(t *testing.T) {
+		valid := getPromise("consumer")
+		invalid := getPromise("consumer")
+		invalid.Signature = nil
+
+		lines := strings.Split(strings.TrimSpace(PromiseTable([]Promise{valid, invalid})), "\n")
+		assert.Len(t, lines, 3)
+
+		first := strings.Fields(lines[1])
+		assert.Equal(t, "0", first[0])
+		assert.Equal(t, "1401", first[2])
+		assert.Len(t, first[4], len("0x")+8)
+		assert.Equal(t, "none", first[5])
+		assert.Equal(t, "true", first[6])
+
+		assert.Equal(t, "false", strings.Fields(lines[2])[6])
+	})
+}
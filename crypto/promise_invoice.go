@@ -0,0 +1,124 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrPromiseInvoiceSignerMismatch is returned if a promise invoice is not signed by the expected provider.
+var ErrPromiseInvoiceSignerMismatch = errors.New("invoice is not signed by the expected provider")
+
+// PromiseInvoice is a payment request of a service provider for the given promise.
+type PromiseInvoice struct {
+	Promise            *Promise       `json:"promise"`
+	InvoiceID          string         `json:"invoiceId"`
+	ServiceDescription string         `json:"serviceDescription"`
+	ReceiverAddress    common.Address `json:"receiverAddress"`
+	CreatedAt          int64          `json:"createdAt"`
+	ProviderSignature  []byte         `json:"providerSignature"`
+}
+
+// CreatePromiseInvoice creates a new invoice with a random ID signed by the provider.
+func CreatePromiseInvoice(p *Promise, desc string, receiver common.Address, signer *ecdsa.PrivateKey) (*PromiseInvoice, error) {
+	if p == nil {
+		return nil, errors.New("promise must be provided")
+	}
+	if err := validatePromiseChannelID(p.ChannelID); err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate invoice ID: %w", err)
+	}
+
+	inv := &PromiseInvoice{
+		Promise:            p,
+		InvoiceID:          hex.EncodeToString(id),
+		ServiceDescription: desc,
+		ReceiverAddress:    receiver,
+		CreatedAt:          time.Now().Unix(),
+	}
+
+	signature, err := signForBC(inv.hash(), signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign invoice: %w", err)
+	}
+
+	inv.ProviderSignature = signature
+	return inv, nil
+}
+
+// ParsePromiseInvoice decodes a JSON encoded invoice.
+//
+// The invoice signature is not verified, use VerifyPromiseInvoice for that.
+func ParsePromiseInvoice(data []byte) (*PromiseInvoice, error) {
+	var inv PromiseInvoice
+	if err := json.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("failed to parse invoice: %w", err)
+	}
+	if inv.Promise == nil {
+		return nil, errors.New("invoice is missing the promise")
+	}
+
+	return &inv, nil
+}
+
+// VerifyPromiseInvoice verifies that the invoice is signed by the expected provider.
+func VerifyPromiseInvoice(inv *PromiseInvoice, expectedProvider common.Address) error {
+	if inv.Promise == nil {
+		return errors.New("invoice is missing the promise")
+	}
+	if err := validatePromiseChannelID(inv.Promise.ChannelID); err != nil {
+		return err
+	}
+
+	provider, err := recoverFromBC(inv.hash(), inv.ProviderSignature)
+	if err != nil {
+		return fmt.Errorf("invalid invoice signature: %w", err)
+	}
+	if provider != expectedProvider {
+		return fmt.Errorf("got %s, expected %s: %w", provider.Hex(), expectedProvider.Hex(), ErrPromiseInvoiceSignerMismatch)
+	}
+
+	return nil
+}
+
+// hash returns the keccak hash of the promise hash and invoice details.
+func (inv *PromiseInvoice) hash() []byte {
+	createdAt := make([]byte, 8)
+	binary.BigEndian.PutUint64(createdAt, uint64(inv.CreatedAt))
+
+	return crypto.Keccak256(
+		inv.Promise.GetHash(),
+		crypto.Keccak256([]byte(inv.InvoiceID)),
+		crypto.Keccak256([]byte(inv.ServiceDescription)),
+		inv.ReceiverAddress.Bytes(),
+		createdAt,
+	)
+}
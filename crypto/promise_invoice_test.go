@@ -0,0 +1,60 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromiseInvoice(t *testing.T) {
+	provider, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	providerAddress := crypto.PubkeyToAddress(provider.PublicKey)
+	receiver := common.HexToAddress("0xf53acdd584ccb85ee4ec1590007ad3c16fdff057")
+
+	p := getPromise("consumer")
+	inv, err := CreatePromiseInvoice(&p, "wireguard session", receiver, provider)
+	assert.NoError(t, err)
+	assert.Len(t, inv.InvoiceID, 32)
+	assert.NoError(t, VerifyPromiseInvoice(inv, providerAddress))
+
+	data, err := json.Marshal(inv)
+	assert.NoError(t, err)
+	parsed, err := ParsePromiseInvoice(data)
+	assert.NoError(t, err)
+	assert.NoError(t, VerifyPromiseInvoice(parsed, providerAddress))
+
+	t.Run("rejects tampered description", func(t *testing.T) {
+		tampered, err := ParsePromiseInvoice(data)
+		assert.NoError(t, err)
+		tampered.ServiceDescription = "openvpn session"
+		assert.True(t, errors.Is(VerifyPromiseInvoice(tampered, providerAddress), ErrPromiseInvoiceSignerMismatch))
+	})
+	t.Run("rejects other provider", func(t *testing.T) {
+		assert.True(t, errors.Is(VerifyPromiseInvoice(parsed, receiver), ErrPromiseInvoiceSignerMismatch))
+	})
+	t.Run("rejects invoice without promise", func(t *testing.T) {
+		_, err := ParsePromiseInvoice([]byte(`{"invoiceId":"1"}`))
+		assert.Error(t, err)
+	})
+}
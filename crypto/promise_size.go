@@ -0,0 +1,63 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import "fmt"
+
+// Maximal lengths of variable length promise fields.
+const (
+	MaxPromiseChannelIDLength   = 32
+	MaxPromiseHashlockLength    = 32
+	MaxPromiseRLength           = 32
+	MaxPromiseSignatureLength   = 65
+	MaxPromiseServiceTypeLength = 64
+)
+
+// ErrFieldTooLarge is returned if a promise field exceeds its maximal length.
+type ErrFieldTooLarge struct {
+	Field    string
+	Got, Max int
+}
+
+func (e ErrFieldTooLarge) Error() string {
+	return fmt.Sprintf("promise field %s is too large: got %d bytes, max %d", e.Field, e.Got, e.Max)
+}
+
+// ValidatePromiseSize returns an error if any of the promise fields is larger than allowed.
+//
+// It should be called before processing promises received from untrusted peers.
+func (p Promise) ValidatePromiseSize() error {
+	fields := []struct {
+		name string
+		got  int
+		max  int
+	}{
+		{"ChannelID", len(p.ChannelID), MaxPromiseChannelIDLength},
+		{"Hashlock", len(p.Hashlock), MaxPromiseHashlockLength},
+		{"R", len(p.R), MaxPromiseRLength},
+		{"Signature", len(p.Signature), MaxPromiseSignatureLength},
+		{"ServiceType", len(p.ServiceType), MaxPromiseServiceTypeLength},
+	}
+
+	for _, f := range fields {
+		if f.got > f.max {
+			return ErrFieldTooLarge{Field: f.name, Got: f.got, Max: f.max}
+		}
+	}
+
+	return nil
+}
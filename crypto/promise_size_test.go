@@ -0,0 +1,62 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromise_ValidatePromiseSize(t *testing.T) {
+	fields := []struct {
+		name string
+		max  int
+		set  func(p *Promise, n int)
+	}{
+		{"ChannelID", MaxPromiseChannelIDLength, func(p *Promise, n int) { p.ChannelID = bytes.Repeat([]byte{1}, n) }},
+		{"Hashlock", MaxPromiseHashlockLength, func(p *Promise, n int) { p.Hashlock = bytes.Repeat([]byte{1}, n) }},
+		{"R", MaxPromiseRLength, func(p *Promise, n int) { p.R = bytes.Repeat([]byte{1}, n) }},
+		{"Signature", MaxPromiseSignatureLength, func(p *Promise, n int) { p.Signature = bytes.Repeat([]byte{1}, n) }},
+		{"ServiceType", MaxPromiseServiceTypeLength, func(p *Promise, n int) { p.ServiceType = strings.Repeat("a", n) }},
+	}
+
+	for _, f := range fields {
+		t.Run(f.name, func(t *testing.T) {
+			p := getPromise("consumer")
+			f.set(&p, f.max)
+			assert.NoError(t, p.ValidatePromiseSize())
+
+			f.set(&p, f.max+1)
+			assert.Equal(t, ErrFieldTooLarge{Field: f.name, Got: f.max + 1, Max: f.max}, p.ValidatePromiseSize())
+		})
+	}
+
+	t.Run("ValidatePromise checks size first", func(t *testing.T) {
+		p := getPromise("consumer")
+		p.Hashlock = make([]byte, 1<<20)
+
+		var tooLarge ErrFieldTooLarge
+		assert.True(t, errors.As(p.ValidatePromise(common.Address{}), &tooLarge))
+		assert.Equal(t, "Hashlock", tooLarge.Field)
+		assert.True(t, errors.As(p.ValidatePromiseAnyVersion(common.Address{}), &tooLarge))
+	})
+}
@@ -235,6 +235,24 @@ func getPrivKey(userType string) *ecdsa.PrivateKey {
 	return pk
 }
 
+func TestPromiseSemanticEquals(t *testing.T) {
+	a := getPromise("consumer")
+	b := getPromise("consumer")
+	b.Signature = []byte{1, 2, 3}
+	assert.True(t, a.SemanticEquals(b))
+	assert.Equal(t, a.Hash(), b.Hash())
+	assert.Equal(t, hex.EncodeToString(a.GetHash()), a.Hash())
+
+	b.Amount = new(big.Int).Add(a.Amount, big.NewInt(1))
+	assert.False(t, a.SemanticEquals(b))
+	assert.NotEqual(t, a.Hash(), b.Hash())
+
+	b = getPromise("consumer")
+	b.Fee = nil
+	assert.Equal(t, int64(0), a.Fee.Int64())
+	assert.True(t, a.SemanticEquals(b), "nil fee should equal zero fee")
+}
+
 func getPromise(userType string) Promise {
 	p := getParams(userType)
 	amount := big.NewInt(0).SetUint64(p.Amount)
@@ -0,0 +1,106 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// PromiseVersionV1 is the original promise message format.
+	PromiseVersionV1 uint8 = 1
+	// PromiseVersionV2 extends the V1 message with the promise expiration time and service type.
+	PromiseVersionV2 uint8 = 2
+	// PromiseVersionLatest is the newest known promise version.
+	PromiseVersionLatest = PromiseVersionV2
+)
+
+// GetMessageV2 forms the message of payment promise prefixed with the
+// version byte and extended with the expiration time and service type.
+func (p Promise) GetMessageV2() []byte {
+	message := []byte{PromiseVersionV2}
+	message = append(message, p.GetMessageV1()...)
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(p.ExpiresAt))
+	message = append(message, Pad(b, 32)...)
+
+	// Service type is only included when set to stay compatible with already issued promises.
+	if p.ServiceType != "" {
+		message = append(message, []byte(p.ServiceType)...)
+	}
+	return message
+}
+
+// ValidatePromiseAnyVersion validates if any of the known promise message formats
+// is signed by the expected signer. Error is returned only if all versions fail.
+func (p Promise) ValidatePromiseAnyVersion(expectedSigner common.Address) error {
+	if err := p.ValidatePromiseSize(); err != nil {
+		return err
+	}
+
+	messages := []struct {
+		version uint8
+		message []byte
+	}{
+		{PromiseVersionV1, p.GetMessageV1()},
+		{PromiseVersionV2, p.GetMessageV2()},
+	}
+
+	failures := make([]string, 0, len(messages))
+	for _, m := range messages {
+		signer, err := p.recoverSigner(m.message)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("v%d: %v", m.version, err))
+			continue
+		}
+		if signer == expectedSigner {
+			return nil
+		}
+		failures = append(failures, fmt.Sprintf("v%d: signed by %s", m.version, signer.Hex()))
+	}
+
+	return fmt.Errorf("promise is not signed by %s in any known version: %s", expectedSigner.Hex(), strings.Join(failures, "; "))
+}
+
+// MigrateToLatest upgrades the given promise to the latest version
+// re-signing it using the given keystore and signer.
+//
+// Promises which are already on the latest version are left untouched.
+func MigrateToLatest(p *Promise, ks hashSigner, signer common.Address) error {
+	if p.Version >= PromiseVersionLatest {
+		return nil
+	}
+
+	migrated := *p
+	migrated.Version = PromiseVersionLatest
+	signature, err := migrated.CreateSignature(ks, signer)
+	if err != nil {
+		return fmt.Errorf("failed to sign migrated promise: %w", err)
+	}
+
+	if err := ReformatSignatureVForBC(signature); err != nil {
+		return fmt.Errorf("failed to reformat signature: %w", err)
+	}
+
+	migrated.Signature = signature
+	*p = migrated
+	return nil
+}
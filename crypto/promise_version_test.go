@@ -0,0 +1,76 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromiseVersions(t *testing.T) {
+	expectedSigner := common.HexToAddress("0xf53acdd584ccb85ee4ec1590007ad3c16fdff057")
+
+	t.Run("v1 promise validates with unset and explicit version", func(t *testing.T) {
+		promise := getPromise("consumer")
+		assert.Equal(t, uint8(0), promise.Version)
+		assert.True(t, promise.IsPromiseValid(expectedSigner))
+		assert.NoError(t, promise.ValidatePromiseAnyVersion(expectedSigner))
+
+		promise.Version = PromiseVersionV1
+		assert.True(t, promise.IsPromiseValid(expectedSigner))
+		assert.Equal(t, promise.GetMessageV1(), promise.GetMessage())
+	})
+	t.Run("v2 message is prefixed with version", func(t *testing.T) {
+		promise := getPromise("consumer")
+		promise.Version = PromiseVersionV2
+		promise.ExpiresAt = 1700000000
+
+		message := promise.GetMessage()
+		assert.Equal(t, PromiseVersionV2, message[0])
+		assert.Len(t, message, len(promise.GetMessageV1())+33)
+		assert.False(t, promise.IsPromiseValid(expectedSigner), "v1 signature should not be valid for v2 message")
+	})
+	t.Run("migrates promise to latest version", func(t *testing.T) {
+		dir, ks := tmpKeyStore(t, false)
+		defer os.RemoveAll(dir)
+
+		account, err := ks.ImportECDSA(getPrivKey("consumer"), "")
+		assert.NoError(t, err)
+		assert.NoError(t, ks.Unlock(account, ""))
+
+		promise := getPromise("consumer")
+		promise.ExpiresAt = 1700000000
+		assert.NoError(t, MigrateToLatest(&promise, ks, account.Address))
+		assert.Equal(t, PromiseVersionLatest, promise.Version)
+		assert.True(t, promise.IsPromiseValid(expectedSigner))
+
+		// Version is detected even if the promise version is lost.
+		promise.Version = 0
+		assert.False(t, promise.IsPromiseValid(expectedSigner))
+		assert.NoError(t, promise.ValidatePromiseAnyVersion(expectedSigner))
+	})
+	t.Run("fails if no version matches", func(t *testing.T) {
+		promise := getPromise("consumer")
+		err := promise.ValidatePromiseAnyVersion(common.HexToAddress("0x1"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "v1")
+		assert.Contains(t, err.Error(), "v2")
+	})
+}
@@ -0,0 +1,105 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// RecoveryMode defines how a message is hashed before recovering its signer.
+type RecoveryMode uint8
+
+const (
+	// RecoveryModeRaw hashes the message as is.
+	RecoveryModeRaw RecoveryMode = iota
+	// RecoveryModePrefix hashes the message with the `\x19Ethereum Signed Message:\n` prefix.
+	RecoveryModePrefix
+	// RecoveryModeEIP712 hashes the message with the `\x19\x01` prefix.
+	// The message must be the domain separator followed by the struct hash.
+	RecoveryModeEIP712
+)
+
+// recoveryModes lists modes in the order they're tried by DetectSignatureMode.
+var recoveryModes = []RecoveryMode{RecoveryModeRaw, RecoveryModePrefix, RecoveryModeEIP712}
+
+// eip712Prefix is prepended to EIP-712 messages before hashing.
+var eip712Prefix = []byte{0x19, 0x01}
+
+// ErrUnknownRecoveryMode is returned for recovery modes which are not defined.
+var ErrUnknownRecoveryMode = errors.New("unknown recovery mode")
+
+// ErrSignatureModeNotDetected is returned if no recovery mode recovers one of the candidates.
+var ErrSignatureModeNotDetected = errors.New("signature does not recover any candidate address")
+
+func (m RecoveryMode) String() string {
+	switch m {
+	case RecoveryModeRaw:
+		return "raw"
+	case RecoveryModePrefix:
+		return "prefix"
+	case RecoveryModeEIP712:
+		return "eip712"
+	default:
+		return fmt.Sprintf("RecoveryMode(%d)", uint8(m))
+	}
+}
+
+func (m RecoveryMode) hash(message []byte) ([]byte, error) {
+	switch m {
+	case RecoveryModeRaw:
+		return crypto.Keccak256(message), nil
+	case RecoveryModePrefix:
+		return accounts.TextHash(message), nil
+	case RecoveryModeEIP712:
+		if bytes.HasPrefix(message, eip712Prefix) {
+			return crypto.Keccak256(message), nil
+		}
+		return crypto.Keccak256(eip712Prefix, message), nil
+	default:
+		return nil, fmt.Errorf("%s: %w", m, ErrUnknownRecoveryMode)
+	}
+}
+
+// DetectSignatureMode tries all recovery modes returning the first
+// one which recovers one of the candidate addresses.
+//
+// Signature V is accepted in both recovery and Ethereum formats.
+func DetectSignatureMode(sig []byte, message []byte, candidates []common.Address) (RecoveryMode, common.Address, error) {
+	normalized := make([]byte, len(sig))
+	copy(normalized, sig)
+	if err := ReformatSignatureVForRecovery(normalized); err != nil {
+		return 0, common.Address{}, err
+	}
+
+	for _, mode := range recoveryModes {
+		recovered, err := RecoverAddress(message, normalized, mode)
+		if err != nil {
+			continue
+		}
+		if containsAddress(candidates, recovered) {
+			return mode, recovered, nil
+		}
+	}
+
+	return 0, common.Address{}, ErrSignatureModeNotDetected
+}
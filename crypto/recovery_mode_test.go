@@ -0,0 +1,85 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryModes(t *testing.T) {
+	key, err := crypto.HexToECDSA("6f88a6dd6c5d4d8da2f99d0c3dc38e0b6e4fcbd163b7c6b2b533929dff1b55e1")
+	assert.NoError(t, err)
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	message := append(crypto.Keccak256([]byte("domain")), crypto.Keccak256([]byte("struct"))...)
+	hashes := map[RecoveryMode][]byte{
+		RecoveryModeRaw:    crypto.Keccak256(message),
+		RecoveryModePrefix: accounts.TextHash(message),
+		RecoveryModeEIP712: crypto.Keccak256([]byte{0x19, 0x01}, message),
+	}
+
+	for mode, hash := range hashes {
+		t.Run(mode.String(), func(t *testing.T) {
+			sig, err := crypto.Sign(hash, key)
+			assert.NoError(t, err)
+
+			recovered, err := RecoverAddress(message, sig, mode)
+			assert.NoError(t, err)
+			assert.Equal(t, signer, recovered)
+
+			bcSig := append([]byte{}, sig...)
+			assert.NoError(t, ReformatSignatureVForBC(bcSig))
+			detected, addr, err := DetectSignatureMode(bcSig, message, []common.Address{common.HexToAddress("0x1"), signer})
+			assert.NoError(t, err)
+			assert.Equal(t, mode, detected)
+			assert.Equal(t, signer, addr)
+		})
+	}
+
+	t.Run("default mode is raw", func(t *testing.T) {
+		sig, err := crypto.Sign(hashes[RecoveryModeRaw], key)
+		assert.NoError(t, err)
+
+		recovered, err := RecoverAddress(message, sig)
+		assert.NoError(t, err)
+		assert.Equal(t, signer, recovered)
+	})
+	t.Run("prefixed EIP-712 message is not prefixed twice", func(t *testing.T) {
+		sig, err := crypto.Sign(hashes[RecoveryModeEIP712], key)
+		assert.NoError(t, err)
+
+		recovered, err := RecoverAddress(append([]byte{0x19, 0x01}, message...), sig, RecoveryModeEIP712)
+		assert.NoError(t, err)
+		assert.Equal(t, signer, recovered)
+	})
+	t.Run("unknown candidates", func(t *testing.T) {
+		sig, err := crypto.Sign(hashes[RecoveryModePrefix], key)
+		assert.NoError(t, err)
+
+		_, _, err = DetectSignatureMode(sig, message, []common.Address{common.HexToAddress("0x1")})
+		assert.ErrorIs(t, err, ErrSignatureModeNotDetected)
+	})
+	t.Run("unknown mode", func(t *testing.T) {
+		_, err := RecoverAddress(message, make([]byte, 65), RecoveryMode(42))
+		assert.ErrorIs(t, err, ErrUnknownRecoveryMode)
+	})
+}
@@ -0,0 +1,101 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// ErrReplayDetected is returned if a promise was already seen within the replay window.
+type ErrReplayDetected struct {
+	Hash string
+}
+
+func (e ErrReplayDetected) Error() string {
+	return fmt.Sprintf("promise replay detected, hash: %s", e.Hash)
+}
+
+// ReplayWindow keeps track of recently seen promises to detect replays.
+//
+// Only the most recent Capacity promises are remembered.
+type ReplayWindow struct {
+	Window   time.Duration
+	Capacity int
+
+	seen *lru.Cache
+	now  func() time.Time
+	m    sync.Mutex
+}
+
+// NewReplayWindow returns a new replay window remembering promises for the given duration.
+func NewReplayWindow(window time.Duration, capacity int) (*ReplayWindow, error) {
+	if window <= 0 {
+		return nil, errors.New("replay window must be greater than 0")
+	}
+
+	seen, err := lru.New(capacity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create replay cache: %w", err)
+	}
+
+	return &ReplayWindow{
+		Window:   window,
+		Capacity: capacity,
+		seen:     seen,
+		now:      time.Now,
+	}, nil
+}
+
+// CheckAndRecord returns ErrReplayDetected if the promise was seen within the window,
+// otherwise the promise is recorded.
+//
+// Promise entries expire at the promise ExpiresAt time if it is set
+// or after the configured window otherwise.
+func (rw *ReplayWindow) CheckAndRecord(p Promise) error {
+	hashBytes, err := p.GetHashErr()
+	if err != nil {
+		return err
+	}
+	hash := hex.EncodeToString(hashBytes)
+
+	rw.m.Lock()
+	defer rw.m.Unlock()
+
+	now := rw.now()
+	if expiry, ok := rw.seen.Get(hash); ok && now.Before(expiry.(time.Time)) {
+		return ErrReplayDetected{Hash: hash}
+	}
+
+	expiry := now.Add(rw.Window)
+	if p.ExpiresAt > 0 {
+		expiry = time.Unix(p.ExpiresAt, 0)
+	}
+
+	rw.seen.Add(hash, expiry)
+	return nil
+}
+
+// Size returns the amount of promises currently held in the window.
+func (rw *ReplayWindow) Size() int {
+	return rw.seen.Len()
+}
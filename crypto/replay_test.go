@@ -0,0 +1,71 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayWindow(t *testing.T) {
+	now := time.Now()
+	newWindow := func(capacity int) *ReplayWindow {
+		rw, err := NewReplayWindow(time.Minute, capacity)
+		assert.NoError(t, err)
+		rw.now = func() time.Time { return now }
+		return rw
+	}
+
+	t.Run("rejects a promise replayed within the window", func(t *testing.T) {
+		rw := newWindow(10)
+		p := getPromise("consumer")
+
+		assert.NoError(t, rw.CheckAndRecord(p))
+		err := rw.CheckAndRecord(p)
+		var replay ErrReplayDetected
+		assert.True(t, errors.As(err, &replay))
+		assert.NotEmpty(t, replay.Hash)
+		assert.Equal(t, 1, rw.Size())
+
+		now = now.Add(time.Minute)
+		assert.NoError(t, rw.CheckAndRecord(p), "promise should be accepted after the window expires")
+	})
+	t.Run("uses promise expiration when set", func(t *testing.T) {
+		rw := newWindow(10)
+		p := getPromise("consumer")
+		p.ExpiresAt = now.Add(time.Hour).Unix()
+
+		assert.NoError(t, rw.CheckAndRecord(p))
+		now = now.Add(time.Minute * 30)
+		assert.Error(t, rw.CheckAndRecord(p))
+		now = now.Add(time.Minute * 31)
+		assert.NoError(t, rw.CheckAndRecord(p))
+	})
+	t.Run("forgets oldest promises above capacity", func(t *testing.T) {
+		rw := newWindow(2)
+		for n := int64(1); n <= 3; n++ {
+			p := getPromise("consumer")
+			p.Amount = big.NewInt(n)
+			assert.NoError(t, rw.CheckAndRecord(p))
+		}
+		assert.Equal(t, 2, rw.Size())
+	})
+}
@@ -0,0 +1,120 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// PromiseSchema encodes and decodes promises of a single format version.
+//
+// Encoded promises must start with the schema version byte.
+type PromiseSchema interface {
+	Encode(p Promise) ([]byte, error)
+	Decode(data []byte) (*Promise, error)
+	Version() uint8
+}
+
+// ErrSchemaAlreadyRegistered is returned when registering a schema version twice.
+var ErrSchemaAlreadyRegistered = errors.New("promise schema version already registered")
+
+// ErrUnknownSchemaVersion is returned if there is no schema for the given version.
+var ErrUnknownSchemaVersion = errors.New("unknown promise schema version")
+
+// SchemaRegistry holds the promise schemas a node is able to speak.
+type SchemaRegistry struct {
+	schemas map[uint8]PromiseSchema
+	latest  uint8
+	m       sync.RWMutex
+}
+
+// NewSchemaRegistry returns a new empty schema registry.
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{
+		schemas: make(map[uint8]PromiseSchema),
+	}
+}
+
+// Register adds a schema to the registry.
+func (r *SchemaRegistry) Register(s PromiseSchema) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	v := s.Version()
+	if _, ok := r.schemas[v]; ok {
+		return fmt.Errorf("version %d: %w", v, ErrSchemaAlreadyRegistered)
+	}
+
+	r.schemas[v] = s
+	if v > r.latest {
+		r.latest = v
+	}
+	return nil
+}
+
+// EncodeLatest encodes the promise using the schema with the highest version.
+func (r *SchemaRegistry) EncodeLatest(p Promise) ([]byte, error) {
+	r.m.RLock()
+	s, ok := r.schemas[r.latest]
+	r.m.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no schemas registered: %w", ErrUnknownSchemaVersion)
+	}
+
+	return s.Encode(p)
+}
+
+// Decode decodes the promise using the schema matching the version in the first byte.
+func (r *SchemaRegistry) Decode(data []byte) (*Promise, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty promise data: %w", ErrUnknownSchemaVersion)
+	}
+
+	r.m.RLock()
+	s, ok := r.schemas[data[0]]
+	r.m.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("version %d: %w", data[0], ErrUnknownSchemaVersion)
+	}
+
+	return s.Decode(data)
+}
+
+// CompactPromiseSchema is the promise schema of the compact encoding.
+type CompactPromiseSchema struct{}
+
+// Encode encodes the promise using SignedPromise.MarshalCompact.
+func (CompactPromiseSchema) Encode(p Promise) ([]byte, error) {
+	return SignedPromise{Promise: p}.MarshalCompact()
+}
+
+// Decode decodes the promise using SignedPromise.UnmarshalCompact.
+func (CompactPromiseSchema) Decode(data []byte) (*Promise, error) {
+	var sp SignedPromise
+	if err := sp.UnmarshalCompact(data); err != nil {
+		return nil, err
+	}
+
+	return &sp.Promise, nil
+}
+
+// Version returns CompactPromiseVersion.
+func (CompactPromiseSchema) Version() uint8 {
+	return CompactPromiseVersion
+}
@@ -0,0 +1,81 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// jsonPromiseSchema is a version 2 schema encoding promises as JSON.
+type jsonPromiseSchema struct{}
+
+func (jsonPromiseSchema) Encode(p Promise) ([]byte, error) {
+	data, err := json.Marshal(p)
+	return append([]byte{2}, data...), err
+}
+
+func (jsonPromiseSchema) Decode(data []byte) (*Promise, error) {
+	var p Promise
+	return &p, json.Unmarshal(data[1:], &p)
+}
+
+func (jsonPromiseSchema) Version() uint8 {
+	return 2
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	r := NewSchemaRegistry()
+	_, err := r.EncodeLatest(getPromise("consumer"))
+	assert.True(t, errors.Is(err, ErrUnknownSchemaVersion))
+
+	assert.NoError(t, r.Register(jsonPromiseSchema{}))
+	assert.NoError(t, r.Register(CompactPromiseSchema{}))
+	assert.True(t, errors.Is(r.Register(CompactPromiseSchema{}), ErrSchemaAlreadyRegistered))
+
+	t.Run("encodes with latest schema", func(t *testing.T) {
+		p := getPromise("consumer")
+		data, err := r.EncodeLatest(p)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(2), data[0])
+
+		decoded, err := r.Decode(data)
+		assert.NoError(t, err)
+		assert.True(t, p.SemanticEquals(*decoded))
+	})
+	t.Run("decodes older versions", func(t *testing.T) {
+		p := getPromise("consumer")
+		p.Hashlock = Pad(p.Hashlock, 32)
+		data, err := CompactPromiseSchema{}.Encode(p)
+		assert.NoError(t, err)
+
+		decoded, err := r.Decode(data)
+		assert.NoError(t, err)
+		assert.Equal(t, p.Amount, decoded.Amount)
+		assert.Equal(t, p.Signature, decoded.Signature)
+	})
+	t.Run("rejects unknown versions", func(t *testing.T) {
+		_, err := r.Decode([]byte{3, 1, 2})
+		assert.True(t, errors.Is(err, ErrUnknownSchemaVersion))
+
+		_, err = r.Decode(nil)
+		assert.True(t, errors.Is(err, ErrUnknownSchemaVersion))
+	})
+}
@@ -0,0 +1,73 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// hermesSettlePromiseABI holds the `settlePromise` method definition of the Hermes contract.
+const hermesSettlePromiseABI = `[{"inputs":[{"internalType":"address","name":"_identity","type":"address"},{"internalType":"uint256","name":"_amount","type":"uint256"},{"internalType":"uint256","name":"_transactorFee","type":"uint256"},{"internalType":"bytes32","name":"_preimage","type":"bytes32"},{"internalType":"bytes","name":"_signature","type":"bytes"}],"name":"settlePromise","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+
+// HermesSettlementABI is the Hermes contract ABI used to settle promises.
+var HermesSettlementABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(hermesSettlePromiseABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// ComputeSettlementCalldata packs the promise channel ID, amount, fee, hashlock and signature
+// as arguments of the given contract method.
+func (p Promise) ComputeSettlementCalldata(contractABI abi.ABI, methodName string) ([]byte, error) {
+	data, err := contractABI.Pack(methodName, padBytes32(p.ChannelID), p.Amount, p.Fee, padBytes32(p.Hashlock), p.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack %s calldata: %w", methodName, err)
+	}
+
+	return data, nil
+}
+
+// StandardHermesSettlementCalldata returns the calldata for settling
+// the promise of the given provider identity with Hermes.
+//
+// Hermes settles promises by identity and verifies the promise
+// hashlock using the preimage R, which must be set.
+func (p Promise) StandardHermesSettlementCalldata(identity common.Address) ([]byte, error) {
+	if len(p.R) == 0 {
+		return nil, errors.New("promise preimage is required for settlement")
+	}
+
+	data, err := HermesSettlementABI.Pack("settlePromise", identity, p.Amount, p.Fee, padBytes32(p.R), p.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack settlePromise calldata: %w", err)
+	}
+
+	return data, nil
+}
+
+// padBytes32 copies the given bytes into a fixed 32 byte array.
+func padBytes32(arr []byte) (res [32]byte) {
+	copy(res[:], arr)
+	return res
+}
@@ -0,0 +1,72 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromiseSettlementCalldata(t *testing.T) {
+	promise := getPromise("consumer")
+	promise.R = getParams("consumer").R
+
+	t.Run("packs promise fields for the given method", func(t *testing.T) {
+		contractABI, err := abi.JSON(strings.NewReader(`[{"inputs":[{"name":"_channelId","type":"bytes32"},{"name":"_amount","type":"uint256"},{"name":"_fee","type":"uint256"},{"name":"_hashlock","type":"bytes32"},{"name":"_signature","type":"bytes"}],"name":"settle","outputs":[],"type":"function"}]`))
+		assert.NoError(t, err)
+
+		data, err := promise.ComputeSettlementCalldata(contractABI, "settle")
+		assert.NoError(t, err)
+
+		method := contractABI.Methods["settle"]
+		assert.Equal(t, method.ID, data[:4])
+		args, err := method.Inputs.Unpack(data[4:])
+		assert.NoError(t, err)
+		assert.Equal(t, padBytes32(promise.ChannelID), args[0])
+		assert.Equal(t, promise.Amount.String(), args[1].(*big.Int).String())
+		assert.Equal(t, promise.Fee.String(), args[2].(*big.Int).String())
+		assert.Equal(t, padBytes32(promise.Hashlock), args[3])
+		assert.Equal(t, promise.Signature, args[4])
+
+		_, err = promise.ComputeSettlementCalldata(contractABI, "unknown")
+		assert.Error(t, err)
+	})
+	t.Run("packs hermes settlement", func(t *testing.T) {
+		identity := common.HexToAddress("0x1")
+		data, err := promise.StandardHermesSettlementCalldata(identity)
+		assert.NoError(t, err)
+
+		method := HermesSettlementABI.Methods["settlePromise"]
+		assert.Equal(t, method.ID, data[:4])
+		args, err := method.Inputs.Unpack(data[4:])
+		assert.NoError(t, err)
+		assert.Equal(t, identity, args[0])
+		assert.Equal(t, promise.Amount.String(), args[1].(*big.Int).String())
+		assert.Equal(t, promise.Fee.String(), args[2].(*big.Int).String())
+		assert.Equal(t, padBytes32(promise.R), args[3])
+		assert.Equal(t, promise.Signature, args[4])
+
+		promise.R = nil
+		_, err = promise.StandardHermesSettlementCalldata(identity)
+		assert.Error(t, err)
+	})
+}
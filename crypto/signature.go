@@ -0,0 +1,93 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSignatureLength is returned if a signature is not 65 bytes long.
+var ErrInvalidSignatureLength = errors.New("the signature must be 65 bytes long")
+
+// ErrInvalidSignatureV is returned if the V byte of a signature is not valid.
+var ErrInvalidSignatureV = errors.New("invalid signature V value")
+
+// DecomposeSignature splits a 65 byte signature into its V, R and S components.
+//
+// V is accepted in both recovery (0/1) and Ethereum (27/28) formats
+// and is always returned in the Ethereum format.
+func DecomposeSignature(sig []byte) (v uint8, r, s [32]byte, err error) {
+	if len(sig) != 65 {
+		return 0, r, s, fmt.Errorf("got %d bytes: %w", len(sig), ErrInvalidSignatureLength)
+	}
+
+	v = sig[64]
+	switch v {
+	case 0, 1:
+		v += 27
+	case 27, 28:
+	default:
+		return 0, r, s, fmt.Errorf("got %d: %w", v, ErrInvalidSignatureV)
+	}
+
+	copy(r[:], sig[:32])
+	copy(s[:], sig[32:64])
+	return v, r, s, nil
+}
+
+// NormalizePromiseSignature converts the promise signature V to the recovery format (0/1)
+// used internally. Signatures in both recovery and Ethereum (27/28) formats are accepted.
+func NormalizePromiseSignature(p *Promise) error {
+	v, _, _, err := DecomposeSignature(p.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid promise signature: %w", err)
+	}
+
+	// Signature is copied so that slices shared with other promises are not modified.
+	sig := append([]byte(nil), p.Signature...)
+	sig[64] = v - 27
+	p.Signature = sig
+	return nil
+}
+
+// GetSignatureBytesExternal returns a copy of the promise signature with V in
+// the Ethereum format (27/28) expected by the smart contracts.
+//
+// Malformed signatures are returned as is.
+func (p Promise) GetSignatureBytesExternal() []byte {
+	v, r, s, err := DecomposeSignature(p.Signature)
+	if err != nil {
+		return append([]byte(nil), p.Signature...)
+	}
+
+	sig, _ := RecomposeSignature(v, r, s)
+	return sig
+}
+
+// RecomposeSignature joins the V, R and S components into a 65 byte signature.
+// V must be in the Ethereum format (27/28).
+func RecomposeSignature(v uint8, r, s [32]byte) ([]byte, error) {
+	if v != 27 && v != 28 {
+		return nil, fmt.Errorf("got %d: %w", v, ErrInvalidSignatureV)
+	}
+
+	sig := make([]byte, 0, 65)
+	sig = append(sig, r[:]...)
+	sig = append(sig, s[:]...)
+	return append(sig, v), nil
+}
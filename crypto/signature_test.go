@@ -0,0 +1,117 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecomposeSignature(t *testing.T) {
+	r := bytes.Repeat([]byte{1}, 32)
+	s := bytes.Repeat([]byte{2}, 32)
+	sigWithV := func(v byte) []byte {
+		return append(append(append([]byte{}, r...), s...), v)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		inV   byte
+		wantV uint8
+	}{
+		{"recovery 0", 0, 27},
+		{"recovery 1", 1, 28},
+		{"ethereum 27", 27, 27},
+		{"ethereum 28", 28, 28},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			v, gotR, gotS, err := DecomposeSignature(sigWithV(tc.inV))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantV, v)
+			assert.Equal(t, r, gotR[:])
+			assert.Equal(t, s, gotS[:])
+
+			recomposed, err := RecomposeSignature(v, gotR, gotS)
+			assert.NoError(t, err)
+			assert.Equal(t, sigWithV(tc.wantV), recomposed)
+		})
+	}
+
+	t.Run("wrong length", func(t *testing.T) {
+		for _, sig := range [][]byte{nil, make([]byte, 64), make([]byte, 66)} {
+			_, _, _, err := DecomposeSignature(sig)
+			assert.ErrorIs(t, err, ErrInvalidSignatureLength)
+		}
+	})
+	t.Run("invalid V", func(t *testing.T) {
+		for _, v := range []byte{2, 26, 29, 255} {
+			_, _, _, err := DecomposeSignature(sigWithV(v))
+			assert.ErrorIs(t, err, ErrInvalidSignatureV, v)
+		}
+	})
+}
+
+func TestRecomposeSignature_InvalidV(t *testing.T) {
+	for _, v := range []uint8{0, 1, 26, 29} {
+		_, err := RecomposeSignature(v, [32]byte{}, [32]byte{})
+		assert.ErrorIs(t, err, ErrInvalidSignatureV, v)
+	}
+}
+
+func TestNormalizePromiseSignature(t *testing.T) {
+	dir, ks := tmpKeyStore(t, false)
+	defer os.RemoveAll(dir)
+	acc, err := ks.ImportECDSA(getPrivKey("consumer"), "")
+	assert.NoError(t, err)
+	assert.NoError(t, ks.Unlock(acc, ""))
+
+	p := getPromise("consumer")
+	assert.NoError(t, p.Sign(ks, acc.Address))
+	assert.Contains(t, []byte{27, 28}, p.Signature[64], "promises are signed in Ethereum format")
+
+	recovery := p
+	assert.NoError(t, NormalizePromiseSignature(&recovery))
+	assert.Equal(t, p.Signature[64]-27, recovery.Signature[64])
+	assert.Equal(t, p.Signature[:64], recovery.Signature[:64])
+	assert.Contains(t, []byte{27, 28}, p.Signature[64], "original signature should not be modified")
+
+	for name, promise := range map[string]Promise{"ethereum": p, "recovery": recovery} {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, promise.ValidatePromise(acc.Address))
+			signer, err := promise.RecoverSigner()
+			assert.NoError(t, err)
+			assert.Equal(t, acc.Address, signer)
+			assert.Equal(t, p.Signature, promise.GetSignatureBytesExternal())
+		})
+	}
+
+	t.Run("invalid signatures", func(t *testing.T) {
+		invalid := p
+		invalid.Signature = append(append([]byte(nil), p.Signature[:64]...), 29)
+		assert.ErrorIs(t, NormalizePromiseSignature(&invalid), ErrInvalidSignatureV)
+		assert.ErrorIs(t, invalid.ValidatePromise(acc.Address), ErrInvalidSignatureV)
+		assert.Equal(t, invalid.Signature, invalid.GetSignatureBytesExternal())
+
+		invalid.Signature = p.Signature[:64]
+		assert.ErrorIs(t, NormalizePromiseSignature(&invalid), ErrInvalidSignatureLength)
+		_, err := invalid.RecoverSigner()
+		assert.ErrorIs(t, err, ErrInvalidSignatureLength)
+	})
+}
@@ -0,0 +1,107 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// compactBufPool holds buffers used for encoding compact promises.
+var compactBufPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, CompactPromiseSize)
+		return &b
+	},
+}
+
+// PromiseEncoder writes compactly encoded promises to a stream.
+type PromiseEncoder struct {
+	w io.Writer
+}
+
+// NewPromiseEncoder returns a new encoder writing to w.
+func NewPromiseEncoder(w io.Writer) *PromiseEncoder {
+	return &PromiseEncoder{w: w}
+}
+
+// Encode writes the compact encoding of the promise to the stream.
+func (e *PromiseEncoder) Encode(p Promise) error {
+	buf := compactBufPool.Get().(*[]byte)
+	defer compactBufPool.Put(buf)
+
+	data, err := SignedPromise{Promise: p}.appendCompact((*buf)[:0])
+	if err != nil {
+		return err
+	}
+	*buf = data
+
+	if _, err := e.w.Write(data); err != nil {
+		return fmt.Errorf("failed to write promise: %w", err)
+	}
+	return nil
+}
+
+// PromiseDecoder reads compactly encoded promises from a stream.
+type PromiseDecoder struct {
+	r   io.Reader
+	buf [CompactPromiseSize]byte
+}
+
+// NewPromiseDecoder returns a new decoder reading from r.
+func NewPromiseDecoder(r io.Reader) *PromiseDecoder {
+	return &PromiseDecoder{r: r}
+}
+
+// Decode reads the next promise from the stream.
+//
+// io.EOF is returned once the stream ends on a promise boundary.
+func (d *PromiseDecoder) Decode() (*Promise, error) {
+	if _, err := io.ReadFull(d.r, d.buf[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read promise: %w", err)
+	}
+
+	var sp SignedPromise
+	if err := sp.UnmarshalCompact(d.buf[:]); err != nil {
+		return nil, err
+	}
+	return &sp.Promise, nil
+}
+
+// StreamPromises decodes promises from r passing each to the handler
+// until the stream ends or the handler returns an error.
+func StreamPromises(r io.Reader, handler func(*Promise) error) error {
+	d := NewPromiseDecoder(r)
+	for {
+		p, err := d.Decode()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := handler(p); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,98 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromiseStream(t *testing.T) {
+	promises := make([]Promise, 3)
+	var buf bytes.Buffer
+	enc := NewPromiseEncoder(&buf)
+	for n := range promises {
+		promises[n] = getPromise("consumer")
+		promises[n].Hashlock = Pad(promises[n].Hashlock, 32)
+		promises[n].Amount.SetInt64(int64(n))
+		assert.NoError(t, enc.Encode(promises[n]))
+	}
+	assert.Equal(t, len(promises)*CompactPromiseSize, buf.Len())
+
+	t.Run("streams all promises", func(t *testing.T) {
+		var decoded []*Promise
+		assert.NoError(t, StreamPromises(bytes.NewReader(buf.Bytes()), func(p *Promise) error {
+			decoded = append(decoded, p)
+			return nil
+		}))
+
+		assert.Len(t, decoded, len(promises))
+		for n, p := range decoded {
+			assert.Equal(t, promises[n].Amount.String(), p.Amount.String())
+			assert.Equal(t, promises[n].Signature, p.Signature)
+		}
+	})
+	t.Run("stops on handler error", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		err := StreamPromises(bytes.NewReader(buf.Bytes()), func(p *Promise) error {
+			calls++
+			return stop
+		})
+		assert.Equal(t, stop, err)
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("rejects truncated stream", func(t *testing.T) {
+		d := NewPromiseDecoder(bytes.NewReader(buf.Bytes()[:CompactPromiseSize+10]))
+		_, err := d.Decode()
+		assert.NoError(t, err)
+
+		_, err = d.Decode()
+		assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	})
+	t.Run("rejects promises which can't be encoded", func(t *testing.T) {
+		assert.True(t, errors.Is(enc.Encode(getPromise("provider")), ErrCompactEncoding))
+	})
+}
+
+func BenchmarkStreamPromises(b *testing.B) {
+	p := getPromise("consumer")
+	p.Hashlock = Pad(p.Hashlock, 32)
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	enc := NewPromiseEncoder(w)
+	for n := 0; n < b.N; n++ {
+		if err := enc.Encode(p); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	if err := StreamPromises(bufio.NewReader(&buf), func(*Promise) error { return nil }); err != nil {
+		b.Fatal(err)
+	}
+}
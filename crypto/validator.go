@@ -0,0 +1,86 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Promise validation failure reasons reported to Metrics.
+const (
+	ValidationFailureSignerMismatch = "signer_mismatch"
+	ValidationFailureInvalid        = "invalid_signature"
+)
+
+// Metrics is used to observe promise validation.
+type Metrics interface {
+	ObserveValidationLatency(d time.Duration)
+	IncValidationSuccess()
+	IncValidationFailure(reason string)
+}
+
+// NoopPromiseMetrics discards all metrics.
+type NoopPromiseMetrics struct{}
+
+// ObserveValidationLatency does nothing.
+func (NoopPromiseMetrics) ObserveValidationLatency(d time.Duration) {}
+
+// IncValidationSuccess does nothing.
+func (NoopPromiseMetrics) IncValidationSuccess() {}
+
+// IncValidationFailure does nothing.
+func (NoopPromiseMetrics) IncValidationFailure(reason string) {}
+
+// PromiseValidator validates promises of a single signer reporting metrics.
+type PromiseValidator struct {
+	expectedSigner common.Address
+	metrics        Metrics
+}
+
+// NewInstrumentedPromiseValidator returns a new validator for promises of the expected signer.
+// If no metrics are given, NoopPromiseMetrics is used.
+func NewInstrumentedPromiseValidator(expectedSigner common.Address, m Metrics) *PromiseValidator {
+	if m == nil {
+		m = NoopPromiseMetrics{}
+	}
+
+	return &PromiseValidator{
+		expectedSigner: expectedSigner,
+		metrics:        m,
+	}
+}
+
+// Validate validates if the promise is signed by the expected signer.
+func (v *PromiseValidator) Validate(p Promise) error {
+	start := time.Now()
+	err := p.ValidatePromise(v.expectedSigner)
+	v.metrics.ObserveValidationLatency(time.Since(start))
+
+	switch {
+	case err == nil:
+		v.metrics.IncValidationSuccess()
+	case errors.Is(err, ErrPromiseSignerMismatch):
+		v.metrics.IncValidationFailure(ValidationFailureSignerMismatch)
+	default:
+		v.metrics.IncValidationFailure(ValidationFailureInvalid)
+	}
+
+	return err
+}
@@ -0,0 +1,86 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingMetrics struct {
+	latencies []time.Duration
+	successes int
+	failures  []string
+	m         sync.Mutex
+}
+
+func (r *recordingMetrics) ObserveValidationLatency(d time.Duration) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.latencies = append(r.latencies, d)
+}
+
+func (r *recordingMetrics) IncValidationSuccess() {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.successes++
+}
+
+func (r *recordingMetrics) IncValidationFailure(reason string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.failures = append(r.failures, reason)
+}
+
+func TestPromiseValidator(t *testing.T) {
+	expectedSigner := common.HexToAddress("0xf53acdd584ccb85ee4ec1590007ad3c16fdff057")
+
+	t.Run("reports failure reasons", func(t *testing.T) {
+		m := &recordingMetrics{}
+		promise := getPromise("consumer")
+
+		assert.Error(t, NewInstrumentedPromiseValidator(common.HexToAddress("0x1"), m).Validate(promise))
+
+		promise.Signature = []byte{1, 2, 3}
+		assert.Error(t, NewInstrumentedPromiseValidator(expectedSigner, m).Validate(promise))
+
+		assert.Equal(t, []string{ValidationFailureSignerMismatch, ValidationFailureInvalid}, m.failures)
+		assert.Equal(t, 0, m.successes)
+	})
+	t.Run("observes latency of every validation", func(t *testing.T) {
+		m := &recordingMetrics{}
+		v := NewInstrumentedPromiseValidator(expectedSigner, m)
+		promise := getPromise("consumer")
+		for n := 0; n < 1000; n++ {
+			assert.NoError(t, v.Validate(promise))
+		}
+
+		assert.Equal(t, 1000, m.successes)
+		assert.Len(t, m.latencies, 1000)
+		for _, d := range m.latencies {
+			assert.True(t, d >= 0 && d < time.Second, "unexpected validation latency %v", d)
+		}
+	})
+	t.Run("uses noop metrics by default", func(t *testing.T) {
+		v := NewInstrumentedPromiseValidator(expectedSigner, nil)
+		assert.NoError(t, v.Validate(getPromise("consumer")))
+	})
+}
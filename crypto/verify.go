@@ -0,0 +1,114 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Promise verification failures returned as part of VerifyError.
+var (
+	ErrPromiseFeeTooHigh       = errors.New("promise fee is too high")
+	ErrPromiseZeroFee          = errors.New("promise fee is zero")
+	ErrPromiseAmountOutOfRange = errors.New("promise amount is out of range")
+)
+
+// VerifyOpts configures promise verification.
+// Nil limits are not checked.
+type VerifyOpts struct {
+	ExpectedSigner common.Address
+	MaxFee         *big.Int
+	MinAmount      *big.Int
+	MaxAmount      *big.Int
+	AllowZeroFee   bool
+}
+
+// VerifyError holds all failures found while verifying a promise.
+type VerifyError struct {
+	errs []error
+}
+
+func (e *VerifyError) Error() string {
+	msgs := make([]string, 0, len(e.errs))
+	for _, err := range e.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return "promise verification failed: " + strings.Join(msgs, "; ")
+}
+
+// Errors returns each of the verification failures.
+func (e *VerifyError) Errors() []error {
+	return append([]error(nil), e.errs...)
+}
+
+// Unwrap returns the verification failures so that errors.Is and
+// errors.As match any of them.
+func (e *VerifyError) Unwrap() []error {
+	return e.Errors()
+}
+
+// Is returns true if any of the verification failures matches the target.
+func (e *VerifyError) Is(target error) bool {
+	for _, err := range e.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify checks that the promise is signed by the expected signer and that
+// it's amount and fee are within the given limits.
+//
+// A *VerifyError holding all failures is returned if any of the checks fail.
+func (p Promise) Verify(opts VerifyOpts) error {
+	var errs []error
+	if err := p.ValidatePromise(opts.ExpectedSigner); err != nil {
+		errs = append(errs, err)
+	}
+
+	amount := canonicalAmount(p.Amount)
+	fee := canonicalAmount(p.Fee)
+
+	if fee.Cmp(amount) > 0 {
+		errs = append(errs, fmt.Errorf("fee %v is greater than amount %v: %w", fee, amount, ErrPromiseFeeTooHigh))
+	} else if opts.MaxFee != nil && fee.Cmp(opts.MaxFee) > 0 {
+		errs = append(errs, fmt.Errorf("fee %v is greater than %v: %w", fee, opts.MaxFee, ErrPromiseFeeTooHigh))
+	}
+	if !opts.AllowZeroFee && fee.Sign() == 0 {
+		errs = append(errs, ErrPromiseZeroFee)
+	}
+
+	switch {
+	case amount.Sign() == 0:
+		errs = append(errs, fmt.Errorf("amount is zero: %w", ErrPromiseAmountOutOfRange))
+	case opts.MinAmount != nil && amount.Cmp(opts.MinAmount) < 0:
+		errs = append(errs, fmt.Errorf("amount %v is less than %v: %w", amount, opts.MinAmount, ErrPromiseAmountOutOfRange))
+	case opts.MaxAmount != nil && amount.Cmp(opts.MaxAmount) > 0:
+		errs = append(errs, fmt.Errorf("amount %v is greater than %v: %w", amount, opts.MaxAmount, ErrPromiseAmountOutOfRange))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &VerifyError{errs: errs}
+}
@@ -0,0 +1,78 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromiseVerify(t *testing.T) {
+	dir, ks := tmpKeyStore(t, false)
+	defer os.RemoveAll(dir)
+
+	account, err := ks.ImportECDSA(getPrivKey("consumer"), "")
+	assert.NoError(t, err)
+	assert.NoError(t, ks.Unlock(account, ""))
+
+	p := getParams("consumer")
+	create := func(amount, fee int64) Promise {
+		promise, err := CreatePromise(hex.EncodeToString(p.ChannelID), 1, big.NewInt(amount), big.NewInt(fee), hex.EncodeToString(p.Hashlock), ks, account.Address)
+		assert.NoError(t, err)
+		return *promise
+	}
+
+	t.Run("valid promise", func(t *testing.T) {
+		assert.NoError(t, create(100, 1).Verify(VerifyOpts{
+			ExpectedSigner: account.Address,
+			MaxFee:         big.NewInt(10),
+			MinAmount:      big.NewInt(1),
+			MaxAmount:      big.NewInt(1000),
+		}))
+	})
+	t.Run("fee greater than amount is the only failure", func(t *testing.T) {
+		err := create(10, 20).Verify(VerifyOpts{ExpectedSigner: account.Address})
+
+		var verifyErr *VerifyError
+		assert.True(t, errors.As(err, &verifyErr))
+		assert.Len(t, verifyErr.Errors(), 1)
+		assert.True(t, errors.Is(verifyErr.Errors()[0], ErrPromiseFeeTooHigh))
+		assert.True(t, errors.Is(err, ErrPromiseFeeTooHigh))
+		assert.False(t, errors.Is(err, ErrPromiseSignerMismatch))
+	})
+	t.Run("collects all failures", func(t *testing.T) {
+		err := create(10, 0).Verify(VerifyOpts{
+			ExpectedSigner: common.HexToAddress("0x1"),
+			MinAmount:      big.NewInt(100),
+		})
+
+		var verifyErr *VerifyError
+		assert.True(t, errors.As(err, &verifyErr))
+		assert.Len(t, verifyErr.Errors(), 3)
+		assert.True(t, errors.Is(err, ErrPromiseSignerMismatch))
+		assert.True(t, errors.Is(err, ErrPromiseZeroFee))
+		assert.True(t, errors.Is(err, ErrPromiseAmountOutOfRange))
+
+		assert.NoError(t, create(10, 0).Verify(VerifyOpts{ExpectedSigner: account.Address, AllowZeroFee: true}))
+	})
+}
@@ -0,0 +1,171 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrChannelNotWhitelisted is returned if a promise is issued for a channel which is not whitelisted.
+type ErrChannelNotWhitelisted struct {
+	ChannelID string
+}
+
+func (e ErrChannelNotWhitelisted) Error() string {
+	return fmt.Sprintf("channel %s is not whitelisted", e.ChannelID)
+}
+
+// ChannelWhitelist holds channel IDs promises are accepted from.
+//
+// A whitelist without any channels ever added allows all channels.
+// Once configured, removing every channel leaves no channel allowed.
+type ChannelWhitelist struct {
+	channels   map[common.Hash]struct{}
+	restricted bool
+	m          sync.RWMutex
+}
+
+// NewChannelWhitelist returns a new whitelist holding the given channel IDs.
+func NewChannelWhitelist(channelIDs ...string) (*ChannelWhitelist, error) {
+	wl := &ChannelWhitelist{
+		channels: make(map[common.Hash]struct{}),
+	}
+	for _, id := range channelIDs {
+		if err := wl.Add(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return wl, nil
+}
+
+// LoadWhitelistFromFile loads a whitelist from a file holding one channel ID per line.
+// Empty lines are skipped. The loaded whitelist is restricted even if the file is empty.
+func LoadWhitelistFromFile(path string) (*ChannelWhitelist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open whitelist file: %w", err)
+	}
+	defer f.Close()
+
+	wl := &ChannelWhitelist{
+		channels:   make(map[common.Hash]struct{}),
+		restricted: true,
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := wl.Add(line); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read whitelist file: %w", err)
+	}
+
+	return wl, nil
+}
+
+// Add adds the channel ID to the whitelist.
+//
+// Channel ID must be either an address or a 32 byte hex string.
+func (wl *ChannelWhitelist) Add(channelID string) error {
+	key, err := parseWhitelistChannelID(channelID)
+	if err != nil {
+		return err
+	}
+
+	wl.m.Lock()
+	defer wl.m.Unlock()
+
+	wl.channels[key] = struct{}{}
+	wl.restricted = true
+	return nil
+}
+
+// Remove removes the channel ID from the whitelist.
+//
+// Removing the last channel does not make the whitelist allow all channels again.
+func (wl *ChannelWhitelist) Remove(channelID string) {
+	key, err := parseWhitelistChannelID(channelID)
+	if err != nil {
+		return
+	}
+
+	wl.m.Lock()
+	defer wl.m.Unlock()
+
+	delete(wl.channels, key)
+}
+
+// IsAllowed returns true if promises for the given channel should be accepted.
+func (wl *ChannelWhitelist) IsAllowed(channelID string) bool {
+	return wl.isAllowed(common.FromHex(channelID))
+}
+
+// AllowAll returns true if no channels were ever whitelisted
+// meaning that all channels are allowed.
+func (wl *ChannelWhitelist) AllowAll() bool {
+	wl.m.RLock()
+	defer wl.m.RUnlock()
+
+	return !wl.restricted
+}
+
+func (wl *ChannelWhitelist) isAllowed(channelID []byte) bool {
+	wl.m.RLock()
+	defer wl.m.RUnlock()
+
+	if !wl.restricted {
+		return true
+	}
+
+	_, ok := wl.channels[channelKey(channelID)]
+	return ok
+}
+
+func parseWhitelistChannelID(channelID string) (common.Hash, error) {
+	if !common.IsHexAddress(channelID) && len(common.FromHex(channelID)) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid channel ID in whitelist: %q", channelID)
+	}
+	return channelKey(common.FromHex(channelID)), nil
+}
+
+// channelKey left pads channel IDs so that both addresses
+// and 32 byte channel IDs can be used interchangeably.
+func channelKey(channelID []byte) common.Hash {
+	return common.BytesToHash(channelID)
+}
+
+// ValidatePromiseWithWhitelist validates if the promise is signed by the expected signer
+// and that it's channel is whitelisted.
+func (p Promise) ValidatePromiseWithWhitelist(expectedSigner common.Address, wl *ChannelWhitelist) error {
+	if wl != nil && !wl.isAllowed(p.ChannelID) {
+		return ErrChannelNotWhitelisted{ChannelID: common.BytesToHash(p.ChannelID).Hex()}
+	}
+
+	return p.ValidatePromise(expectedSigner)
+}
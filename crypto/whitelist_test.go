@@ -0,0 +1,101 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package crypto
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelWhitelist(t *testing.T) {
+	expectedSigner := common.HexToAddress("0xf53acdd584ccb85ee4ec1590007ad3c16fdff057")
+	promise := getPromise("consumer")
+	channelID := common.BytesToHash(promise.ChannelID).Hex()
+
+	t.Run("empty whitelist allows all channels", func(t *testing.T) {
+		wl, err := NewChannelWhitelist()
+		assert.NoError(t, err)
+		assert.True(t, wl.AllowAll())
+		assert.True(t, wl.IsAllowed(channelID))
+		assert.NoError(t, promise.ValidatePromiseWithWhitelist(expectedSigner, wl))
+	})
+	t.Run("rejects unknown channels", func(t *testing.T) {
+		wl, err := NewChannelWhitelist("0x0000000000000000000000000000000000000001")
+		assert.NoError(t, err)
+		assert.False(t, wl.AllowAll())
+		assert.True(t, wl.IsAllowed("0x0000000000000000000000000000000000000001"))
+		assert.False(t, wl.IsAllowed(channelID))
+
+		err = promise.ValidatePromiseWithWhitelist(expectedSigner, wl)
+		var notWhitelisted ErrChannelNotWhitelisted
+		assert.True(t, errors.As(err, &notWhitelisted))
+		assert.Equal(t, channelID, notWhitelisted.ChannelID)
+
+		assert.NoError(t, wl.Add(channelID))
+		assert.NoError(t, promise.ValidatePromiseWithWhitelist(expectedSigner, wl))
+
+		wl.Remove(channelID)
+		assert.Error(t, promise.ValidatePromiseWithWhitelist(expectedSigner, wl))
+
+		wl.Remove("0x0000000000000000000000000000000000000001")
+		assert.False(t, wl.AllowAll(), "whitelist should stay closed once emptied")
+		assert.False(t, wl.IsAllowed(channelID))
+	})
+	t.Run("rejects invalid channel IDs", func(t *testing.T) {
+		wl, err := NewChannelWhitelist()
+		assert.NoError(t, err)
+		for _, id := range []string{"", "not hex", "0x01"} {
+			assert.Error(t, wl.Add(id), id)
+		}
+		assert.True(t, wl.AllowAll())
+		assert.True(t, wl.IsAllowed(channelID))
+
+		_, err = NewChannelWhitelist("")
+		assert.Error(t, err)
+	})
+	t.Run("loads whitelist from file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "whitelist")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "whitelist")
+		assert.NoError(t, ioutil.WriteFile(path, []byte(channelID+"\n\n0x0000000000000000000000000000000000000001\n"), 0600))
+
+		wl, err := LoadWhitelistFromFile(path)
+		assert.NoError(t, err)
+		assert.False(t, wl.AllowAll())
+		assert.True(t, wl.IsAllowed(channelID))
+		assert.True(t, wl.IsAllowed("0x0000000000000000000000000000000000000001"))
+		assert.False(t, wl.IsAllowed("0x0000000000000000000000000000000000000002"))
+
+		assert.NoError(t, ioutil.WriteFile(path, []byte("not a channel\n"), 0600))
+		_, err = LoadWhitelistFromFile(path)
+		assert.Error(t, err)
+
+		assert.NoError(t, ioutil.WriteFile(path, []byte("\n"), 0600))
+		wl, err = LoadWhitelistFromFile(path)
+		assert.NoError(t, err)
+		assert.False(t, wl.AllowAll(), "empty whitelist file should not allow all channels")
+		assert.False(t, wl.IsAllowed(channelID))
+	})
+}
@@ -3,6 +3,9 @@
 package main
 
 import (
+	"fmt"
+	"go/build"
+	"runtime"
 	"strings"
 
 	"github.com/magefile/mage/sh"
@@ -27,3 +30,36 @@ func GenerateMatic() error {
 func Test() error {
 	return sh.RunV("go", "test", "--short", "-race", "-cover", "./...")
 }
+
+// fuzzTargets lists fuzz tests run by Fuzz keyed by their package.
+var fuzzTargets = map[string][]string{
+	"./crypto": {"FuzzPromiseUnmarshalCompact", "FuzzPromiseBase64URL"},
+}
+
+// Fuzz runs a short fuzzing session of every fuzz test.
+//
+// Fuzzing requires Go 1.18, it's skipped on older versions.
+func Fuzz() error {
+	if !supportsFuzzing() {
+		fmt.Printf("skipping fuzzing, it requires go1.18 or newer, got %s\n", runtime.Version())
+		return nil
+	}
+
+	for pkg, targets := range fuzzTargets {
+		for _, target := range targets {
+			if err := sh.RunV("go", "test", "-run=^$", "-fuzz=^"+target+"$", "-fuzztime=1000x", pkg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func supportsFuzzing() bool {
+	for _, tag := range build.Default.ReleaseTags {
+		if tag == "go1.18" {
+			return true
+		}
+	}
+	return false
+}
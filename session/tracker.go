@@ -0,0 +1,203 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+)
+
+// ErrSessionNotFound is returned if a session is not being tracked.
+var ErrSessionNotFound = errors.New("session not found")
+
+// ErrPromiseOutOfOrder is returned if a promise has a lower amount than the previous promise of the session.
+var ErrPromiseOutOfOrder = errors.New("promise amount is lower than the previous promise amount")
+
+// ErrChannelMismatch is returned if a promise belongs to a different channel than the session.
+var ErrChannelMismatch = errors.New("promise channel does not match the session channel")
+
+// SessionSummary describes a session and the promises issued during it.
+type SessionSummary struct {
+	SessionID string
+	ChannelID []byte
+	// Amount and Fee are taken from the latest promise of the session.
+	Amount       *big.Int
+	Fee          *big.Int
+	PromiseCount int
+	StartedAt    time.Time
+	ClosedAt     time.Time
+}
+
+// SessionClosed is emitted when a session is closed.
+type SessionClosed struct {
+	Summary SessionSummary
+	// Idle is true if the session was closed because its promise amounts stopped increasing.
+	Idle bool
+}
+
+// SessionTracker groups promises issued during a service session.
+//
+// Promise amounts are cumulative, so every new promise of a session
+// must have an amount that is equal or greater than the previous one.
+type SessionTracker struct {
+	idleTimeout time.Duration
+	onClosed    func(SessionClosed)
+
+	sessions map[string]*trackedSession
+	now      func() time.Time
+	m        sync.Mutex
+}
+
+type trackedSession struct {
+	channelID    []byte
+	amount       *big.Int
+	fee          *big.Int
+	promiseCount int
+	startedAt    time.Time
+	lastIncrease time.Time
+}
+
+// NewSessionTracker returns a new session tracker.
+//
+// Sessions which have not received an increasing promise for the given idle timeout
+// are closed by `CloseIdle`. The given onClosed func is called for every closed session,
+// it can be nil.
+func NewSessionTracker(idleTimeout time.Duration, onClosed func(SessionClosed)) *SessionTracker {
+	return &SessionTracker{
+		idleTimeout: idleTimeout,
+		onClosed:    onClosed,
+		sessions:    make(map[string]*trackedSession),
+		now:         time.Now,
+	}
+}
+
+// AddPromise adds a promise to the given session, starting the session if it's not tracked yet.
+func (st *SessionTracker) AddPromise(sessionID string, p crypto.Promise) error {
+	if p.Amount == nil || p.Fee == nil {
+		return errors.New("promise amount and fee must be set")
+	}
+
+	st.m.Lock()
+	defer st.m.Unlock()
+
+	now := st.now()
+	s, ok := st.sessions[sessionID]
+	if !ok {
+		st.sessions[sessionID] = &trackedSession{
+			channelID:    p.ChannelID,
+			amount:       new(big.Int).Set(p.Amount),
+			fee:          new(big.Int).Set(p.Fee),
+			promiseCount: 1,
+			startedAt:    now,
+			lastIncrease: now,
+		}
+		return nil
+	}
+
+	if !bytes.Equal(s.channelID, p.ChannelID) {
+		return ErrChannelMismatch
+	}
+
+	switch p.Amount.Cmp(s.amount) {
+	case -1:
+		return fmt.Errorf("session %q got amount %v after %v: %w", sessionID, p.Amount, s.amount, ErrPromiseOutOfOrder)
+	case 1:
+		s.lastIncrease = now
+	}
+
+	s.amount = new(big.Int).Set(p.Amount)
+	s.fee = new(big.Int).Set(p.Fee)
+	s.promiseCount++
+	return nil
+}
+
+// ActiveSessions returns IDs of all sessions which are still open.
+func (st *SessionTracker) ActiveSessions() []string {
+	st.m.Lock()
+	defer st.m.Unlock()
+
+	ids := make([]string, 0, len(st.sessions))
+	for id := range st.sessions {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Close closes the given session and returns its summary.
+func (st *SessionTracker) Close(sessionID string) (*SessionSummary, error) {
+	st.m.Lock()
+	summary, err := st.close(sessionID)
+	st.m.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	st.emit(SessionClosed{Summary: summary})
+	return &summary, nil
+}
+
+// CloseIdle closes all sessions whose promise amounts stopped increasing
+// for longer than the idle timeout. Summaries of closed sessions are returned.
+func (st *SessionTracker) CloseIdle() []SessionSummary {
+	st.m.Lock()
+	now := st.now()
+	closed := make([]SessionSummary, 0)
+	for id, s := range st.sessions {
+		if now.Sub(s.lastIncrease) < st.idleTimeout {
+			continue
+		}
+
+		summary, _ := st.close(id)
+		closed = append(closed, summary)
+	}
+	st.m.Unlock()
+
+	for _, summary := range closed {
+		st.emit(SessionClosed{Summary: summary, Idle: true})
+	}
+	return closed
+}
+
+func (st *SessionTracker) close(sessionID string) (SessionSummary, error) {
+	s, ok := st.sessions[sessionID]
+	if !ok {
+		return SessionSummary{}, ErrSessionNotFound
+	}
+	delete(st.sessions, sessionID)
+
+	return SessionSummary{
+		SessionID:    sessionID,
+		ChannelID:    s.channelID,
+		Amount:       s.amount,
+		Fee:          s.fee,
+		PromiseCount: s.promiseCount,
+		StartedAt:    s.startedAt,
+		ClosedAt:     st.now(),
+	}, nil
+}
+
+func (st *SessionTracker) emit(ev SessionClosed) {
+	if st.onClosed != nil {
+		st.onClosed(ev)
+	}
+}
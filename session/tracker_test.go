@@ -0,0 +1,121 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func promise(amount int64) crypto.Promise {
+	return crypto.Promise{
+		ChannelID: []byte{1},
+		ChainID:   1,
+		Amount:    big.NewInt(amount),
+		Fee:       big.NewInt(1),
+	}
+}
+
+func TestSessionTracker(t *testing.T) {
+	t.Run("tracks and closes sessions", func(t *testing.T) {
+		var closed []SessionClosed
+		st := NewSessionTracker(time.Minute, func(ev SessionClosed) {
+			closed = append(closed, ev)
+		})
+
+		assert.NoError(t, st.AddPromise("s1", promise(1)))
+		assert.NoError(t, st.AddPromise("s1", promise(5)))
+		assert.NoError(t, st.AddPromise("s2", promise(1)))
+		assert.ElementsMatch(t, []string{"s1", "s2"}, st.ActiveSessions())
+
+		summary, err := st.Close("s1")
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(5), summary.Amount)
+		assert.Equal(t, big.NewInt(1), summary.Fee)
+		assert.Equal(t, 2, summary.PromiseCount)
+		assert.Equal(t, []string{"s2"}, st.ActiveSessions())
+		assert.Len(t, closed, 1)
+
+		_, err = st.Close("s1")
+		assert.Equal(t, ErrSessionNotFound, err)
+	})
+	t.Run("rejects out of order promises", func(t *testing.T) {
+		st := NewSessionTracker(time.Minute, nil)
+
+		assert.NoError(t, st.AddPromise("s1", promise(5)))
+		err := st.AddPromise("s1", promise(4))
+		assert.True(t, errors.Is(err, ErrPromiseOutOfOrder))
+
+		p := promise(6)
+		p.ChannelID = []byte{2}
+		assert.Equal(t, ErrChannelMismatch, st.AddPromise("s1", p))
+	})
+	t.Run("closes sessions whose amount stopped increasing", func(t *testing.T) {
+		var closed []SessionClosed
+		st := NewSessionTracker(time.Minute, func(ev SessionClosed) {
+			closed = append(closed, ev)
+		})
+		now := time.Now()
+		st.now = func() time.Time { return now }
+
+		assert.NoError(t, st.AddPromise("idle", promise(1)))
+		assert.NoError(t, st.AddPromise("active", promise(1)))
+
+		now = now.Add(time.Minute)
+		assert.NoError(t, st.AddPromise("idle", promise(1)))
+		assert.NoError(t, st.AddPromise("active", promise(2)))
+
+		summaries := st.CloseIdle()
+		assert.Len(t, summaries, 1)
+		assert.Equal(t, "idle", summaries[0].SessionID)
+		assert.Equal(t, []string{"active"}, st.ActiveSessions())
+		assert.Len(t, closed, 1)
+		assert.True(t, closed[0].Idle)
+	})
+	t.Run("handles concurrent promises for the same session", func(t *testing.T) {
+		st := NewSessionTracker(time.Minute, nil)
+
+		var wg sync.WaitGroup
+		var accepted int
+		var m sync.Mutex
+		for g := 0; g < 10; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for n := 1; n <= 100; n++ {
+					if err := st.AddPromise("s1", promise(int64(n*10+g))); err == nil {
+						m.Lock()
+						accepted++
+						m.Unlock()
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		summary, err := st.Close("s1")
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(1009), summary.Amount)
+		assert.Equal(t, accepted, summary.PromiseCount)
+	})
+}
@@ -0,0 +1,149 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// archiveInterval is how often finalized transactions are checked for archiving.
+const archiveInterval = time.Hour
+
+// Archiver moves finalized transactions to cold storage.
+type Archiver interface {
+	Archive(txs []Transaction) error
+}
+
+// ArchiverConfig configures archiving of finalized transactions.
+type ArchiverConfig struct {
+	Archiver Archiver
+	// ArchiveAfter is how long a transaction is kept in the storage after it's been finalized.
+	ArchiveAfter time.Duration
+}
+
+func (c ArchiverConfig) enabled() bool {
+	return c.Archiver != nil && c.ArchiveAfter > 0
+}
+
+// ArchiveFinalized archives all transactions finalized earlier than the configured
+// ArchiveAfter duration and removes them from the storage.
+func (i *GasPriceIncremenetor) ArchiveFinalized() error {
+	if !i.cfg.ArchiverConfig.enabled() {
+		return nil
+	}
+
+	txs, err := i.storage.GetIncrementorFinalizedBefore(time.Now().UTC().Add(-i.cfg.ArchiverConfig.ArchiveAfter))
+	if err != nil {
+		return fmt.Errorf("failed to get finalized transactions: %w", err)
+	}
+	if len(txs) == 0 {
+		return nil
+	}
+
+	if err := i.cfg.ArchiverConfig.Archiver.Archive(txs); err != nil {
+		return fmt.Errorf("failed to archive transactions: %w", err)
+	}
+
+	ids := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		ids = append(ids, tx.UniqueID)
+	}
+	if err := i.storage.DeleteIncrementorTransactions(ids); err != nil {
+		return fmt.Errorf("failed to delete archived transactions: %w", err)
+	}
+
+	return nil
+}
+
+type fileArchiver struct {
+	dir string
+	now func() time.Time
+	m   sync.Mutex
+}
+
+// NewFileArchiver returns an archiver which appends transactions as
+// newline delimited JSON to a gzipped file in the given directory.
+// A new file is started every day.
+func NewFileArchiver(dir string) Archiver {
+	return &fileArchiver{
+		dir: dir,
+		now: time.Now,
+	}
+}
+
+// Archive appends the transactions to the archive file of the day and syncs
+// it to disk, as archived transactions are deleted from the storage next.
+func (a *fileArchiver) Archive(txs []Transaction) error {
+	// Every call appends a new gzip member which gzip readers handle as
+	// a single stream. It's encoded fully before writing so a failure
+	// can't leave a truncated member corrupting the whole file.
+	var member bytes.Buffer
+	zw := gzip.NewWriter(&member)
+	enc := json.NewEncoder(zw)
+	for _, tx := range txs {
+		if err := enc.Encode(tx); err != nil {
+			return fmt.Errorf("failed to encode transaction: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress transactions: %w", err)
+	}
+
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	name := fmt.Sprintf("transactions-%s.ndjson.gz", a.now().UTC().Format("2006-01-02"))
+	f, err := os.OpenFile(filepath.Join(a.dir, name), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open archive file: %w", err)
+	}
+
+	if err := appendSynced(f, member.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close archive file: %w", err)
+	}
+	return nil
+}
+
+// appendSynced appends data to the file in a single write and syncs it.
+// The file is truncated back to its previous size if writing fails.
+func appendSynced(f *os.File, data []byte) error {
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat archive file: %w", err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Truncate(info.Size())
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync archive file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileArchiver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2021, 7, 1, 23, 0, 0, 0, time.UTC)
+	a := NewFileArchiver(dir).(*fileArchiver)
+	a.now = func() time.Time { return now }
+
+	assert.NoError(t, a.Archive([]Transaction{{UniqueID: "1"}, {UniqueID: "2"}}))
+	assert.NoError(t, a.Archive([]Transaction{{UniqueID: "3"}}))
+	now = now.Add(2 * time.Hour)
+	assert.NoError(t, a.Archive([]Transaction{{UniqueID: "4"}}))
+
+	read := func(name string) []string {
+		f, err := os.Open(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		defer f.Close()
+
+		zr, err := gzip.NewReader(f)
+		assert.NoError(t, err)
+
+		var ids []string
+		scanner := bufio.NewScanner(zr)
+		for scanner.Scan() {
+			var tx Transaction
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &tx))
+			ids = append(ids, tx.UniqueID)
+		}
+		assert.NoError(t, scanner.Err())
+		return ids
+	}
+
+	assert.Equal(t, []string{"1", "2", "3"}, read("transactions-2021-07-01.ndjson.gz"))
+	assert.Equal(t, []string{"4"}, read("transactions-2021-07-02.ndjson.gz"))
+}
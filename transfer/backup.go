@@ -0,0 +1,156 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// exportUntil is the upper creation time bound of exported transactions.
+var exportUntil = time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ExportToFile writes all stored transactions of the given chain
+// to the file as newline delimited JSON, one transaction per line.
+//
+// The file is created or truncated. Transactions are written
+// in their latest stored state ordered by creation time.
+func ExportToFile(st Storage, path string, chainID int64) (err error) {
+	txs, err := st.GetIncrementorTransactionsByTimeRange(time.Time{}, exportUntil, chainID)
+	if err != nil {
+		return fmt.Errorf("failed to get transactions from storage: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close export file: %w", closeErr)
+		}
+	}()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, tx := range txs {
+		if err := enc.Encode(tx); err != nil {
+			return fmt.Errorf("failed to write transaction %q: %w", tx.UniqueID, err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	return nil
+}
+
+// ImportFromFile upserts all transactions of a file created by ExportToFile
+// returning the amount of imported transactions.
+//
+// Importing stops at the first line which can't be parsed. Transactions
+// read before it stay imported, use ValidateExportFile to check the file first.
+func ImportFromFile(st Storage, path string) (int, error) {
+	var imported int
+	err := readExportFile(path, func(line int, data []byte) error {
+		var tx Transaction
+		if err := json.Unmarshal(data, &tx); err != nil {
+			return fmt.Errorf("line %d: failed to parse transaction: %w", line, err)
+		}
+		if tx.UniqueID == "" {
+			return fmt.Errorf("line %d: transaction has no unique ID", line)
+		}
+
+		if err := st.UpsertIncrementorTransaction(tx); err != nil {
+			return fmt.Errorf("line %d: failed to store transaction %q: %w", line, tx.UniqueID, err)
+		}
+		imported++
+		return nil
+	})
+	return imported, err
+}
+
+// ValidateExportFile checks a file created by ExportToFile without importing it.
+//
+// Problems found in the file are returned as warnings, an error is
+// only returned if the file can't be read.
+func ValidateExportFile(path string) ([]string, error) {
+	warnings := make([]string, 0)
+	seen := make(map[string]int)
+	err := readExportFile(path, func(line int, data []byte) error {
+		var tx Transaction
+		if err := json.Unmarshal(data, &tx); err != nil {
+			warnings = append(warnings, fmt.Sprintf("line %d: failed to parse transaction: %v", line, err))
+			return nil
+		}
+
+		if tx.UniqueID == "" {
+			warnings = append(warnings, fmt.Sprintf("line %d: transaction has no unique ID", line))
+			return nil
+		}
+		if first, ok := seen[tx.UniqueID]; ok {
+			warnings = append(warnings, fmt.Sprintf("line %d: unique ID %q is already used on line %d", line, tx.UniqueID, first))
+		} else {
+			seen[tx.UniqueID] = line
+		}
+
+		chainID, err := chainIDFromUniqueID(tx.UniqueID)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("line %d: %v", line, err))
+		} else if chainID != tx.ChainID {
+			warnings = append(warnings, fmt.Sprintf("line %d: unique ID %q does not match chain %d", line, tx.UniqueID, tx.ChainID))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return warnings, nil
+}
+
+// readExportFile calls fn with every non empty line of the file and its number.
+func readExportFile(path string, fn func(line int, data []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open export file: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for line := 1; ; line++ {
+		data, err := r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read export file: %w", err)
+		}
+
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			if err := fn(line, data); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,88 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"math/big"
+	"sync"
+	"time"
+)
+
+// DefaultBlockPeriod is the estimated block time used if none is configured.
+const DefaultBlockPeriod = 15 * time.Second
+
+// blockGasBudget limits the total cost of transactions sent within a block period per chain.
+type blockGasBudget struct {
+	max    *big.Int
+	period time.Duration
+
+	periods map[int64]*gasPeriod
+	now     func() time.Time
+	m       sync.Mutex
+}
+
+type gasPeriod struct {
+	start time.Time
+	used  *big.Int
+}
+
+func newBlockGasBudget(max *big.Int, period time.Duration) *blockGasBudget {
+	if period <= 0 {
+		period = DefaultBlockPeriod
+	}
+
+	return &blockGasBudget{
+		max:     max,
+		period:  period,
+		periods: make(map[int64]*gasPeriod),
+		now:     time.Now,
+	}
+}
+
+// reserve reserves the cost in the first block period it fits in
+// and returns how long to wait until that period starts.
+//
+// A cost exceeding the budget on its own is given a whole period.
+func (b *blockGasBudget) reserve(chainID int64, cost *big.Int) time.Duration {
+	if b.max == nil || b.max.Sign() <= 0 {
+		return 0
+	}
+
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	now := b.now()
+	p, ok := b.periods[chainID]
+	if !ok || !now.Before(p.start.Add(b.period)) {
+		p = &gasPeriod{start: now, used: new(big.Int)}
+		b.periods[chainID] = p
+	}
+
+	total := new(big.Int).Add(p.used, cost)
+	if p.used.Sign() == 0 || total.Cmp(b.max) <= 0 {
+		p.used = total
+		if p.start.After(now) {
+			// Earlier reservations already moved to a future period.
+			return p.start.Sub(now)
+		}
+		return 0
+	}
+
+	next := p.start.Add(b.period)
+	b.periods[chainID] = &gasPeriod{start: next, used: new(big.Int).Set(cost)}
+	return next.Sub(now)
+}
@@ -0,0 +1,72 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_blockGasBudget(t *testing.T) {
+	now := time.Now()
+	b := newBlockGasBudget(big.NewInt(100), time.Second)
+	b.now = func() time.Time { return now }
+
+	assert.Equal(t, time.Duration(0), b.reserve(1, big.NewInt(60)))
+	assert.Equal(t, time.Second, b.reserve(1, big.NewInt(60)), "should be delayed until next block")
+	assert.Equal(t, time.Second, b.reserve(1, big.NewInt(40)), "should fit in the next block")
+	assert.Equal(t, 2*time.Second, b.reserve(1, big.NewInt(1)))
+	assert.Equal(t, time.Duration(0), b.reserve(137, big.NewInt(60)), "chains should be limited separately")
+
+	now = now.Add(5 * time.Second)
+	assert.Equal(t, time.Duration(0), b.reserve(1, big.NewInt(200)), "transaction over budget gets a whole block")
+	assert.Equal(t, time.Second, b.reserve(1, big.NewInt(1)))
+
+	t.Run("unlimited without max", func(t *testing.T) {
+		b := newBlockGasBudget(nil, time.Second)
+		assert.Equal(t, time.Duration(0), b.reserve(1, big.NewInt(1000)))
+		assert.Equal(t, time.Duration(0), b.reserve(1, big.NewInt(1000)))
+	})
+}
+
+func TestGasPriceIncrementor_MaxGasPerBlock(t *testing.T) {
+	chid := int64(137)
+	sg := newSigner()
+	period := time.Millisecond * 100
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{
+		MaxGasPerBlock: big.NewInt(100),
+		BlockPeriod:    period,
+	}, &mockStorage{}, newClient(nil), Signers{sg.address: sg.SignatureFunc})
+	defer inc.Stop()
+
+	// Every transaction costs 60 gas limit * 1 gas price.
+	send := func(nonce uint64) time.Duration {
+		start := time.Now()
+		_, err := inc.signAndSend(types.NewTransaction(nonce, common.HexToAddress("0x1"), big.NewInt(1), 60, big.NewInt(1), nil), chid, sg.address.Hex())
+		assert.NoError(t, err)
+		return time.Since(start)
+	}
+
+	assert.True(t, send(1) < period/2, "first transaction should not be delayed")
+	delay := send(2)
+	assert.True(t, delay >= period*8/10, "second transaction should be delayed by a block period, got %v", delay)
+}
@@ -0,0 +1,132 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrChainHasPendingTransactions is returned when removing a chain
+// which still has non finalized transactions.
+var ErrChainHasPendingTransactions = errors.New("chain has pending transactions")
+
+// ErrChainNotHandled is returned when inserting a transaction of a chain
+// which is not handled by the incrementor.
+var ErrChainNotHandled = errors.New("chain is not handled")
+
+// chainSet holds chains registered at runtime and their config overrides.
+//
+// Until the first chain is registered all chains are handled.
+// Once restricted, removing every chain leaves no chain handled.
+type chainSet struct {
+	chains     map[int64]*GasIncrementorConfig
+	restricted bool
+	m          sync.RWMutex
+}
+
+func newChainSet() *chainSet {
+	return &chainSet{
+		chains: make(map[int64]*GasIncrementorConfig),
+	}
+}
+
+func (s *chainSet) handles(chainID int64) bool {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.handlesLocked(chainID)
+}
+
+// handlesLocked is like handles but expects the lock to be held.
+func (s *chainSet) handlesLocked(chainID int64) bool {
+	if !s.restricted {
+		return true
+	}
+	_, ok := s.chains[chainID]
+	return ok
+}
+
+func (s *chainSet) override(chainID int64) (GasIncrementorConfig, bool) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	cfg, ok := s.chains[chainID]
+	if !ok || cfg == nil {
+		return GasIncrementorConfig{}, false
+	}
+	return *cfg, true
+}
+
+// AddChain registers a chain to be handled by the incrementor.
+//
+// Once any chain is registered, only transactions of registered chains are handled.
+// An optional config override can be given which replaces the chain's
+// MinBumpPercent and MinGasPrice. Fields left unset in the override
+// fall back to the global config.
+func (i *GasPriceIncremenetor) AddChain(chainID int64, overrides ...GasIncrementorConfig) {
+	var override *GasIncrementorConfig
+	if len(overrides) > 0 {
+		override = &overrides[0]
+	}
+
+	i.chains.m.Lock()
+	defer i.chains.m.Unlock()
+	i.chains.chains[chainID] = override
+	i.chains.restricted = true
+}
+
+// RemoveChain stops handling transactions of the given chain.
+//
+// Removing the last registered chain does not make the incrementor handle all chains again.
+//
+// ErrChainHasPendingTransactions is returned if the chain still has non finalized transactions.
+// Inserts of the chain's transactions are blocked while checking.
+func (i *GasPriceIncremenetor) RemoveChain(chainID int64) error {
+	i.chains.m.Lock()
+	defer i.chains.m.Unlock()
+
+	txs, err := i.storage.GetIncrementorTransactionsToCheck(i.signers.getSigners())
+	if err != nil {
+		return fmt.Errorf("failed to get transactions to check: %w", err)
+	}
+
+	for _, tx := range txs {
+		if tx.ChainID == chainID && !tx.State.IsTerminal() {
+			return fmt.Errorf("can't remove chain %d: %w", chainID, ErrChainHasPendingTransactions)
+		}
+	}
+
+	delete(i.chains.chains, chainID)
+	return nil
+}
+
+// ListChains returns all chains registered using AddChain.
+func (i *GasPriceIncremenetor) ListChains() []int64 {
+	i.chains.m.RLock()
+	defer i.chains.m.RUnlock()
+
+	res := make([]int64, 0, len(i.chains.chains))
+	for chainID := range i.chains.chains {
+		res = append(res, chainID)
+	}
+
+	sort.Slice(res, func(a, b int) bool { return res[a] < res[b] })
+	return res
+}
@@ -0,0 +1,98 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPriceIncrementor_Chains(t *testing.T) {
+	txs := []Transaction{
+		{UniqueID: "eth", ChainID: 1, State: TxStateCreated},
+		{UniqueID: "matic", ChainID: 137, State: TxStateCreated},
+	}
+	watched := func(inc *GasPriceIncremenetor) []string {
+		var res []string
+		inc.process(txs, func(tx Transaction) {
+			res = append(res, tx.UniqueID)
+		})
+		return res
+	}
+
+	st := &mockStorage{tx: Transaction{UniqueID: "matic", ChainID: 137, State: TxStateCreated}}
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{MinBumpPercent: 0.2}, st, newClient(nil), Signers{})
+	assert.Equal(t, []string{"eth", "matic"}, watched(inc), "all chains should be handled if none are registered")
+
+	inc.AddChain(137, GasIncrementorConfig{MinGasPrice: big.NewInt(10)})
+	assert.Equal(t, []int64{137}, inc.ListChains())
+	assert.Equal(t, []string{"matic"}, watched(inc))
+
+	inc.AddChain(1)
+	assert.Equal(t, []int64{1, 137}, inc.ListChains())
+	assert.Equal(t, []string{"eth", "matic"}, watched(inc))
+
+	t.Run("chain overrides are applied", func(t *testing.T) {
+		assert.Equal(t, big.NewInt(10), inc.chainConfig(137).MinGasPrice)
+		assert.Equal(t, 0.2, inc.minBumpPercent(137), "unset override fields should fall back to the global config")
+		assert.Nil(t, inc.chainConfig(1).MinGasPrice)
+		assert.Equal(t, 0.2, inc.minBumpPercent(1))
+
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{MinBumpPercent: 0.2, MinGasPrice: big.NewInt(5)}, st, newClient(nil), Signers{})
+		inc.AddChain(80001, GasIncrementorConfig{MinBumpPercent: 0.5})
+		assert.Equal(t, 0.5, inc.minBumpPercent(80001))
+		assert.Equal(t, big.NewInt(5), inc.chainConfig(80001).MinGasPrice)
+	})
+
+	t.Run("chain with pending transactions can't be removed", func(t *testing.T) {
+		err := inc.RemoveChain(137)
+		assert.True(t, errors.Is(err, ErrChainHasPendingTransactions))
+		assert.Equal(t, []int64{1, 137}, inc.ListChains())
+	})
+
+	assert.NoError(t, inc.RemoveChain(1))
+	assert.Equal(t, []int64{137}, inc.ListChains())
+	assert.Equal(t, []string{"matic"}, watched(inc))
+
+	t.Run("transactions of chains not handled are rejected", func(t *testing.T) {
+		sg := newSigner()
+		org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 1)
+		st := &mockStorage{}
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, newClient(nil), Signers{sg.address: sg.SignatureFunc})
+
+		assert.NoError(t, inc.InsertInitial(org, defaultOpts(), sg.address), "all chains are handled if none are registered")
+
+		st = &mockStorage{}
+		inc = NewGasPriceIncremenetor(GasIncrementorConfig{}, st, newClient(nil), Signers{sg.address: sg.SignatureFunc})
+		inc.AddChain(137)
+		err := inc.InsertInitial(org, defaultOpts(), sg.address)
+		assert.True(t, errors.Is(err, ErrChainNotHandled))
+		assert.False(t, st.inserted)
+	})
+	t.Run("removing the last chain handles no chains", func(t *testing.T) {
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, &mockStorage{}, newClient(nil), Signers{})
+		inc.AddChain(1)
+		assert.NoError(t, inc.RemoveChain(1))
+		assert.Empty(t, inc.ListChains())
+		assert.Empty(t, watched(inc))
+	})
+}
@@ -0,0 +1,105 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"time"
+)
+
+// IncrementorCheckpoint is a snapshot of watched transactions used for crash recovery.
+type IncrementorCheckpoint struct {
+	CreatedAt time.Time
+	// Config holds the incrementor config without funcs and
+	// interface values, which can't be serialized.
+	Config  GasIncrementorConfig
+	Watched []CheckpointEntry
+}
+
+// CheckpointEntry is a watched transaction within a checkpoint.
+type CheckpointEntry struct {
+	UniqueID  string
+	StartedAt time.Time
+}
+
+// Checkpoint returns a gob encoded IncrementorCheckpoint of currently watched transactions.
+func (i *GasPriceIncremenetor) Checkpoint() ([]byte, error) {
+	cfg := i.cfg
+	cfg.ReceiptEventABIs = nil
+	cfg.ArchiverConfig.Archiver = nil
+	cfg.ReceiptFetcher = nil
+
+	cp := IncrementorCheckpoint{
+		CreatedAt: time.Now().UTC(),
+		Config:    cfg,
+		Watched:   make([]CheckpointEntry, 0),
+	}
+	i.syncer.forEach(func(tx Transaction, startedAt time.Time) {
+		cp.Watched = append(cp.Watched, CheckpointEntry{UniqueID: tx.UniqueID, StartedAt: startedAt})
+	})
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cp); err != nil {
+		return nil, fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeCheckpoint decodes a checkpoint created by Checkpoint.
+func DecodeCheckpoint(data []byte) (IncrementorCheckpoint, error) {
+	var cp IncrementorCheckpoint
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&cp); err != nil {
+		return IncrementorCheckpoint{}, fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// RestoreFromCheckpoint starts watching all transactions of the checkpoint
+// which are still not finalized in the storage, keeping their original start times.
+//
+// The checkpoint config is informational only and is not applied.
+func (i *GasPriceIncremenetor) RestoreFromCheckpoint(data []byte) error {
+	cp, err := DecodeCheckpoint(data)
+	if err != nil {
+		return err
+	}
+
+	stored, err := i.storage.GetIncrementorTransactionsToCheck(i.signers.getSigners())
+	if err != nil {
+		return fmt.Errorf("failed to get transactions from storage: %w", err)
+	}
+	pending := make(map[string]Transaction, len(stored))
+	for _, tx := range stored {
+		if !tx.State.IsTerminal() {
+			pending[tx.UniqueID] = tx
+		}
+	}
+
+	for _, entry := range cp.Watched {
+		tx, ok := pending[entry.UniqueID]
+		if !ok {
+			continue
+		}
+
+		i.tryWatch(tx)
+		i.syncer.txRestoreStartedAt(tx, entry.StartedAt)
+	}
+
+	return nil
+}
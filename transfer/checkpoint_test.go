@@ -0,0 +1,90 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPriceIncrementor_Checkpoint(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+	opts := defaultOpts()
+	opts.IncreaseInterval = time.Hour
+
+	tx, err := newTransaction(org, sg.address, opts)
+	assert.NoError(t, err)
+
+	watched := func(inc *GasPriceIncremenetor) map[string]time.Time {
+		res := make(map[string]time.Time)
+		inc.ForEachWatched(func(tx Transaction, startedAt time.Time) {
+			res[tx.UniqueID] = startedAt
+		})
+		return res
+	}
+
+	st := &mockStorage{}
+	assert.NoError(t, st.UpsertIncrementorTransaction(*tx))
+	cfg := GasIncrementorConfig{PullInterval: time.Minute, MinGasPrice: big.NewInt(5)}
+	crashed := NewGasPriceIncremenetor(cfg, st, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+	crashed.tryWatch(*tx)
+	crashed.tryWatch(Transaction{UniqueID: "finalized", State: TxStateSucceed, Opts: opts})
+	before := watched(crashed)
+
+	data, err := crashed.Checkpoint()
+	assert.NoError(t, err)
+	crashed.Stop()
+
+	cp, err := DecodeCheckpoint(data)
+	assert.NoError(t, err)
+	assert.Len(t, cp.Watched, 2)
+	assert.Equal(t, time.Minute, cp.Config.PullInterval)
+	assert.Equal(t, "5", cp.Config.MinGasPrice.String())
+
+	t.Run("rewatches non finalized transactions", func(t *testing.T) {
+		recovered := NewGasPriceIncremenetor(cfg, st, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+		defer recovered.Stop()
+
+		assert.NoError(t, recovered.RestoreFromCheckpoint(data))
+		after := watched(recovered)
+		assert.Len(t, after, 1)
+		assert.True(t, before[tx.UniqueID].Equal(after[tx.UniqueID]), "start time should be preserved")
+	})
+	t.Run("skips transactions finalized after checkpoint", func(t *testing.T) {
+		finalized := *tx
+		finalized.State = TxStateSucceed
+		assert.NoError(t, st.UpsertIncrementorTransaction(finalized))
+
+		recovered := NewGasPriceIncremenetor(cfg, st, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+		defer recovered.Stop()
+
+		assert.NoError(t, recovered.RestoreFromCheckpoint(data))
+		assert.Empty(t, watched(recovered))
+	})
+	t.Run("fails on malformed checkpoint", func(t *testing.T) {
+		inc := NewGasPriceIncremenetor(cfg, st, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+		defer inc.Stop()
+
+		assert.Error(t, inc.RestoreFromCheckpoint([]byte("garbage")))
+	})
+}
@@ -0,0 +1,138 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ConfigMetricsNamespace is the namespace of GasIncrementorConfig metrics.
+const ConfigMetricsNamespace = "gas_incrementor_config"
+
+type configMetric struct {
+	name  string
+	help  string
+	value func(cfg GasIncrementorConfig) float64
+}
+
+// configMetrics lists all numeric config fields exposed as gauges.
+// Durations are exposed in milliseconds and unset prices as zero.
+var configMetrics = []configMetric{
+	{"pull_interval_ms", "Interval of pulling transactions from storage.", func(cfg GasIncrementorConfig) float64 { return millis(cfg.PullInterval) }},
+	{"max_queue_per_signer", "Maximum amount of queued transactions per signer.", func(cfg GasIncrementorConfig) float64 { return float64(cfg.MaxQueuePerSigner) }},
+	{"rate_limit_max_inserts_per_second", "Maximum inserts per second per signer.", func(cfg GasIncrementorConfig) float64 { return float64(cfg.RateLimit.MaxInsertsPerSecond) }},
+	{"rate_limit_burst", "Amount of inserts allowed at once per signer.", func(cfg GasIncrementorConfig) float64 { return float64(cfg.RateLimit.Burst) }},
+	{"min_bump_percent", "Minimal gas price bump.", func(cfg GasIncrementorConfig) float64 { return cfg.MinBumpPercent }},
+	{"min_gas_price", "Global minimal gas price in wei.", func(cfg GasIncrementorConfig) float64 { return bigFloat(cfg.MinGasPrice) }},
+	{"chain_min_gas_price_refresh_interval_ms", "Interval of refreshing chain suggested gas prices used as a floor.", func(cfg GasIncrementorConfig) float64 { return millis(cfg.ChainMinGasPriceRefreshInterval) }},
+	{"max_gas_per_block", "Maximum gas used per block period.", func(cfg GasIncrementorConfig) float64 { return bigFloat(cfg.MaxGasPerBlock) }},
+	{"block_period_ms", "Block period used with max gas per block.", func(cfg GasIncrementorConfig) float64 { return millis(cfg.BlockPeriod) }},
+	{"archive_after_ms", "Time finalized transactions are kept before archiving.", func(cfg GasIncrementorConfig) float64 { return millis(cfg.ArchiverConfig.ArchiveAfter) }},
+	{"lock_ttl_ms", "Time to live of transaction locks.", func(cfg GasIncrementorConfig) float64 { return millis(cfg.LockTTL) }},
+	{"adaptive_alpha", "Weight of the latest observation of adaptive bumping.", func(cfg GasIncrementorConfig) float64 { return cfg.AdaptiveAlpha }},
+	{"adaptive_bump_threshold", "Bump threshold of adaptive bumping.", func(cfg GasIncrementorConfig) float64 { return cfg.AdaptiveBumpThreshold }},
+	{"signer_health_check_interval_ms", "Interval of signer health checks.", func(cfg GasIncrementorConfig) float64 { return millis(cfg.SignerHealthCheckInterval) }},
+	{"warn_price", "Gas price above which warnings are logged in wei.", func(cfg GasIncrementorConfig) float64 { return bigFloat(cfg.WarnPrice) }},
+	{"underpriced_max_retries", "Maximum retries of underpriced transactions.", func(cfg GasIncrementorConfig) float64 { return float64(cfg.UnderpricedMaxRetries) }},
+	{"underpriced_retry_delay_ms", "Delay between underpriced transaction retries.", func(cfg GasIncrementorConfig) float64 { return millis(cfg.UnderpricedRetryDelay) }},
+	{"underpriced_retry_multiplier", "Gas price multiplier of underpriced transaction retries.", func(cfg GasIncrementorConfig) float64 { return cfg.UnderpricedRetryMultiplier }},
+	{"storage_latency_threshold_ms", "Storage write latency above which watching is paused.", func(cfg GasIncrementorConfig) float64 { return millis(cfg.StorageLatencyThreshold) }},
+	{"storage_latency_window_ms", "Window of storage write latency tracking.", func(cfg GasIncrementorConfig) float64 { return millis(cfg.StorageLatencyWindow) }},
+	{"fee_budget_max_per_sender_per_hour", "Hourly fee budget per sender in wei.", func(cfg GasIncrementorConfig) float64 {
+		if cfg.FeeBudget == nil {
+			return 0
+		}
+		return bigFloat(cfg.FeeBudget.MaxPerSenderPerHour)
+	}},
+	{"fee_budget_max_total_per_hour", "Total hourly fee budget in wei.", func(cfg GasIncrementorConfig) float64 {
+		if cfg.FeeBudget == nil {
+			return 0
+		}
+		return bigFloat(cfg.FeeBudget.MaxTotalPerHour)
+	}},
+}
+
+// ConfigGauge exposes numeric GasIncrementorConfig fields as prometheus gauges.
+type ConfigGauge struct {
+	gauges map[string]prometheus.Gauge
+}
+
+// NewConfigGauge registers config gauges with the given registerer.
+// Gauges which are already registered are reused.
+func NewConfigGauge(reg prometheus.Registerer) (*ConfigGauge, error) {
+	g := &ConfigGauge{
+		gauges: make(map[string]prometheus.Gauge, len(configMetrics)),
+	}
+
+	for _, m := range configMetrics {
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: ConfigMetricsNamespace,
+			Name:      m.name,
+			Help:      m.help,
+		})
+		if err := reg.Register(gauge); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			if !errors.As(err, &are) {
+				return nil, fmt.Errorf("failed to register %s gauge: %w", m.name, err)
+			}
+			existing, ok := are.ExistingCollector.(prometheus.Gauge)
+			if !ok {
+				return nil, fmt.Errorf("collector %s is not a gauge: %w", m.name, err)
+			}
+			gauge = existing
+		}
+		g.gauges[m.name] = gauge
+	}
+
+	return g, nil
+}
+
+// Update sets all gauges to the values of the given config.
+func (g *ConfigGauge) Update(cfg GasIncrementorConfig) {
+	for _, m := range configMetrics {
+		g.gauges[m.name].Set(m.value(cfg))
+	}
+}
+
+// UpdateConfigMetrics registers config gauges if needed and sets them to the given config.
+func UpdateConfigMetrics(reg prometheus.Registerer, cfg GasIncrementorConfig) error {
+	g, err := NewConfigGauge(reg)
+	if err != nil {
+		return err
+	}
+
+	g.Update(cfg)
+	return nil
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func bigFloat(v *big.Int) float64 {
+	if v == nil {
+		return 0
+	}
+
+	f, _ := new(big.Float).SetInt(v).Float64()
+	return f
+}
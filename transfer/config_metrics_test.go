@@ -0,0 +1,66 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateConfigMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cfg := GasIncrementorConfig{
+		PullInterval:      1500 * time.Millisecond,
+		MaxQueuePerSigner: 12,
+		MinBumpPercent:    0.15,
+		MinGasPrice:       big.NewInt(3000000000),
+		FeeBudget:         &FeeBudget{MaxTotalPerHour: big.NewInt(42)},
+	}
+	assert.NoError(t, UpdateConfigMetrics(reg, cfg))
+
+	gather := func() map[string]float64 {
+		families, err := reg.Gather()
+		assert.NoError(t, err)
+		res := make(map[string]float64, len(families))
+		for _, f := range families {
+			res[f.GetName()] = f.GetMetric()[0].GetGauge().GetValue()
+		}
+		return res
+	}
+
+	values := gather()
+	assert.Len(t, values, len(configMetrics))
+	assert.Equal(t, 1500.0, values["gas_incrementor_config_pull_interval_ms"])
+	assert.Equal(t, 12.0, values["gas_incrementor_config_max_queue_per_signer"])
+	assert.Equal(t, 0.15, values["gas_incrementor_config_min_bump_percent"])
+	assert.Equal(t, 3000000000.0, values["gas_incrementor_config_min_gas_price"])
+	assert.Equal(t, 42.0, values["gas_incrementor_config_fee_budget_max_total_per_hour"])
+	assert.Equal(t, 0.0, values["gas_incrementor_config_fee_budget_max_per_sender_per_hour"])
+
+	cfg.MaxQueuePerSigner = 3
+	assert.NoError(t, UpdateConfigMetrics(reg, cfg), "registering twice should reuse gauges")
+	assert.Equal(t, 3.0, gather()["gas_incrementor_config_max_queue_per_signer"])
+
+	g, err := NewConfigGauge(reg)
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, testutil.ToFloat64(g.gauges["max_queue_per_signer"]))
+}
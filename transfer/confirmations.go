@@ -0,0 +1,65 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultConfirmationPollInterval is how often the ConfirmationGate checks transaction confirmations.
+const DefaultConfirmationPollInterval = 2 * time.Second
+
+// ConfirmationGate allows waiting until a transaction is confirmed by enough blocks.
+type ConfirmationGate struct {
+	bc       MultichainClient
+	interval time.Duration
+}
+
+// NewConfirmationGate returns a new confirmation gate.
+func NewConfirmationGate(bc MultichainClient) *ConfirmationGate {
+	return &ConfirmationGate{
+		bc:       bc,
+		interval: DefaultConfirmationPollInterval,
+	}
+}
+
+// WaitForConfirmations blocks until the given transaction has at least
+// the required amount of confirmations or the context is done.
+func (g *ConfirmationGate) WaitForConfirmations(ctx context.Context, chainID int64, hash common.Hash, required uint64) error {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	for {
+		confirmations, err := g.bc.TransactionConfirmations(chainID, hash)
+		if err != nil {
+			return fmt.Errorf("failed to get transaction confirmations: %w", err)
+		}
+		if confirmations >= required {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
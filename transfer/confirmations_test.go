@@ -0,0 +1,180 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// confirmingClient gains one confirmation every time it is polled.
+type confirmingClient struct {
+	mockClient
+	polls uint64
+}
+
+func (c *confirmingClient) TransactionConfirmations(chainID int64, hash common.Hash) (uint64, error) {
+	c.polls++
+	return c.polls, nil
+}
+
+func TestConfirmationGate(t *testing.T) {
+	t.Run("returns once required confirmations are reached", func(t *testing.T) {
+		bc := &confirmingClient{}
+		gate := NewConfirmationGate(bc)
+		gate.interval = time.Millisecond
+
+		err := gate.WaitForConfirmations(context.Background(), 1, common.HexToHash("0x1"), 5)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(5), bc.polls)
+	})
+	t.Run("stops when context is cancelled", func(t *testing.T) {
+		gate := NewConfirmationGate(&confirmingClient{})
+		gate.interval = time.Millisecond
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+		defer cancel()
+
+		err := gate.WaitForConfirmations(ctx, 1, common.HexToHash("0x1"), 1000000)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
+
+// minedClient returns a successful receipt mined in block 10
+// and advances the chain head by a block on every poll if advancing.
+type minedClient struct {
+	mockClient
+	advancing bool
+	head      uint64
+	m         sync.Mutex
+}
+
+func (c *minedClient) TransactionReceipt(chainID int64, hash common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(10)}, nil
+}
+
+func (c *minedClient) BlockNumber(chainID int64) (uint64, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.head == 0 {
+		c.head = 10
+	} else if c.advancing {
+		c.head++
+	}
+	return c.head, nil
+}
+
+// reorgedClient never finds a receipt as the mined transaction was removed by a reorg.
+type reorgedClient struct {
+	mockClient
+}
+
+func (c *reorgedClient) TransactionByHash(chainID int64, hash common.Hash) (*types.Transaction, bool, error) {
+	return nil, true, nil
+}
+
+func (c *minedClient) currentHead() uint64 {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.head
+}
+
+func TestGasPriceIncrementor_RequiredConfirmations(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+	opts := defaultOpts()
+	opts.CheckInterval = 10 * time.Millisecond
+	opts.IncreaseInterval = 20 * time.Millisecond
+	opts.RequiredConfirmations = 3
+
+	t.Run("succeeds once 3 blocks are mined on top", func(t *testing.T) {
+		tx, err := newTransaction(org, sg.address, opts)
+		assert.NoError(t, err)
+
+		st := &mockStorage{}
+		bc := &minedClient{advancing: true}
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, bc, Signers{sg.address: sg.SignatureFunc})
+		defer inc.Stop()
+
+		assert.NoError(t, inc.watchAndIncrement(context.Background(), *tx))
+		assert.Equal(t, TxStateSucceed, st.tx.State)
+		assert.Equal(t, uint64(13), bc.currentHead())
+		assert.False(t, bc.sent, "mined transaction should not get a price increase")
+	})
+	t.Run("marks pending confirmation on timeout", func(t *testing.T) {
+		timeoutOpts := opts
+		timeoutOpts.Timeout = 100 * time.Millisecond
+		tx, err := newTransaction(org, sg.address, timeoutOpts)
+		assert.NoError(t, err)
+
+		st := &mockStorage{}
+		bc := &minedClient{}
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, bc, Signers{sg.address: sg.SignatureFunc})
+		defer inc.Stop()
+
+		assert.NoError(t, inc.watchAndIncrement(context.Background(), *tx))
+		assert.Equal(t, TxStatePendingConfirmation, st.tx.State)
+		assert.False(t, st.tx.State.IsTerminal())
+		assert.False(t, bc.sent)
+
+		// Once enough blocks are mined the rewatched transaction succeeds.
+		bc.advancing = true
+		assert.NoError(t, inc.watchAndIncrement(context.Background(), st.tx))
+		assert.Equal(t, TxStateSucceed, st.tx.State)
+	})
+	t.Run("pending confirmation fails after its confirmation deadline", func(t *testing.T) {
+		timeoutOpts := opts
+		timeoutOpts.Timeout = 100 * time.Millisecond
+		tx, err := newTransaction(org, sg.address, timeoutOpts)
+		assert.NoError(t, err)
+		tx.State = TxStatePendingConfirmation
+		tx.CreatedAt = time.Now().Add(-2 * timeoutOpts.Timeout)
+
+		st := &mockStorage{}
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, &minedClient{}, Signers{sg.address: sg.SignatureFunc})
+		defer inc.Stop()
+
+		start := time.Now()
+		assert.NoError(t, inc.watchAndIncrement(context.Background(), *tx))
+		assert.Equal(t, TxStateFailed, st.tx.State)
+		assert.True(t, time.Since(start) < timeoutOpts.Timeout, "rewatched transaction should not get a fresh timeout")
+	})
+	t.Run("reorged out transaction gets its gas price increased", func(t *testing.T) {
+		timeoutOpts := opts
+		timeoutOpts.Timeout = 50 * time.Millisecond
+		tx, err := newTransaction(org, sg.address, timeoutOpts)
+		assert.NoError(t, err)
+		tx.State = TxStatePendingConfirmation
+
+		st := &mockStorage{}
+		bc := &reorgedClient{mockClient: *newClient(nil)}
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, bc, Signers{sg.address: sg.SignatureFunc})
+		defer inc.Stop()
+
+		// Once bumped, the transaction is no longer mined and fails on its confirmation deadline.
+		assert.NoError(t, inc.watchAndIncrement(context.Background(), *tx))
+		assert.True(t, bc.sent)
+		assert.Equal(t, TxStateFailed, st.tx.State)
+	})
+}
@@ -0,0 +1,151 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// CostEntry holds the gas cost of a single finalized transaction.
+type CostEntry struct {
+	UniqueID      string
+	SenderAddress string
+	GasUsed       uint64
+	GasPrice      *big.Int
+	TotalCost     *big.Int
+	FinalizedAt   time.Time
+	Metadata      map[string]string
+	// Err is set if the cost couldn't be determined, costs are zero then.
+	Err error
+}
+
+// SenderCost holds the gas costs of a single sender.
+type SenderCost struct {
+	TransactionCount int
+	GasUsed          uint64
+	TotalCost        *big.Int
+}
+
+// CostSummary holds gas costs of finalized transactions aggregated by sender.
+type CostSummary struct {
+	ChainID          int64
+	From, To         time.Time
+	TransactionCount int
+	TotalCost        *big.Int
+	// UnknownCostCount is the amount of transactions whose cost couldn't be
+	// determined. They are counted in TransactionCount but not in the costs.
+	UnknownCostCount int
+	// PerSender is keyed by sender address hex.
+	PerSender map[string]SenderCost
+}
+
+// TransactionCostReport returns gas costs of all transactions of the
+// given chain which were finalized within `[from, to)` ordered by finalization time.
+//
+// Gas used is taken from the receipt of the latest transaction.
+// Transactions which were never mined have zero cost. If the receipt
+// can't be fetched the error is set on the entry instead.
+func (i *GasPriceIncremenetor) TransactionCostReport(from, to time.Time, chainID int64) ([]CostEntry, error) {
+	txs, err := i.storage.GetIncrementorFinalizedBefore(to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions from storage: %w", err)
+	}
+
+	entries := make([]CostEntry, 0, len(txs))
+	for _, tx := range txs {
+		if tx.ChainID != chainID || tx.FinalizedAt.Before(from) {
+			continue
+		}
+		entries = append(entries, i.costEntry(tx))
+	}
+
+	sort.SliceStable(entries, func(a, b int) bool {
+		return entries[a].FinalizedAt.Before(entries[b].FinalizedAt)
+	})
+	return entries, nil
+}
+
+// SummaryCostReport aggregates the TransactionCostReport by sender.
+func (i *GasPriceIncremenetor) SummaryCostReport(from, to time.Time, chainID int64) (CostSummary, error) {
+	entries, err := i.TransactionCostReport(from, to, chainID)
+	if err != nil {
+		return CostSummary{}, err
+	}
+
+	summary := CostSummary{
+		ChainID:   chainID,
+		From:      from,
+		To:        to,
+		TotalCost: big.NewInt(0),
+		PerSender: make(map[string]SenderCost),
+	}
+	for _, entry := range entries {
+		sender, ok := summary.PerSender[entry.SenderAddress]
+		if !ok {
+			sender.TotalCost = big.NewInt(0)
+		}
+		sender.TransactionCount++
+		sender.GasUsed += entry.GasUsed
+		sender.TotalCost.Add(sender.TotalCost, entry.TotalCost)
+		summary.PerSender[entry.SenderAddress] = sender
+
+		summary.TransactionCount++
+		summary.TotalCost.Add(summary.TotalCost, entry.TotalCost)
+		if entry.Err != nil {
+			summary.UnknownCostCount++
+		}
+	}
+
+	return summary, nil
+}
+
+func (i *GasPriceIncremenetor) costEntry(tx Transaction) CostEntry {
+	entry := CostEntry{
+		UniqueID:      tx.UniqueID,
+		SenderAddress: tx.SenderAddress().Hex(),
+		TotalCost:     big.NewInt(0),
+		FinalizedAt:   tx.FinalizedAt,
+		Metadata:      tx.Metadata,
+	}
+
+	latest, err := tx.getLatestTx()
+	if err != nil {
+		entry.Err = fmt.Errorf("malformed internal tx object: %w", err)
+		return entry
+	}
+	entry.GasPrice = latest.GasPrice()
+
+	receipt, pending, err := i.receipts.FetchReceipt(context.Background(), tx.ChainID, latest.Hash())
+	if errors.Is(err, ethereum.NotFound) || pending {
+		return entry
+	}
+	if err != nil {
+		entry.Err = fmt.Errorf("failed to get receipt: %w", err)
+		return entry
+	}
+
+	entry.GasUsed = receipt.GasUsed
+	entry.TotalCost.Mul(new(big.Int).SetUint64(receipt.GasUsed), entry.GasPrice)
+	return entry
+}
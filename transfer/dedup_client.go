@@ -0,0 +1,191 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/sync/singleflight"
+)
+
+// DeduplicatingClient wraps a MultichainClient collapsing identical
+// concurrent queries in to a single call to the wrapped client.
+//
+// Sending transactions is never deduplicated.
+type DeduplicatingClient struct {
+	bc    MultichainClient
+	group singleflight.Group
+
+	calls  uint64
+	misses uint64
+}
+
+// NewDeduplicatingClient returns a new deduplicating client.
+func NewDeduplicatingClient(bc MultichainClient) *DeduplicatingClient {
+	return &DeduplicatingClient{
+		bc: bc,
+	}
+}
+
+type txByHashResult struct {
+	tx      *types.Transaction
+	pending bool
+}
+
+// TransactionReceipt returns a transaction receipt.
+func (c *DeduplicatingClient) TransactionReceipt(chainID int64, hash common.Hash) (*types.Receipt, error) {
+	res, err := c.do("receipt", chainID, hash, func() (interface{}, error) {
+		return c.bc.TransactionReceipt(chainID, hash)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*types.Receipt), nil
+}
+
+// TransactionByHash returns a transaction by its hash and whether it's still pending.
+func (c *DeduplicatingClient) TransactionByHash(chainID int64, hash common.Hash) (*types.Transaction, bool, error) {
+	res, err := c.do("txByHash", chainID, hash, func() (interface{}, error) {
+		tx, pending, err := c.bc.TransactionByHash(chainID, hash)
+		return txByHashResult{tx: tx, pending: pending}, err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	r := res.(txByHashResult)
+	return r.tx, r.pending, nil
+}
+
+// TransactionConfirmations returns the amount of confirmations a transaction has.
+func (c *DeduplicatingClient) TransactionConfirmations(chainID int64, hash common.Hash) (uint64, error) {
+	res, err := c.do("confirmations", chainID, hash, func() (interface{}, error) {
+		return c.bc.TransactionConfirmations(chainID, hash)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.(uint64), nil
+}
+
+// BlockNumber returns the latest block number of the chain.
+func (c *DeduplicatingClient) BlockNumber(chainID int64) (uint64, error) {
+	res, err := c.do("blockNumber", chainID, common.Hash{}, func() (interface{}, error) {
+		return c.bc.BlockNumber(chainID)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.(uint64), nil
+}
+
+// NonceAt returns the confirmed nonce of the account at the latest block.
+func (c *DeduplicatingClient) NonceAt(chainID int64, account common.Address) (uint64, error) {
+	res, err := c.do("nonceAt", chainID, common.BytesToHash(account.Bytes()), func() (interface{}, error) {
+		return c.bc.NonceAt(chainID, account)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.(uint64), nil
+}
+
+// PendingNonceAt returns the nonce of the account in the pending state.
+func (c *DeduplicatingClient) PendingNonceAt(chainID int64, account common.Address) (uint64, error) {
+	res, err := c.do("pendingNonceAt", chainID, common.BytesToHash(account.Bytes()), func() (interface{}, error) {
+		return c.bc.PendingNonceAt(chainID, account)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.(uint64), nil
+}
+
+// HeaderByNumber returns the block header, if number is nil the latest header is returned.
+func (c *DeduplicatingClient) HeaderByNumber(chainID int64, number *big.Int) (*types.Header, error) {
+	name, key := "latestHeader", common.Hash{}
+	if number != nil {
+		name, key = "headerByNumber", common.BigToHash(number)
+	}
+
+	res, err := c.do(name, chainID, key, func() (interface{}, error) {
+		return c.bc.HeaderByNumber(chainID, number)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*types.Header), nil
+}
+
+// PendingTransactionCount returns the amount of transactions in the pending block.
+func (c *DeduplicatingClient) PendingTransactionCount(chainID int64) (uint, error) {
+	res, err := c.do("pendingTransactionCount", chainID, common.Hash{}, func() (interface{}, error) {
+		return c.bc.PendingTransactionCount(chainID)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.(uint), nil
+}
+
+// SuggestedGasPrice returns the gas price currently suggested by the chain's node.
+func (c *DeduplicatingClient) SuggestedGasPrice(chainID int64) (*big.Int, error) {
+	res, err := c.do("suggestedGasPrice", chainID, common.Hash{}, func() (interface{}, error) {
+		return c.bc.SuggestedGasPrice(chainID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*big.Int), nil
+}
+
+// SendTransaction sends a transaction using the wrapped client.
+func (c *DeduplicatingClient) SendTransaction(chainID int64, tx *types.Transaction) error {
+	return c.bc.SendTransaction(chainID, tx)
+}
+
+// DeduplicationStats returns the amount of calls which were served by
+// an already running query (hits) and the amount of calls which reached the wrapped client (misses).
+func (c *DeduplicatingClient) DeduplicationStats() (hits, misses uint64) {
+	misses = atomic.LoadUint64(&c.misses)
+	calls := atomic.LoadUint64(&c.calls)
+	return calls - misses, misses
+}
+
+func (c *DeduplicatingClient) do(method string, chainID int64, hash common.Hash, fn func() (interface{}, error)) (interface{}, error) {
+	atomic.AddUint64(&c.calls, 1)
+
+	key := fmt.Sprintf("%s|%d|%s", method, chainID, hash.Hex())
+	res, err, _ := c.group.Do(key, func() (interface{}, error) {
+		atomic.AddUint64(&c.misses, 1)
+		return fn()
+	})
+
+	return res, err
+}
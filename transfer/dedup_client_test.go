@@ -0,0 +1,74 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type blockingClient struct {
+	mockClient
+	release chan struct{}
+	byHash  uint64
+}
+
+func (c *blockingClient) TransactionByHash(chainID int64, hash common.Hash) (*types.Transaction, bool, error) {
+	atomic.AddUint64(&c.byHash, 1)
+	<-c.release
+	return nil, true, nil
+}
+
+func TestDeduplicatingClient(t *testing.T) {
+	bc := &blockingClient{release: make(chan struct{})}
+	c := NewDeduplicatingClient(bc)
+	hash := common.HexToHash("0x1")
+
+	var wg sync.WaitGroup
+	for n := 0; n < 100; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, pending, err := c.TransactionByHash(1, hash)
+			assert.NoError(t, err)
+			assert.True(t, pending)
+		}()
+	}
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadUint64(&c.calls) == 100
+	}, time.Second, time.Millisecond)
+	time.Sleep(time.Millisecond * 20)
+	close(bc.release)
+	wg.Wait()
+
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&bc.byHash))
+	hits, misses := c.DeduplicationStats()
+	assert.Equal(t, uint64(99), hits)
+	assert.Equal(t, uint64(1), misses)
+
+	// Different chain is a different query.
+	_, _, err := c.TransactionByHash(2, hash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&bc.byHash))
+}
@@ -0,0 +1,110 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const erc20TransferABIJSON = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}]`
+
+const erc20ApprovalABIJSON = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"spender","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Approval","type":"event"}]`
+
+// ERC20TransferABI holds the ERC-20 `Transfer` event definition.
+var ERC20TransferABI = mustParseABI(erc20TransferABIJSON)
+
+// ERC20ApprovalABI holds the ERC-20 `Approval` event definition.
+var ERC20ApprovalABI = mustParseABI(erc20ApprovalABIJSON)
+
+// DecodedEvent is a receipt log decoded using a matching event ABI.
+type DecodedEvent struct {
+	Name        string
+	Args        map[string]interface{}
+	Address     common.Address
+	BlockNumber uint64
+}
+
+// DecodeReceiptEvents decodes receipt logs using the given event ABIs.
+//
+// Logs which do not match any of the given events are skipped.
+func DecodeReceiptEvents(receipt *types.Receipt, eventABIs []abi.ABI) ([]DecodedEvent, error) {
+	decoded := make([]DecodedEvent, 0)
+	if receipt == nil {
+		return decoded, nil
+	}
+
+	for _, log := range receipt.Logs {
+		if log == nil || len(log.Topics) == 0 {
+			continue
+		}
+
+		event, ok := findEvent(log.Topics[0], eventABIs)
+		if !ok {
+			continue
+		}
+
+		args := make(map[string]interface{})
+		if err := event.Inputs.NonIndexed().UnpackIntoMap(args, log.Data); err != nil {
+			return nil, fmt.Errorf("failed to unpack %s event data: %w", event.Name, err)
+		}
+
+		var indexed abi.Arguments
+		for _, arg := range event.Inputs {
+			if arg.Indexed {
+				indexed = append(indexed, arg)
+			}
+		}
+		if err := abi.ParseTopicsIntoMap(args, indexed, log.Topics[1:]); err != nil {
+			return nil, fmt.Errorf("failed to parse %s event topics: %w", event.Name, err)
+		}
+
+		decoded = append(decoded, DecodedEvent{
+			Name:        event.Name,
+			Args:        args,
+			Address:     log.Address,
+			BlockNumber: log.BlockNumber,
+		})
+	}
+
+	return decoded, nil
+}
+
+func findEvent(id common.Hash, eventABIs []abi.ABI) (abi.Event, bool) {
+	for _, a := range eventABIs {
+		for _, event := range a.Events {
+			if event.ID == id {
+				return event, true
+			}
+		}
+	}
+
+	return abi.Event{}, false
+}
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+
+	return parsed
+}
@@ -0,0 +1,70 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeReceiptEvents(t *testing.T) {
+	token := common.HexToAddress("0x4Cf89ca06ad997bC732Dc876ed2A7F26a9E7f361")
+	from := common.HexToAddress("0x1")
+	to := common.HexToAddress("0x2")
+	value := big.NewInt(1000)
+
+	receipt := &types.Receipt{
+		Logs: []*types.Log{
+			{
+				Address: token,
+				Topics: []common.Hash{
+					ERC20TransferABI.Events["Transfer"].ID,
+					common.BytesToHash(from.Bytes()),
+					common.BytesToHash(to.Bytes()),
+				},
+				Data:        math.U256Bytes(new(big.Int).Set(value)),
+				BlockNumber: 10,
+			},
+			{
+				Address: token,
+				Topics:  []common.Hash{common.HexToHash("0x1234")},
+			},
+		},
+	}
+
+	events, err := DecodeReceiptEvents(receipt, []abi.ABI{ERC20TransferABI, ERC20ApprovalABI})
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	ev := events[0]
+	assert.Equal(t, "Transfer", ev.Name)
+	assert.Equal(t, token, ev.Address)
+	assert.Equal(t, uint64(10), ev.BlockNumber)
+	assert.Equal(t, from, ev.Args["from"])
+	assert.Equal(t, to, ev.Args["to"])
+	assert.Equal(t, value, ev.Args["value"])
+
+	events, err = DecodeReceiptEvents(receipt, []abi.ABI{ERC20ApprovalABI})
+	assert.NoError(t, err)
+	assert.Len(t, events, 0)
+}
@@ -0,0 +1,81 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// IncrementorVersion is the version of the pending transaction export format.
+const IncrementorVersion = "1"
+
+// PendingExport holds transactions that were being watched at the time of the export.
+type PendingExport struct {
+	ExportedAt         time.Time     `json:"exportedAt"`
+	IncrementorVersion string        `json:"incrementorVersion"`
+	Transactions       []Transaction `json:"transactions"`
+}
+
+// ExportPendingTransactions serializes all currently watched transactions to JSON.
+//
+// Transactions are exported in their latest state found in the storage.
+func (i *GasPriceIncremenetor) ExportPendingTransactions() ([]byte, error) {
+	stored, err := i.storage.GetIncrementorTransactionsToCheck(i.signers.getSigners())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions from storage: %w", err)
+	}
+	latest := make(map[string]Transaction, len(stored))
+	for _, tx := range stored {
+		latest[syncerKey(tx)] = tx
+	}
+
+	export := PendingExport{
+		ExportedAt:         time.Now().UTC(),
+		IncrementorVersion: IncrementorVersion,
+		Transactions:       make([]Transaction, 0),
+	}
+	i.syncer.forEach(func(tx Transaction, _ time.Time) {
+		if stored, ok := latest[syncerKey(tx)]; ok {
+			tx = stored
+		}
+		export.Transactions = append(export.Transactions, tx)
+	})
+
+	return json.Marshal(export)
+}
+
+// ImportAndRewatch starts watching all non finalized transactions of an export
+// created by ExportPendingTransactions.
+func (i *GasPriceIncremenetor) ImportAndRewatch(data []byte) error {
+	var export PendingExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return fmt.Errorf("failed to parse pending transaction export: %w", err)
+	}
+	if export.IncrementorVersion != IncrementorVersion {
+		return fmt.Errorf("unsupported export version %q, expected %q", export.IncrementorVersion, IncrementorVersion)
+	}
+
+	for _, tx := range export.Transactions {
+		if tx.State.IsTerminal() {
+			continue
+		}
+		i.tryWatch(tx)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// pendingClient keeps all transactions pending.
+type pendingClient struct {
+	mockClient
+}
+
+func (c *pendingClient) TransactionByHash(chainID int64, hash common.Hash) (*types.Transaction, bool, error) {
+	return nil, true, nil
+}
+
+func TestGasPriceIncrementor_ExportPendingTransactions(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+	opts := defaultOpts()
+	opts.IncreaseInterval = time.Hour
+
+	tx, err := newTransaction(org, sg.address, opts)
+	assert.NoError(t, err)
+
+	watchedIDs := func(inc *GasPriceIncremenetor) []string {
+		var ids []string
+		inc.ForEachWatched(func(tx Transaction, _ time.Time) {
+			ids = append(ids, tx.UniqueID)
+		})
+		return ids
+	}
+
+	st := &mockStorage{}
+	assert.NoError(t, st.UpsertIncrementorTransaction(*tx))
+	crashed := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+	crashed.tryWatch(*tx)
+	crashed.tryWatch(Transaction{UniqueID: "finalized", State: TxStateSucceed, Opts: opts})
+
+	data, err := crashed.ExportPendingTransactions()
+	assert.NoError(t, err)
+	crashed.Stop()
+
+	recovered := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+	defer recovered.Stop()
+	assert.NoError(t, recovered.ImportAndRewatch(data))
+	assert.Equal(t, []string{tx.UniqueID}, watchedIDs(recovered))
+
+	assert.Error(t, recovered.ImportAndRewatch([]byte(`{"incrementorVersion":"0"}`)))
+}
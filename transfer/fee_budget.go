@@ -0,0 +1,161 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// feeBudgetWindow is the sliding window fee budgets apply to.
+const feeBudgetWindow = time.Hour
+
+// FeeBudget caps the cost of inserted transactions within a sliding hour.
+//
+// The cost of a transaction is its initial gas price multiplied by its gas limit.
+// A nil limit is not enforced.
+type FeeBudget struct {
+	MaxPerSenderPerHour *big.Int
+	MaxTotalPerHour     *big.Int
+}
+
+// ErrFeeBudgetExceeded is returned if inserting a transaction would exceed the fee budget.
+// Used and Limit describe the budget which would be exceeded, either the sender or the total one.
+type ErrFeeBudgetExceeded struct {
+	Sender      string
+	Used, Limit *big.Int
+}
+
+func (e ErrFeeBudgetExceeded) Error() string {
+	return fmt.Sprintf("fee budget exceeded for sender %s: used %s of %s", e.Sender, e.Used, e.Limit)
+}
+
+// ErrFeeBudgetNotConfigured is returned when querying the fee budget without one configured.
+var ErrFeeBudgetNotConfigured = errors.New("fee budget is not configured")
+
+type feeSpend struct {
+	at     time.Time
+	sender common.Address
+	cost   *big.Int
+}
+
+// feeBudgetTracker keeps track of transaction costs within the fee budget window.
+type feeBudgetTracker struct {
+	budget *FeeBudget
+	spends []*feeSpend
+	now    func() time.Time
+	m      sync.Mutex
+}
+
+func newFeeBudgetTracker(budget *FeeBudget) *feeBudgetTracker {
+	return &feeBudgetTracker{
+		budget: budget,
+		now:    time.Now,
+	}
+}
+
+// reserve records the cost for the sender if it fits within the budget.
+// The returned func releases the reservation.
+func (f *feeBudgetTracker) reserve(sender common.Address, cost *big.Int) (func(), error) {
+	if f.budget == nil {
+		return func() {}, nil
+	}
+
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	senderUsed, totalUsed := f.used(sender)
+	if limit := f.budget.MaxPerSenderPerHour; limit != nil && new(big.Int).Add(senderUsed, cost).Cmp(limit) > 0 {
+		return nil, ErrFeeBudgetExceeded{Sender: sender.Hex(), Used: senderUsed, Limit: limit}
+	}
+	if limit := f.budget.MaxTotalPerHour; limit != nil && new(big.Int).Add(totalUsed, cost).Cmp(limit) > 0 {
+		return nil, ErrFeeBudgetExceeded{Sender: sender.Hex(), Used: totalUsed, Limit: limit}
+	}
+
+	spend := &feeSpend{at: f.now(), sender: sender, cost: cost}
+	f.spends = append(f.spends, spend)
+	return func() {
+		f.m.Lock()
+		defer f.m.Unlock()
+
+		for n, s := range f.spends {
+			if s == spend {
+				f.spends = append(f.spends[:n], f.spends[n+1:]...)
+				return
+			}
+		}
+	}, nil
+}
+
+// remaining returns the cost the sender is still allowed to spend.
+func (f *feeBudgetTracker) remaining(sender common.Address) (*big.Int, error) {
+	if f.budget == nil || (f.budget.MaxPerSenderPerHour == nil && f.budget.MaxTotalPerHour == nil) {
+		return nil, ErrFeeBudgetNotConfigured
+	}
+
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	senderUsed, totalUsed := f.used(sender)
+	var remaining *big.Int
+	if limit := f.budget.MaxPerSenderPerHour; limit != nil {
+		remaining = new(big.Int).Sub(limit, senderUsed)
+	}
+	if limit := f.budget.MaxTotalPerHour; limit != nil {
+		total := new(big.Int).Sub(limit, totalUsed)
+		if remaining == nil || total.Cmp(remaining) < 0 {
+			remaining = total
+		}
+	}
+
+	if remaining.Sign() < 0 {
+		remaining.SetInt64(0)
+	}
+	return remaining, nil
+}
+
+// used drops spends outside of the window and returns the cost used by the sender and in total.
+// Caller must hold the lock.
+func (f *feeBudgetTracker) used(sender common.Address) (*big.Int, *big.Int) {
+	cutoff := f.now().Add(-feeBudgetWindow)
+	kept := f.spends[:0]
+	for _, s := range f.spends {
+		if s.at.After(cutoff) {
+			kept = append(kept, s)
+		}
+	}
+	f.spends = kept
+
+	senderUsed, totalUsed := new(big.Int), new(big.Int)
+	for _, s := range f.spends {
+		totalUsed.Add(totalUsed, s.cost)
+		if s.sender == sender {
+			senderUsed.Add(senderUsed, s.cost)
+		}
+	}
+	return senderUsed, totalUsed
+}
+
+// FeeBudgetRemaining returns the transaction cost the sender is still allowed to insert within the hour.
+func (i *GasPriceIncremenetor) FeeBudgetRemaining(sender common.Address) (*big.Int, error) {
+	return i.feeBudget.remaining(sender)
+}
@@ -0,0 +1,84 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPriceIncrementor_FeeBudget(t *testing.T) {
+	sg := newSigner()
+	other := newSigner()
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{
+		FeeBudget: &FeeBudget{
+			MaxPerSenderPerHour: big.NewInt(250),
+			MaxTotalPerHour:     big.NewInt(300),
+		},
+	}, &mockStorage{}, newClient(nil), Signers{sg.address: sg.SignatureFunc, other.address: other.SignatureFunc})
+	now := time.Now()
+	inc.feeBudget.now = func() time.Time { return now }
+
+	insert := func(s *signer, nonce uint64) error {
+		// Each transaction costs 100.
+		tx := s.mustSign(types.NewTransaction(nonce, common.HexToAddress("0x1"), big.NewInt(1), 10, big.NewInt(10), nil), 137)
+		return inc.InsertInitial(tx, defaultOpts(), s.address)
+	}
+	remaining := func(s *signer) *big.Int {
+		res, err := inc.FeeBudgetRemaining(s.address)
+		assert.NoError(t, err)
+		return res
+	}
+
+	assert.NoError(t, insert(sg, 1))
+	assert.NoError(t, insert(sg, 2))
+	assert.Equal(t, big.NewInt(50), remaining(sg))
+
+	err := insert(sg, 3)
+	var exceeded ErrFeeBudgetExceeded
+	assert.True(t, errors.As(err, &exceeded))
+	assert.Equal(t, ErrFeeBudgetExceeded{Sender: sg.address.Hex(), Used: big.NewInt(200), Limit: big.NewInt(250)}, exceeded)
+
+	t.Run("total budget is shared by senders", func(t *testing.T) {
+		assert.Equal(t, big.NewInt(100), remaining(other))
+		assert.NoError(t, insert(other, 1))
+		assert.Zero(t, remaining(other).Sign())
+
+		err := insert(other, 2)
+		assert.True(t, errors.As(err, &exceeded))
+		assert.Equal(t, big.NewInt(300), exceeded.Limit)
+	})
+	t.Run("window rolls", func(t *testing.T) {
+		now = now.Add(time.Hour - time.Second)
+		assert.Error(t, insert(sg, 3))
+
+		now = now.Add(time.Second)
+		assert.Equal(t, big.NewInt(250), remaining(sg))
+		assert.NoError(t, insert(sg, 3))
+	})
+	t.Run("budget not configured", func(t *testing.T) {
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, &mockStorage{}, newClient(nil), Signers{})
+		_, err := inc.FeeBudgetRemaining(sg.address)
+		assert.True(t, errors.Is(err, ErrFeeBudgetNotConfigured))
+	})
+}
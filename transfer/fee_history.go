@@ -0,0 +1,143 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Default FeeHistoryConfig values.
+const (
+	DefaultFeeHistoryBlockCount        uint64 = 20
+	DefaultFeeHistoryTipPercentile            = 75.0
+	DefaultFeeHistoryBaseFeeMultiplier        = 1.0
+)
+
+// ErrEmptyFeeHistory is returned if the fee history holds no base fees.
+var ErrEmptyFeeHistory = errors.New("fee history is empty")
+
+// FeeHistory holds the result of an `eth_feeHistory` call.
+type FeeHistory struct {
+	OldestBlock  *big.Int
+	Reward       [][]*big.Int
+	BaseFee      []*big.Int
+	GasUsedRatio []float64
+}
+
+// FeeHistoryClient returns the fee history of a chain.
+type FeeHistoryClient interface {
+	FeeHistory(chainID int64, blockCount uint64, newestBlock *big.Int, rewardPercentiles []float64) (*FeeHistory, error)
+}
+
+// GasPriceOracle suggests a gas price for new transactions.
+type GasPriceOracle interface {
+	SuggestGasPrice(chainID int64) (*big.Int, error)
+}
+
+// FeeHistoryConfig controls FeeHistoryOracle behavior.
+type FeeHistoryConfig struct {
+	// BlockCount is the amount of latest blocks to consider.
+	BlockCount uint64
+	// TipPercentile is the priority fee percentile taken from each block.
+	TipPercentile float64
+	// BaseFeeMultiplier is applied to the median base fee to
+	// account for base fee growth until inclusion.
+	BaseFeeMultiplier float64
+}
+
+// FeeHistoryOracle suggests gas prices from the median base fee
+// of the latest blocks plus the median tip at the configured percentile.
+type FeeHistoryOracle struct {
+	cfg FeeHistoryConfig
+	cl  FeeHistoryClient
+}
+
+var _ GasPriceOracle = (*FeeHistoryOracle)(nil)
+
+// NewFeeHistoryOracle returns a new oracle, using defaults for zero config values.
+func NewFeeHistoryOracle(cfg FeeHistoryConfig, cl FeeHistoryClient) *FeeHistoryOracle {
+	if cfg.BlockCount == 0 {
+		cfg.BlockCount = DefaultFeeHistoryBlockCount
+	}
+	if cfg.TipPercentile <= 0 || cfg.TipPercentile > 100 {
+		cfg.TipPercentile = DefaultFeeHistoryTipPercentile
+	}
+	if cfg.BaseFeeMultiplier <= 0 {
+		cfg.BaseFeeMultiplier = DefaultFeeHistoryBaseFeeMultiplier
+	}
+
+	return &FeeHistoryOracle{
+		cfg: cfg,
+		cl:  cl,
+	}
+}
+
+// SuggestGasPrice returns the suggested gas price for the given chain.
+func (o *FeeHistoryOracle) SuggestGasPrice(chainID int64) (*big.Int, error) {
+	history, err := o.cl.FeeHistory(chainID, o.cfg.BlockCount, nil, []float64{o.cfg.TipPercentile})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee history: %w", err)
+	}
+	if history == nil || len(history.BaseFee) == 0 {
+		return nil, ErrEmptyFeeHistory
+	}
+
+	baseFee, _ := new(big.Float).Mul(
+		big.NewFloat(o.cfg.BaseFeeMultiplier),
+		new(big.Float).SetInt(median(history.BaseFee)),
+	).Int(nil)
+
+	tips := make([]*big.Int, 0, len(history.Reward))
+	for _, rewards := range history.Reward {
+		if len(rewards) > 0 && rewards[0] != nil {
+			tips = append(tips, rewards[0])
+		}
+	}
+	if len(tips) == 0 {
+		return baseFee, nil
+	}
+
+	return baseFee.Add(baseFee, median(tips)), nil
+}
+
+// median returns the median of the given non empty values.
+func median(values []*big.Int) *big.Int {
+	sorted := make([]*big.Int, 0, len(values))
+	for _, v := range values {
+		if v != nil {
+			sorted = append(sorted, v)
+		}
+	}
+	if len(sorted) == 0 {
+		return new(big.Int)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Cmp(sorted[j]) < 0
+	})
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return new(big.Int).Set(sorted[mid])
+	}
+
+	sum := new(big.Int).Add(sorted[mid-1], sorted[mid])
+	return sum.Div(sum, big.NewInt(2))
+}
@@ -0,0 +1,102 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type feeHistoryClient struct {
+	history *FeeHistory
+	err     error
+
+	blockCount  uint64
+	percentiles []float64
+}
+
+func (c *feeHistoryClient) FeeHistory(chainID int64, blockCount uint64, newestBlock *big.Int, rewardPercentiles []float64) (*FeeHistory, error) {
+	c.blockCount = blockCount
+	c.percentiles = rewardPercentiles
+	return c.history, c.err
+}
+
+func bigInts(values ...int64) []*big.Int {
+	res := make([]*big.Int, 0, len(values))
+	for _, v := range values {
+		res = append(res, big.NewInt(v))
+	}
+	return res
+}
+
+func TestFeeHistoryOracle_SuggestGasPrice(t *testing.T) {
+	t.Run("median base fee plus median tip", func(t *testing.T) {
+		cl := &feeHistoryClient{history: &FeeHistory{
+			BaseFee: bigInts(100, 300, 200, 1000, 150),
+			Reward:  [][]*big.Int{bigInts(5), bigInts(50), bigInts(10), bigInts(7)},
+		}}
+		oracle := NewFeeHistoryOracle(FeeHistoryConfig{}, cl)
+
+		price, err := oracle.SuggestGasPrice(137)
+		assert.NoError(t, err)
+		assert.Equal(t, "208", price.String())
+		assert.Equal(t, DefaultFeeHistoryBlockCount, cl.blockCount)
+		assert.Equal(t, []float64{DefaultFeeHistoryTipPercentile}, cl.percentiles)
+	})
+	t.Run("base fee multiplier keeps price within bounds", func(t *testing.T) {
+		baseFees := make([]int64, 0, 21)
+		for n := int64(0); n < 21; n++ {
+			baseFees = append(baseFees, 1000+n*10)
+		}
+		cl := &feeHistoryClient{history: &FeeHistory{
+			BaseFee: bigInts(baseFees...),
+			Reward:  [][]*big.Int{bigInts(20), bigInts(30), bigInts(25)},
+		}}
+		oracle := NewFeeHistoryOracle(FeeHistoryConfig{BlockCount: 20, TipPercentile: 50, BaseFeeMultiplier: 2}, cl)
+
+		price, err := oracle.SuggestGasPrice(137)
+		assert.NoError(t, err)
+		assert.True(t, price.Cmp(big.NewInt(2*1000+20)) >= 0, price.String())
+		assert.True(t, price.Cmp(big.NewInt(2*1200+30)) <= 0, price.String())
+		assert.Equal(t, []float64{50}, cl.percentiles)
+	})
+	t.Run("missing rewards use base fee only", func(t *testing.T) {
+		oracle := NewFeeHistoryOracle(FeeHistoryConfig{}, &feeHistoryClient{history: &FeeHistory{
+			BaseFee: bigInts(100, 200),
+		}})
+
+		price, err := oracle.SuggestGasPrice(137)
+		assert.NoError(t, err)
+		assert.Equal(t, "150", price.String())
+	})
+	t.Run("empty history", func(t *testing.T) {
+		oracle := NewFeeHistoryOracle(FeeHistoryConfig{}, &feeHistoryClient{history: &FeeHistory{}})
+
+		_, err := oracle.SuggestGasPrice(137)
+		assert.ErrorIs(t, err, ErrEmptyFeeHistory)
+	})
+	t.Run("client error", func(t *testing.T) {
+		failure := errors.New("boom")
+		oracle := NewFeeHistoryOracle(FeeHistoryConfig{}, &feeHistoryClient{err: failure})
+
+		_, err := oracle.SuggestGasPrice(137)
+		assert.ErrorIs(t, err, failure)
+	})
+}
@@ -0,0 +1,78 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// FilterWatched returns all currently watched transactions matching the predicate.
+//
+// Transactions are matched in their latest stored state. Should storage
+// fail, the state they were in when watching started is used instead.
+func (i *GasPriceIncremenetor) FilterWatched(predicate func(Transaction) bool) []Transaction {
+	watched := make(map[string]Transaction)
+	i.syncer.forEach(func(tx Transaction, startedAt time.Time) {
+		watched[tx.UniqueID] = tx
+	})
+	if len(watched) == 0 {
+		return []Transaction{}
+	}
+
+	stored, err := i.storage.GetIncrementorTransactionsToCheck(i.signers.getSigners())
+	if err != nil {
+		i.log(Transaction{}, fmt.Errorf("failed to get watched transactions, using cached state: %w", err))
+	}
+	for _, tx := range stored {
+		if _, ok := watched[tx.UniqueID]; ok {
+			watched[tx.UniqueID] = tx
+		}
+	}
+
+	res := make([]Transaction, 0)
+	for _, tx := range watched {
+		if predicate(tx) {
+			res = append(res, tx)
+		}
+	}
+	return res
+}
+
+// WithChainID matches transactions of the given chain.
+func WithChainID(id int64) func(Transaction) bool {
+	return func(tx Transaction) bool {
+		return tx.ChainID == id
+	}
+}
+
+// WithSender matches transactions sent by the given address.
+func WithSender(addr string) func(Transaction) bool {
+	sender := common.HexToAddress(addr)
+	return func(tx Transaction) bool {
+		return tx.SenderAddress() == sender
+	}
+}
+
+// OlderThan matches transactions created more than d ago.
+func OlderThan(d time.Duration) func(Transaction) bool {
+	return func(tx Transaction) bool {
+		return time.Since(tx.CreatedAt) > d
+	}
+}
@@ -0,0 +1,46 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPriceIncrementor_FilterWatched(t *testing.T) {
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, &mockStorage{}, newClient(nil), Signers{})
+	assert.Empty(t, inc.FilterWatched(WithChainID(137)))
+
+	now := time.Now().UTC()
+	inc.syncer.txMarkBeingWatched(Transaction{UniqueID: "eth", ChainID: 1, SenderAddressHex: "0x1", CreatedAt: now})
+	inc.syncer.txMarkBeingWatched(Transaction{UniqueID: "matic", ChainID: 137, SenderAddressHex: "0x1", CreatedAt: now})
+	inc.syncer.txMarkBeingWatched(Transaction{UniqueID: "matic-old", ChainID: 137, SenderAddressHex: "0x2", CreatedAt: now.Add(-time.Hour)})
+
+	ids := func(txs []Transaction) []string {
+		res := make([]string, 0, len(txs))
+		for _, tx := range txs {
+			res = append(res, tx.UniqueID)
+		}
+		return res
+	}
+
+	assert.ElementsMatch(t, []string{"matic", "matic-old"}, ids(inc.FilterWatched(WithChainID(137))))
+	assert.ElementsMatch(t, []string{"eth", "matic"}, ids(inc.FilterWatched(WithSender("0x0000000000000000000000000000000000000001"))))
+	assert.ElementsMatch(t, []string{"matic-old"}, ids(inc.FilterWatched(OlderThan(time.Minute))))
+}
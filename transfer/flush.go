@@ -0,0 +1,116 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSyncerFlushed is logged if a transaction is not watched because FlushSyncer was called.
+var ErrSyncerFlushed = errors.New("incrementor was flushed, not watching transactions anymore")
+
+// FlushSyncer prepares the incrementor for a handoff to another instance.
+//
+// New transactions are no longer watched, all running watchers are stopped
+// and the latest in memory state of the transactions they were watching is
+// stored. The incrementor does not watch transactions after it's flushed,
+// Stop should still be called to stop the Run loop.
+//
+// Returns the context error if watchers don't stop before it's done.
+func (i *GasPriceIncremenetor) FlushSyncer(ctx context.Context) error {
+	for _, done := range i.syncer.drain() {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return fmt.Errorf("failed to wait for watchers to stop: %w", ctx.Err())
+		}
+	}
+
+	for _, tx := range i.syncer.takeInterrupted() {
+		if err := i.upsert(tx); err != nil {
+			return fmt.Errorf("failed to store transaction %q: %w", tx.UniqueID, err)
+		}
+	}
+
+	i.syncer.markFlushed(time.Now().UTC())
+	return nil
+}
+
+// FlushedAt returns the time FlushSyncer completed.
+// Zero time is returned if it was not flushed.
+func (i *GasPriceIncremenetor) FlushedAt() time.Time {
+	return i.syncer.flushTime()
+}
+
+// drain stops new watches from being started and cancels all running watchers
+// returning channels closed once they have exited.
+func (s *syncer) drain() []<-chan struct{} {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.draining = true
+	dones := make([]<-chan struct{}, 0, len(s.watches))
+	for _, w := range s.watches {
+		w.cancel()
+		dones = append(dones, w.done)
+	}
+	return dones
+}
+
+func (s *syncer) isDraining() bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.draining
+}
+
+// txInterrupted records the latest state of a transaction
+// whose watcher was stopped while draining.
+func (s *syncer) txInterrupted(tx Transaction) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.draining {
+		s.interrupted[syncerKey(tx)] = tx
+	}
+}
+
+// takeInterrupted returns and forgets the transactions recorded by txInterrupted.
+func (s *syncer) takeInterrupted() []Transaction {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	txs := make([]Transaction, 0, len(s.interrupted))
+	for key, tx := range s.interrupted {
+		txs = append(txs, tx)
+		delete(s.interrupted, key)
+	}
+	return txs
+}
+
+func (s *syncer) markFlushed(at time.Time) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.flushedAt = at
+}
+
+func (s *syncer) flushTime() time.Time {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.flushedAt
+}
@@ -0,0 +1,136 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// mapStorage keeps the latest upserted state of every transaction.
+type mapStorage struct {
+	mockStorage
+	txs map[string]Transaction
+	m   sync.Mutex
+}
+
+func (s *mapStorage) UpsertIncrementorTransaction(tx Transaction) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.txs == nil {
+		s.txs = make(map[string]Transaction)
+	}
+	s.txs[tx.UniqueID] = tx
+	return nil
+}
+
+func (s *mapStorage) GetIncrementorTransactionsByUniqueIDs(uniqueIDs []string) (map[string]Transaction, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	res := make(map[string]Transaction)
+	for _, id := range uniqueIDs {
+		if tx, ok := s.txs[id]; ok {
+			res[id] = tx
+		}
+	}
+	return res, nil
+}
+
+func (s *mapStorage) get(uniqueID string) (Transaction, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	tx, ok := s.txs[uniqueID]
+	return tx, ok
+}
+
+func TestGasPriceIncrementor_FlushSyncer(t *testing.T) {
+	sg := newSigner()
+	opts := defaultOpts()
+	opts.IncreaseInterval = 20 * time.Millisecond
+	opts.MaxPrice = big.NewInt(1000000)
+
+	st := &mapStorage{}
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+	defer inc.Stop()
+
+	var flushedErrs int
+	var m sync.Mutex
+	inc.AttachLogFunc(func(tx Transaction, err error) {
+		if errors.Is(err, ErrSyncerFlushed) {
+			m.Lock()
+			defer m.Unlock()
+			flushedErrs++
+		}
+	})
+
+	var txs []Transaction
+	for nonce := uint64(1); nonce <= 3; nonce++ {
+		org := sg.mustSign(types.NewTransaction(nonce, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+		tx, err := newTransaction(org, sg.address, opts)
+		assert.NoError(t, err)
+		assert.NoError(t, st.UpsertIncrementorTransaction(*tx))
+		txs = append(txs, *tx)
+		inc.tryWatch(*tx)
+	}
+	assert.Equal(t, 3, inc.WatchedTxCount())
+
+	// Wait for every transaction to be bumped at least once.
+	assert.Eventually(t, func() bool {
+		for _, tx := range txs {
+			stored, _ := st.get(tx.UniqueID)
+			if stored.State != TxStatePriceIncreased {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 5*time.Millisecond)
+
+	assert.True(t, inc.FlushedAt().IsZero())
+	assert.NoError(t, inc.FlushSyncer(context.Background()))
+	assert.False(t, inc.FlushedAt().IsZero())
+	assert.Zero(t, inc.WatchedTxCount())
+
+	flushed := make(map[string]Transaction)
+	for _, tx := range txs {
+		stored, ok := st.get(tx.UniqueID)
+		assert.True(t, ok)
+		assert.Equal(t, TxStatePriceIncreased, stored.State)
+		flushed[tx.UniqueID] = stored
+	}
+
+	// Nothing changes after the flush and no new watches are started.
+	time.Sleep(3 * opts.IncreaseInterval)
+	for _, tx := range txs {
+		stored, _ := st.get(tx.UniqueID)
+		assert.Equal(t, flushed[tx.UniqueID].LatestTx, stored.LatestTx)
+	}
+
+	inc.tryWatch(txs[0])
+	inc.startWatching(txs[1])
+	assert.Zero(t, inc.WatchedTxCount())
+	m.Lock()
+	assert.Equal(t, 1, flushedErrs)
+	m.Unlock()
+}
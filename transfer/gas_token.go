@@ -0,0 +1,82 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// gasTokenBurnSelector is the selector of `burnGasToken(address)`.
+var gasTokenBurnSelector = crypto.Keccak256([]byte("burnGasToken(address)"))[:4]
+
+// GasTokenConfig configures burning of gas tokens (e.g. CHI, GST2)
+// when transactions are resubmitted.
+type GasTokenConfig struct {
+	// TokenAddress is the address of the gas token contract.
+	TokenAddress common.Address
+	// BurnGasToken prefixes the data of resubmitted transactions with a
+	// gas token burn instruction. The receiving contract must be a gas
+	// token aware wrapper which strips the prefix before execution.
+	BurnGasToken bool
+	// EstimateSavings returns the amount of gas saved by burning
+	// gas tokens for a transaction with the given gas limit.
+	EstimateSavings func(gasLimit uint64) uint64
+}
+
+// BurnPrefix returns the prefix added to the data of transactions burning gas tokens.
+func (c *GasTokenConfig) BurnPrefix() []byte {
+	return append(append([]byte{}, gasTokenBurnSelector...), common.LeftPadBytes(c.TokenAddress.Bytes(), 32)...)
+}
+
+// EffectiveGasPrice returns the total cost of a transaction with the
+// given gas price and limit after gas token savings are applied.
+func (c *GasTokenConfig) EffectiveGasPrice(gasPrice *big.Int, limit uint64) *big.Int {
+	var saved uint64
+	if c.BurnGasToken && c.EstimateSavings != nil {
+		saved = c.EstimateSavings(limit)
+	}
+	if saved > limit {
+		saved = limit
+	}
+
+	return new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(limit-saved))
+}
+
+// withBurnPrefix returns the data prefixed with a gas token burn instruction.
+// Data which already carries the prefix is returned unchanged.
+func (c *GasTokenConfig) withBurnPrefix(data []byte) []byte {
+	if c == nil || !c.BurnGasToken {
+		return data
+	}
+
+	prefix := c.BurnPrefix()
+	if bytes.HasPrefix(data, prefix) {
+		return data
+	}
+	return append(prefix, data...)
+}
+
+// rebuildTransaction rebuilds the transaction with a new gas price
+// applying gas token burning if configured.
+func (i *GasPriceIncremenetor) rebuildTransaction(tx Transaction, org *types.Transaction, newGasPrice *big.Int) *types.Transaction {
+	return tx.rebuiledWithData(org, newGasPrice, i.cfg.GasToken.withBurnPrefix(org.Data()))
+}
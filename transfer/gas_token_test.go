@@ -0,0 +1,91 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasTokenConfig(t *testing.T) {
+	cfg := &GasTokenConfig{
+		TokenAddress:    common.HexToAddress("0x0000000000004946c0e9F43F4Dee607b0eF1fA1c"),
+		BurnGasToken:    true,
+		EstimateSavings: func(gasLimit uint64) uint64 { return gasLimit / 4 },
+	}
+
+	t.Run("resubmitted transactions have the burn prefix", func(t *testing.T) {
+		sg := newSigner()
+		data := []byte{0xde, 0xad, 0xbe, 0xef}
+		org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 100000, big.NewInt(1), data), 137)
+		tx, err := newTransaction(org, sg.address, defaultOpts())
+		assert.NoError(t, err)
+
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{GasToken: cfg}, &mockStorage{}, newClient(nil), Signers{sg.address: sg.SignatureFunc})
+		newTx, err := inc.increaseGasPrice(*tx)
+		assert.NoError(t, err)
+
+		latest, err := newTx.getLatestTx()
+		assert.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(latest.Data(), cfg.BurnPrefix()))
+		assert.Equal(t, append(cfg.BurnPrefix(), data...), latest.Data())
+
+		again, err := inc.increaseGasPrice(newTx)
+		assert.NoError(t, err)
+		latest, err = again.getLatestTx()
+		assert.NoError(t, err)
+		assert.Equal(t, append(cfg.BurnPrefix(), data...), latest.Data(), "prefix should only be added once")
+	})
+	t.Run("sped up access list transactions keep their type with the burn prefix", func(t *testing.T) {
+		to := common.HexToAddress("0x1")
+		accessList := types.AccessList{{Address: to, StorageKeys: []common.Hash{common.HexToHash("0x2")}}}
+		data := []byte{0xde, 0xad, 0xbe, 0xef}
+		org := types.NewTx(&types.AccessListTx{
+			ChainID:    big.NewInt(137),
+			Nonce:      1,
+			GasPrice:   big.NewInt(10),
+			Gas:        100000,
+			To:         &to,
+			Value:      big.NewInt(1),
+			Data:       data,
+			AccessList: accessList,
+		})
+		opts := defaultOpts()
+		opts.SpeedUp = true
+		tx := Transaction{Opts: opts}
+
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{GasToken: cfg}, &mockStorage{}, newClient(nil), Signers{})
+		rebuilt := inc.rebuildTransaction(tx, org, big.NewInt(20))
+		assert.Equal(t, uint8(types.AccessListTxType), rebuilt.Type())
+		assert.Equal(t, accessList, rebuilt.AccessList())
+		assert.Equal(t, append(cfg.BurnPrefix(), data...), rebuilt.Data())
+		assert.Equal(t, big.NewInt(20), rebuilt.GasPrice())
+	})
+	t.Run("effective gas price includes savings", func(t *testing.T) {
+		price := big.NewInt(10)
+		assert.Equal(t, big.NewInt(750000), cfg.EffectiveGasPrice(price, 100000))
+		assert.True(t, cfg.EffectiveGasPrice(price, 100000).Cmp(new(big.Int).Mul(price, big.NewInt(100000))) < 0)
+
+		noBurn := &GasTokenConfig{EstimateSavings: cfg.EstimateSavings}
+		assert.Equal(t, big.NewInt(1000000), noBurn.EffectiveGasPrice(price, 100000))
+	})
+}
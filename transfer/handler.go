@@ -61,7 +61,7 @@ var ErrNoSigners = errors.New("failed to send a transaction, no signers for incr
 // GasPriceIncremenetorIface abstracts gas price incrementor.
 type GasPriceIncremenetorIface interface {
 	// InsertInitial inserts a new transaction to the queue.
-	InsertInitial(tx *types.Transaction, opts TransactionOpts, senderAddress common.Address) error
+	InsertInitial(tx *types.Transaction, opts TransactionOpts, senderAddress common.Address, metas ...TransactionMeta) error
 
 	// CanQueue returns true if another transaction can be queue in to the incrementor.
 	CanQueue(sender common.Address) (bool, error)
@@ -0,0 +1,121 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultHealthCheckConcurrency is the amount of transactions checked concurrently by HealthCheck.
+const DefaultHealthCheckConcurrency = 10
+
+// HealthCheckResult describes whether a watched transaction can still be submitted.
+type HealthCheckResult struct {
+	UniqueID string
+	Healthy  bool
+	Issues   []string
+}
+
+// HealthCheckResults holds health check results of all watched transactions.
+type HealthCheckResults []HealthCheckResult
+
+// OverallHealthy returns true if all transactions are healthy.
+func (r HealthCheckResults) OverallHealthy() bool {
+	for _, res := range r {
+		if !res.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// HealthCheck concurrently checks every watched transaction verifying that its
+// signer is available, its gas price can still be increased, its nonce was not
+// used yet and its chain is reachable.
+//
+// Results are ordered by transaction unique ID.
+func (i *GasPriceIncremenetor) HealthCheck(ctx context.Context) HealthCheckResults {
+	txs := i.FilterWatched(func(Transaction) bool { return true })
+
+	concurrency := i.cfg.HealthCheckConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultHealthCheckConcurrency
+	}
+
+	results := make(HealthCheckResults, len(txs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for n, tx := range txs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(n int, tx Transaction) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[n] = i.checkTxHealth(ctx, tx)
+		}(n, tx)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(a, b int) bool {
+		return results[a].UniqueID < results[b].UniqueID
+	})
+	return results
+}
+
+func (i *GasPriceIncremenetor) checkTxHealth(ctx context.Context, tx Transaction) HealthCheckResult {
+	issues := make([]string, 0)
+
+	if err := ctx.Err(); err != nil {
+		return HealthCheckResult{UniqueID: tx.UniqueID, Issues: []string{fmt.Sprintf("health check aborted: %v", err)}}
+	}
+
+	sender := tx.SenderAddress()
+	if _, ok := i.signers.getSignerFunc(tx.SenderAddressHex); !ok {
+		issues = append(issues, fmt.Sprintf("no signer for sender %s", sender.Hex()))
+	} else if i.health.isSuspended(sender) {
+		issues = append(issues, fmt.Sprintf("signer %s is suspended", sender.Hex()))
+	}
+
+	latest, err := tx.getLatestTx()
+	if err != nil {
+		issues = append(issues, fmt.Sprintf("malformed internal tx object: %v", err))
+		return HealthCheckResult{UniqueID: tx.UniqueID, Issues: issues}
+	}
+	if tx.Opts.MaxPrice != nil && latest.GasPrice().Cmp(tx.Opts.MaxPrice) >= 0 {
+		issues = append(issues, fmt.Sprintf("gas price %s reached max price %s", latest.GasPrice(), tx.Opts.MaxPrice))
+	}
+
+	if _, err := i.bc.BlockNumber(tx.ChainID); err != nil {
+		issues = append(issues, fmt.Sprintf("chain %d is unreachable: %v", tx.ChainID, err))
+	} else {
+		nonce, err := i.bc.NonceAt(tx.ChainID, sender)
+		if err != nil {
+			issues = append(issues, fmt.Sprintf("failed to get sender nonce: %v", err))
+		} else if nonce > latest.Nonce() {
+			issues = append(issues, fmt.Sprintf("nonce %d was already used, sender nonce is %d", latest.Nonce(), nonce))
+		}
+	}
+
+	return HealthCheckResult{
+		UniqueID: tx.UniqueID,
+		Healthy:  len(issues) == 0,
+		Issues:   issues,
+	}
+}
@@ -0,0 +1,100 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type healthClient struct {
+	pendingClient
+	blockErr error
+	nonce    uint64
+}
+
+func (c *healthClient) BlockNumber(chainID int64) (uint64, error) {
+	return 100, c.blockErr
+}
+
+func (c *healthClient) NonceAt(chainID int64, account common.Address) (uint64, error) {
+	return c.nonce, nil
+}
+
+func TestGasPriceIncrementor_HealthCheck(t *testing.T) {
+	sg := newSigner()
+	watch := func(cl MultichainClient, gasPrice int64) (*GasPriceIncremenetor, *Transaction) {
+		org := sg.mustSign(types.NewTransaction(5, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(gasPrice), []byte{}), 137)
+		opts := defaultOpts()
+		opts.IncreaseInterval = time.Hour
+		tx, err := newTransaction(org, sg.address, opts)
+		assert.NoError(t, err)
+
+		st := &mockStorage{}
+		assert.NoError(t, st.UpsertIncrementorTransaction(*tx))
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, cl, Signers{sg.address: sg.SignatureFunc})
+		inc.tryWatch(*tx)
+		return inc, tx
+	}
+
+	t.Run("healthy transaction", func(t *testing.T) {
+		inc, tx := watch(&healthClient{nonce: 5}, 1)
+		defer inc.Stop()
+
+		results := inc.HealthCheck(context.Background())
+		assert.Equal(t, HealthCheckResults{{UniqueID: tx.UniqueID, Healthy: true, Issues: []string{}}}, results)
+		assert.True(t, results.OverallHealthy())
+	})
+	t.Run("unreachable chain", func(t *testing.T) {
+		inc, tx := watch(&healthClient{blockErr: errors.New("connection refused")}, 1)
+		defer inc.Stop()
+
+		results := inc.HealthCheck(context.Background())
+		assert.Len(t, results, 1)
+		assert.Equal(t, tx.UniqueID, results[0].UniqueID)
+		assert.False(t, results[0].Healthy)
+		assert.Equal(t, []string{"chain 137 is unreachable: connection refused"}, results[0].Issues)
+		assert.False(t, results.OverallHealthy())
+	})
+	t.Run("used nonce and max price", func(t *testing.T) {
+		inc, _ := watch(&healthClient{nonce: 6}, 100)
+		defer inc.Stop()
+
+		results := inc.HealthCheck(context.Background())
+		assert.Len(t, results, 1)
+		assert.Equal(t, []string{
+			"gas price 100 reached max price 100",
+			"nonce 5 was already used, sender nonce is 6",
+		}, results[0].Issues)
+	})
+	t.Run("missing signer", func(t *testing.T) {
+		inc, _ := watch(&healthClient{nonce: 5}, 1)
+		defer inc.Stop()
+		inc.RemoveSigner(sg.address)
+
+		results := inc.HealthCheck(context.Background())
+		assert.Len(t, results, 1)
+		assert.Equal(t, []string{"no signer for sender " + sg.address.Hex()}, results[0].Issues)
+	})
+}
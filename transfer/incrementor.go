@@ -17,13 +17,17 @@
 package transfer
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -38,33 +42,285 @@ type GasPriceIncremenetor struct {
 	cfg     GasIncrementorConfig
 	signers safeSigners
 
-	syncer *syncer
-	logFn  LogFunc
-	stop   chan struct{}
-	once   sync.Once
+	syncer    *syncer
+	limiters  *signerLimiters
+	minPrices *chainMinGasPrices
+	chains    *chainSet
+	blockGas  *blockGasBudget
+	health    *SignerHealthChecker
+	nonces    *ChannelNonceLock
+	latency   *StorageLatencyMonitor
+	feeBudget *feeBudgetTracker
+	receipts  ReceiptFetcher
+	logFn     LogFunc
+	stop      chan struct{}
+	once      sync.Once
 }
 
 // GasIncrementorConfig is provided to the incrementor to configure it.
 type GasIncrementorConfig struct {
 	PullInterval      time.Duration
 	MaxQueuePerSigner int
+
+	// RateLimit limits how often `InsertInitial` can be called for a single sender.
+	RateLimit RateLimitConfig
+	// PerSignerLimiter overrides the RateLimit for individual senders.
+	// Keys are sender address hex strings.
+	PerSignerLimiter map[string]RateLimitConfig
+
+	// MinBumpPercent is the minimal gas price increase required by the mempool
+	// to accept a replacement transaction, e.g. 0.10 for 10%.
+	// If zero, DefaultMinBumpPercent is used.
+	MinBumpPercent float64
+
+	// ReceiptEventsFn is optional and is called with receipt events decoded
+	// using ReceiptEventABIs once a transaction succeeds.
+	ReceiptEventsFn  func(Transaction, []DecodedEvent)
+	ReceiptEventABIs []abi.ABI
+
+	// FinalizedFn is optional and is called once a transaction is stored in a terminal state.
+	// It's called in a new goroutine so it doesn't block the watcher. Returned errors
+	// are logged and panics are reported to the attached LogFunc with an ErrHookPanic.
+	FinalizedFn func(Transaction) error
+
+	// PostBumpHook is optional and is called after a gas price increase is stored.
+	// It's called in a new goroutine so it doesn't block the watcher. Panics are
+	// recovered and reported to the attached LogFunc with an ErrHookPanic.
+	PostBumpHook func(tx Transaction, oldGasPrice, newGasPrice *big.Int)
+
+	// ReceiptFetcher is optional and replaces the MultichainClient
+	// as the source of transaction receipts.
+	ReceiptFetcher ReceiptFetcher
+
+	// HealthCheckConcurrency limits the amount of transactions checked at once by HealthCheck.
+	// Defaults to DefaultHealthCheckConcurrency.
+	HealthCheckConcurrency int
+
+	// PriorityOrder makes the incrementor start watching transactions
+	// closest to their deadline first. Transactions expiring at a block
+	// have no deadline and are watched last in the order they were pulled.
+	PriorityOrder bool
+
+	// ArchiverConfig is optional and enables moving finalized transactions out of the storage.
+	ArchiverConfig ArchiverConfig
+
+	// MinGasPrice is the lowest gas price used when increasing gas price.
+	// It can be overridden per transaction by TransactionOpts.MinGasPrice.
+	MinGasPrice *big.Int
+	// ChainMinGasPriceRefreshInterval enables using the chain's suggested
+	// gas price as a floor as well, refreshing it at the given interval.
+	ChainMinGasPriceRefreshInterval time.Duration
+
+	// MaxGasPerBlock limits the total cost (gas limit * gas price) of transactions
+	// sent per chain within a single block period. Transactions exceeding it are
+	// delayed until the next block period. If nil, sending is not limited.
+	MaxGasPerBlock *big.Int
+	// BlockPeriod is the estimated block time. If zero, DefaultBlockPeriod is used.
+	BlockPeriod time.Duration
+
+	// WarmUpOnStart makes Run start watching pending transactions
+	// immediately instead of waiting for the first PullInterval.
+	WarmUpOnStart bool
+
+	// LockTTL is how long a transaction lock is held by a watcher.
+	// If zero, DefaultLockTTL is used.
+	LockTTL time.Duration
+
+	// UseAdaptiveBumping makes the gas price get increased only if the
+	// moving average of the market gas price exceeds the one of the
+	// transaction. The market price is sampled every IncreaseInterval.
+	UseAdaptiveBumping bool
+	// AdaptiveAlpha is the EWMA weight. If zero, DefaultEWMAAlpha is used.
+	AdaptiveAlpha float64
+	// AdaptiveBumpThreshold is the EWMA bump threshold. If zero, DefaultEWMABumpThreshold is used.
+	AdaptiveBumpThreshold float64
+
+	// GasToken optionally enables gas token burning for resubmitted transactions.
+	GasToken *GasTokenConfig
+
+	// SignerHealthCheckInterval enables periodic signer health checks.
+	// Signers failing to sign are suspended until the next check, see SuspendSigner.
+	SignerHealthCheckInterval time.Duration
+
+	// WarnPrice is the gas price above which every bump is reported
+	// to the attached LogFunc with an ErrGasPriceWarning.
+	WarnPrice *big.Int
+
+	// UnderpricedMaxRetries is how many times a resubmission rejected as
+	// underpriced is retried at a higher price. Zero disables retries.
+	UnderpricedMaxRetries int
+	// UnderpricedRetryDelay is how long to wait before retrying an underpriced resubmission.
+	UnderpricedRetryDelay time.Duration
+	// UnderpricedRetryMultiplier is applied to the gas price on every underpriced retry.
+	// If zero, DefaultUnderpricedRetryMultiplier is used.
+	UnderpricedRetryMultiplier float64
+
+	// StorageLatencyThreshold stops new transactions from being watched
+	// while the p99 storage write latency is above it. Zero disables it.
+	StorageLatencyThreshold time.Duration
+	// StorageLatencyWindow is how long write latencies are considered.
+	// If zero, DefaultStorageLatencyWindow is used.
+	StorageLatencyWindow time.Duration
+
+	// FeeBudget optionally caps the cost of transactions inserted per hour.
+	FeeBudget *FeeBudget
+
+	// ThrashingThreshold is the amount of bumps per minute above which a transaction
+	// is considered to be thrashing. Thrashing transactions are reported to the attached
+	// LogFunc with an ErrThrashingWarning and their increase interval is increased
+	// by 50% for the next two intervals. Zero disables the check.
+	ThrashingThreshold float64
+
+	// NonceSafetyCheckEnabled makes the incrementor skip gas price increases
+	// while the sender has pending transactions with higher nonces submitted
+	// outside of the incrementor. Nonces of transactions the incrementor
+	// watches for the sender are not considered, so it can be used together
+	// with MaxQueuePerSigner > 1. Skipped increases are reported to the
+	// attached LogFunc with an ErrUnsafeNonce.
+	NonceSafetyCheckEnabled bool
+
+	// AlertOnWatchDuration reports transactions watched for longer than it
+	// to the attached LogFunc with an ErrLongRunningWatch, once per watch.
+	// Zero disables it.
+	AlertOnWatchDuration time.Duration
 }
 
+// Validate returns an error if the config can't be used by the incrementor.
+func (cfg GasIncrementorConfig) Validate() error {
+	if cfg.PullInterval <= 0 {
+		return errors.New("pull interval must be provided")
+	}
+	if cfg.MaxQueuePerSigner <= 0 {
+		return errors.New("max queue per signer must be more than 0")
+	}
+	if cfg.RateLimit.MaxInsertsPerSecond < 0 || cfg.RateLimit.Burst < 0 {
+		return errors.New("rate limit values must be positive")
+	}
+	if cfg.MinBumpPercent < 0 {
+		return errors.New("min bump percent must be positive")
+	}
+	if cfg.AdaptiveAlpha < 0 || cfg.AdaptiveAlpha > 1 {
+		return errors.New("adaptive alpha must be between 0 and 1")
+	}
+	if cfg.BlockPeriod < 0 || cfg.LockTTL < 0 {
+		return errors.New("block period and lock ttl values must be positive")
+	}
+	if cfg.HealthCheckConcurrency < 0 {
+		return errors.New("health check concurrency must be positive")
+	}
+	if cfg.ThrashingThreshold < 0 {
+		return errors.New("thrashing threshold must be positive")
+	}
+	if cfg.AlertOnWatchDuration < 0 {
+		return errors.New("watch duration alert threshold must be positive")
+	}
+
+	return nil
+}
+
+// ErrTimeoutWarning is logged when a transaction is about to time out, see TransactionOpts.WarnBeforeTimeout.
+type ErrTimeoutWarning struct {
+	UniqueID  string
+	Remaining time.Duration
+}
+
+func (e ErrTimeoutWarning) Error() string {
+	return fmt.Sprintf("transaction %q will time out in %s", e.UniqueID, e.Remaining)
+}
+
+// ErrHookPanic is logged when a configured hook panics.
+type ErrHookPanic struct {
+	UniqueID string
+	Hook     string
+	Value    interface{}
+}
+
+func (e ErrHookPanic) Error() string {
+	return fmt.Sprintf("%s panicked for transaction %q: %v", e.Hook, e.UniqueID, e.Value)
+}
+
+// ErrGasPriceWarning is logged when a transaction is bumped above the configured WarnPrice.
+type ErrGasPriceWarning struct {
+	Price     *big.Int
+	WarnPrice *big.Int
+	MaxPrice  *big.Int
+}
+
+func (e ErrGasPriceWarning) Error() string {
+	return fmt.Sprintf("gas price %s is above warning price %s, max price is %s", e.Price, e.WarnPrice, e.MaxPrice)
+}
+
+// DefaultLockTTL is the default duration of a transaction lock.
+const DefaultLockTTL = 10 * time.Minute
+
+// DefaultMinBumpPercent is the minimal gas price bump accepted by most ethereum clients.
+const DefaultMinBumpPercent = 0.10
+
 // Storage is given to the Incremeter to be used to
 // insert, update or get transactions.
 type Storage interface {
 	// UpsertIncrementorTransaction is called to upsert a transaction.
 	// It either inserts a new entry or updates existing entries.
+	//
+	// Transaction metadata should be persisted as is, including keys with empty values.
 	UpsertIncrementorTransaction(tx Transaction) error
 
+	// InsertIncrementorTransactionIfAbsent atomically inserts a new transaction
+	// unless a transaction with the same idempotency key already exists.
+	//
+	// Returns false if the transaction was not inserted.
+	InsertIncrementorTransactionIfAbsent(tx Transaction, idempotencyKey string) (inserted bool, err error)
+
 	// GetIncrementorTransactionsToCheck returns all transaction that need to rechecked.
 	//
 	// Entries should be filtered by possible signers. If incrementor cannot sign the transaction
 	// it should not received it.
+	//
+	// Signers are checksummed hex while transactions store lowercase hex, see
+	// Transaction.SetSenderAddress. Addresses must be compared case insensitively,
+	// e.g. by parsing them with common.HexToAddress.
 	GetIncrementorTransactionsToCheck(possibleSigners []string) (tx []Transaction, err error)
 
 	// GasIncrementorSenderQueue returns the length of a queue for a single sender.
+	//
+	// Sender must be compared case insensitively, same as in GetIncrementorTransactionsToCheck.
 	GetIncrementorSenderQueue(sender string) (length int, err error)
+
+	// GetIncrementorTransactionsByTimeRange returns all transactions for the given chain
+	// which were created within the `[from, to)` time range.
+	//
+	// If any states are given, only transactions in one of those states should be returned.
+	GetIncrementorTransactionsByTimeRange(from, to time.Time, chainID int64, states ...TransactionState) ([]Transaction, error)
+
+	// GetIncrementorFinalizedBefore returns all finalized transactions
+	// which were finalized before the given time.
+	GetIncrementorFinalizedBefore(before time.Time) ([]Transaction, error)
+
+	// GetIncrementorTransactionsByUniqueIDs returns the latest stored state of the
+	// transactions with the given unique IDs keyed by unique ID.
+	//
+	// Transactions which are not found should be missing from the result.
+	GetIncrementorTransactionsByUniqueIDs(uniqueIDs []string) (map[string]Transaction, error)
+
+	// DeleteIncrementorTransactions removes transactions with the given unique IDs.
+	DeleteIncrementorTransactions(uniqueIDs []string) error
+
+	// LockTransaction acquires an exclusive lock on the transaction for the ttl duration.
+	// It should block until the lock is acquired or the context is done.
+	LockTransaction(ctx context.Context, uniqueID string, ttl time.Duration) (TransactionLock, error)
+}
+
+// ErrLockLost is returned when refreshing a transaction lock which expired
+// and might have been acquired by someone else.
+var ErrLockLost = errors.New("transaction lock was lost")
+
+// TransactionLock is an exclusive lock acquired by Storage.LockTransaction.
+type TransactionLock interface {
+	// Refresh extends the lock to expire ttl from now.
+	// ErrLockLost is returned if the lock is no longer held.
+	Refresh(ttl time.Duration) error
+	// Unlock releases the lock if it's still held.
+	Unlock() error
 }
 
 // MultichainClient handles calls to BC.
@@ -72,13 +328,34 @@ type MultichainClient interface {
 	TransactionReceipt(chainID int64, hash common.Hash) (*types.Receipt, error)
 	SendTransaction(chainID int64, tx *types.Transaction) error
 	TransactionByHash(chainID int64, hash common.Hash) (*types.Transaction, bool, error)
+	// TransactionConfirmations returns the amount of blocks mined on top of the block the transaction is included in.
+	TransactionConfirmations(chainID int64, hash common.Hash) (uint64, error)
+	// BlockNumber returns the latest block number of the chain.
+	BlockNumber(chainID int64) (uint64, error)
+	// SuggestedGasPrice returns the gas price currently suggested by the chain's node.
+	SuggestedGasPrice(chainID int64) (*big.Int, error)
+	// NonceAt returns the confirmed nonce of the account at the latest block.
+	NonceAt(chainID int64, account common.Address) (uint64, error)
+	// PendingNonceAt returns the nonce of the account in the pending state.
+	PendingNonceAt(chainID int64, account common.Address) (uint64, error)
+	// HeaderByNumber returns the block header, if number is nil the latest header is returned.
+	HeaderByNumber(chainID int64, number *big.Int) (*types.Header, error)
+	// PendingTransactionCount returns the amount of transactions in the pending block.
+	PendingTransactionCount(chainID int64) (uint, error)
 }
 
 // LogFunc can be attacheched to Incrementer to enable logging.
+// The given transaction carries its Metadata which can be used to enrich the log entry.
 type LogFunc func(Transaction, error)
 
 // NewGasPriceIncremenetor returns a new incrementer instance.
 func NewGasPriceIncremenetor(cfg GasIncrementorConfig, storage Storage, cl MultichainClient, signers Signers) *GasPriceIncremenetor {
+	stop := make(chan struct{}, 0)
+	receipts := cfg.ReceiptFetcher
+	if receipts == nil {
+		receipts = NewDefaultReceiptFetcher(cl)
+	}
+
 	return &GasPriceIncremenetor{
 		storage: storage,
 		bc:      cl,
@@ -88,8 +365,17 @@ func NewGasPriceIncremenetor(cfg GasIncrementorConfig, storage Storage, cl Multi
 			signers: signers,
 		},
 
-		syncer: newSyncer(),
-		stop:   make(chan struct{}, 0),
+		syncer:    newSyncer(),
+		limiters:  newSignerLimiters(cfg.RateLimit, cfg.PerSignerLimiter),
+		minPrices: newChainMinGasPrices(cl, cfg.ChainMinGasPriceRefreshInterval),
+		chains:    newChainSet(),
+		blockGas:  newBlockGasBudget(cfg.MaxGasPerBlock, cfg.BlockPeriod),
+		health:    newSignerHealthChecker(cfg.SignerHealthCheckInterval),
+		stop:      stop,
+		nonces:    NewChannelNonceLock(stop),
+		latency:   newStorageLatencyMonitor(cfg.StorageLatencyWindow),
+		feeBudget: newFeeBudgetTracker(cfg.FeeBudget),
+		receipts:  receipts,
 	}
 }
 
@@ -98,32 +384,107 @@ func NewGasPriceIncremenetor(cfg GasIncrementorConfig, storage Storage, cl Multi
 // It will query the given storage for any entries that it needs to check
 // for gas increase, trying to check their status.
 func (i *GasPriceIncremenetor) Run() {
-	process := func(txs []Transaction) {
-		for _, tx := range txs {
-			switch tx.State {
-			case TxStateFailed, TxStateSucceed:
-				// Force skip transactions that are finalized.
-			default:
-				i.tryWatch(tx)
-			}
+	if i.cfg.WarmUpOnStart {
+		if err := i.WarmUp(context.Background()); err != nil {
+			i.log(Transaction{}, err)
 		}
 	}
 
+	var archive <-chan time.Time
+	if i.cfg.ArchiverConfig.enabled() {
+		ticker := time.NewTicker(archiveInterval)
+		defer ticker.Stop()
+		archive = ticker.C
+	}
+
+	var healthCheck <-chan time.Time
+	if i.cfg.SignerHealthCheckInterval > 0 {
+		i.CheckSigners()
+		ticker := time.NewTicker(i.cfg.SignerHealthCheckInterval)
+		defer ticker.Stop()
+		healthCheck = ticker.C
+	}
+
+	// Pull ticker is created once so other cases firing don't postpone pulls.
+	pullInterval := i.cfg.PullInterval
+	if pullInterval <= 0 {
+		pullInterval = time.Millisecond
+	}
+	pull := time.NewTicker(pullInterval)
+	defer pull.Stop()
+
 	for {
 		select {
 		case <-i.stop:
 			return
 
-		case <-time.After(i.cfg.PullInterval):
+		case <-archive:
+			if err := i.ArchiveFinalized(); err != nil {
+				i.log(Transaction{}, err)
+			}
+
+		case <-healthCheck:
+			i.CheckSigners()
+
+		case <-pull.C:
 			txs, err := i.storage.GetIncrementorTransactionsToCheck(i.signers.getSigners())
 			if err != nil {
 				continue
 			}
-			process(txs)
+			i.process(txs, i.tryWatch)
 		}
 	}
 }
 
+// WarmUp fetches all pending transactions from storage and starts watching them.
+func (i *GasPriceIncremenetor) WarmUp(ctx context.Context) error {
+	txs, err := i.storage.GetIncrementorTransactionsToCheck(i.signers.getSigners())
+	if err != nil {
+		return fmt.Errorf("failed to warm up: %w", err)
+	}
+
+	i.process(txs, func(tx Transaction) {
+		if ctx.Err() != nil {
+			return
+		}
+		i.tryWatch(tx)
+	})
+	return ctx.Err()
+}
+
+// deadlineBefore returns true if transaction a has to be confirmed before b.
+// Transactions with no deadline are ordered after the ones having one.
+func deadlineBefore(a, b Transaction) bool {
+	da, db := a.Deadline(), b.Deadline()
+	if da.IsZero() || db.IsZero() {
+		return !da.IsZero() && db.IsZero()
+	}
+	return da.Before(db)
+}
+
+// process passes all non finalized transactions to the given watch func.
+func (i *GasPriceIncremenetor) process(txs []Transaction, watch func(Transaction)) {
+	if i.cfg.PriorityOrder {
+		sort.SliceStable(txs, func(a, b int) bool {
+			return deadlineBefore(txs[a], txs[b])
+		})
+	}
+
+	for _, tx := range txs {
+		if tx.State.IsTerminal() {
+			// Force skip transactions that are finalized.
+			continue
+		}
+		if !i.chains.handles(tx.ChainID) {
+			continue
+		}
+		if i.health.isSuspended(tx.SenderAddress()) {
+			continue
+		}
+		watch(tx)
+	}
+}
+
 // AttachLogFunc attaches a new log func incremeter thread.
 // The given log func is called every time the running incrementer thread
 // created by the `Run` method encouters an error.
@@ -140,19 +501,73 @@ func (i *GasPriceIncremenetor) Stop() {
 	})
 }
 
+// AddSigner adds or replaces the signer of the given address.
+func (i *GasPriceIncremenetor) AddSigner(addr common.Address, signer SignatureFunc) {
+	i.signers.add(addr, signer)
+}
+
+// RemoveSigner removes the signer of the given address.
+// Transactions of the signer are no longer picked up.
+func (i *GasPriceIncremenetor) RemoveSigner(addr common.Address) {
+	i.signers.remove(addr)
+}
+
+// IdempotencyKey returns a stable key identifying the given transaction of a sender.
+func IdempotencyKey(tx *types.Transaction, senderAddress common.Address) string {
+	return fmt.Sprintf("%s|%d|%s", tx.Hash().Hex(), tx.ChainId().Int64(), senderAddress.Hex())
+}
+
 // InsertInitial uses the given storage to insert an new transaction which
 // will later be retreived using `GetTransactionsToCheck` in order to check
 // it's state and retry with higher gas price if needed.
-func (i *GasPriceIncremenetor) InsertInitial(tx *types.Transaction, opts TransactionOpts, senderAddress common.Address) error {
+//
+// The given transaction must be signed by the sender for the chain it's meant for,
+// which is TransactionOpts.ChainID if given.
+// Inserting the same transaction multiple times is a no-op.
+// Optional metadata can be given which will be stored alongside the transaction.
+//
+// ErrRateLimitExceeded is returned if the sender is inserting transactions
+// faster than allowed by the configured rate limit. ErrChainNotHandled is
+// returned if the chain is not handled, see AddChain.
+func (i *GasPriceIncremenetor) InsertInitial(tx *types.Transaction, opts TransactionOpts, senderAddress common.Address, metas ...TransactionMeta) error {
 	if err := opts.validate(); err != nil {
 		return fmt.Errorf("invalid opts given: %w", err)
 	}
-	newTx, err := newTransaction(tx, senderAddress, opts)
+	if !i.limiters.allow(senderAddress) {
+		return ErrRateLimitExceeded
+	}
+	chainID := opts.ChainID
+	if chainID == 0 {
+		chainID = tx.ChainId().Int64()
+	}
+	if err := verifySignedTx(tx, chainID, senderAddress); err != nil {
+		return fmt.Errorf("invalid transaction given: %w", err)
+	}
+
+	// Chain can't be removed until the transaction is inserted.
+	i.chains.m.RLock()
+	defer i.chains.m.RUnlock()
+	if !i.chains.handlesLocked(chainID) {
+		return fmt.Errorf("chain %d: %w", chainID, ErrChainNotHandled)
+	}
+
+	newTx, err := newTransaction(tx, senderAddress, opts, metas...)
 	if err != nil {
 		return fmt.Errorf("failed to create new transaction: %w", err)
 	}
 
-	return i.storage.UpsertIncrementorTransaction(*newTx)
+	release, err := i.feeBudget.reserve(senderAddress, new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas())))
+	if err != nil {
+		return err
+	}
+
+	// If the transaction was already inserted by another call, it's
+	// not inserted again and the insert is treated as a success.
+	inserted, err := i.storage.InsertIncrementorTransactionIfAbsent(*newTx, IdempotencyKey(tx, senderAddress))
+	if err != nil || !inserted {
+		release()
+	}
+	return err
 }
 
 // CanSign returns if incrementor is able to sign transactions for the given sender.
@@ -171,9 +586,32 @@ func (i *GasPriceIncremenetor) CanQueue(sender common.Address) (bool, error) {
 	return length < i.cfg.MaxQueuePerSigner, nil
 }
 
+// WatchedByChain returns the amount of transactions currently being watched per chain.
+func (i *GasPriceIncremenetor) WatchedByChain() map[int64]int {
+	return i.syncer.watchedByChain()
+}
+
+// WatchedTxCount returns the amount of transactions currently being watched.
+func (i *GasPriceIncremenetor) WatchedTxCount() int {
+	return i.syncer.count()
+}
+
+// ForEachWatched calls fn for every transaction currently being watched
+// with the time watching started. Transactions are passed in the state
+// they were in when watching started.
+//
+// Watching is blocked while iterating, so fn should return quickly and
+// must not call any GasPriceIncremenetor methods as that would deadlock.
+func (i *GasPriceIncremenetor) ForEachWatched(fn func(tx Transaction, startedAt time.Time)) {
+	i.syncer.forEach(fn)
+}
+
 // tryWatch will try to watch a transaction.
 // If a transaction is already being watched, it will get skipped.
 func (i *GasPriceIncremenetor) tryWatch(tx Transaction) {
+	if i.syncer.isDraining() {
+		return
+	}
 	if i.syncer.txBeingWatched(tx) {
 		// Already watching
 		return
@@ -182,39 +620,168 @@ func (i *GasPriceIncremenetor) tryWatch(tx Transaction) {
 		i.log(tx, fmt.Errorf("can't increment gas price, got wrong tx opts: %w", err))
 		return
 	}
+	if err := i.storageBackpressure(); err != nil {
+		i.log(tx, err)
+		return
+	}
 
-	i.syncer.txMarkBeingWatched(tx)
+	i.startWatching(tx)
+}
+
+// startWatching starts a watcher goroutine for the transaction
+// which can be stopped using syncer.txStopWatch.
+func (i *GasPriceIncremenetor) startWatching(tx Transaction) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &watch{cancel: cancel, done: make(chan struct{})}
+	if !i.syncer.txStartWatch(tx, w) {
+		cancel()
+		i.log(tx, ErrSyncerFlushed)
+		return
+	}
 	go func() {
-		defer i.syncer.txRemoveWatched(tx)
-		if err := i.watchAndIncrement(tx); err != nil {
-			i.log(tx, err)
+		defer close(w.done)
+		defer cancel()
+		defer i.syncer.txWatchDone(tx, w)
+
+		// Waiting for the lock and watching is stopped by Stop as well.
+		go func() {
+			select {
+			case <-i.stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		// Other incrementor instances sharing the storage might be
+		// handling the same transaction, wait until they're done.
+		lock, err := i.storage.LockTransaction(ctx, tx.UniqueID, i.lockTTL())
+		if err != nil {
+			if ctx.Err() == nil {
+				i.log(tx, fmt.Errorf("failed to lock transaction: %w", err))
+			}
+			return
+		}
+		defer func() {
+			if err := lock.Unlock(); err != nil {
+				i.log(tx, fmt.Errorf("failed to unlock transaction: %w", err))
+			}
+		}()
+		go i.refreshLock(ctx, cancel, tx, lock)
 
-			if !tx.isExpired() {
+		// The transaction might have been bumped or finalized
+		// by the previous lock holder while waiting.
+		latest, ok := i.reloadLocked(tx)
+		if !ok {
+			return
+		}
+
+		if err := i.watchAndIncrement(ctx, latest); err != nil {
+			i.log(latest, err)
+
+			if !latest.isExpired() {
 				return
 			}
 
-			if err := i.transactionFailed(tx); err != nil {
-				i.log(tx, err)
+			if err := i.transactionFailed(latest); err != nil {
+				i.log(latest, err)
 			}
 		}
 
 	}()
 }
 
-func (i *GasPriceIncremenetor) watchAndIncrement(tx Transaction) error {
-	timeout := time.After(tx.Opts.Timeout)
+// refreshLock keeps the transaction lock from expiring until the context is done.
+// If the lock is lost, the watch is cancelled as someone else might be handling the transaction.
+func (i *GasPriceIncremenetor) refreshLock(ctx context.Context, cancel context.CancelFunc, tx Transaction, lock TransactionLock) {
+	ttl := i.lockTTL()
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := lock.Refresh(ttl)
+			if errors.Is(err, ErrLockLost) {
+				i.log(tx, fmt.Errorf("stopped watching transaction: %w", err))
+				cancel()
+				return
+			}
+			if err != nil {
+				i.log(tx, fmt.Errorf("failed to refresh transaction lock: %w", err))
+			}
+		}
+	}
+}
+
+// reloadLocked returns the latest stored state of a locked transaction.
+// False is returned if the transaction is finalized and should not be watched.
+//
+// The given transaction is returned as is if it's not found in the storage.
+func (i *GasPriceIncremenetor) reloadLocked(tx Transaction) (Transaction, bool) {
+	stored, err := i.storage.GetIncrementorTransactionsByUniqueIDs([]string{tx.UniqueID})
+	if err != nil {
+		i.log(tx, fmt.Errorf("failed to reload locked transaction: %w", err))
+		return tx, false
+	}
+
+	latest, ok := stored[tx.UniqueID]
+	if !ok {
+		return tx, true
+	}
+	return latest, !latest.State.IsTerminal()
+}
+
+func (i *GasPriceIncremenetor) watchAndIncrement(ctx context.Context, tx Transaction) error {
+	// If the transaction expires at a block, it's checked on every
+	// check tick instead and the timeout channel is never triggered.
+	// Transactions pending confirmation are only watched until their
+	// confirmation deadline, so they never get a fresh timeout.
+	var timeout, timeoutWarning <-chan time.Time
+	if tx.Opts.ExpiryBlock == nil {
+		if tx.State == TxStatePendingConfirmation {
+			timeout = time.After(time.Until(tx.confirmationDeadline()))
+		} else {
+			timeout = time.After(tx.Opts.Timeout)
+			if tx.Opts.WarnBeforeTimeout > 0 {
+				timeoutWarning = time.After(tx.Opts.Timeout - tx.Opts.WarnBeforeTimeout)
+			}
+		}
+	}
 	incTimer := time.NewTicker(tx.Opts.IncreaseInterval)
-	defer incTimer.Stop()
+	defer func() { incTimer.Stop() }()
+	// slowedIntervals is the amount of increase intervals left
+	// to be slowed down after the transaction was found thrashing.
+	var slowedIntervals int
 
 	checkTimer := time.NewTicker(tx.Opts.CheckInterval)
 	defer checkTimer.Stop()
 
+	var predictor *EWMAGasPredictor
+	if i.cfg.UseAdaptiveBumping {
+		predictor = NewEWMAGasPredictor(i.cfg.AdaptiveAlpha, i.cfg.AdaptiveBumpThreshold)
+	}
+
+	// mined is set once a successful receipt is found while
+	// waiting for the required confirmations.
+	mined := tx.State == TxStatePendingConfirmation
+	// longRunningReported is set once the transaction was reported as
+	// being watched for longer than the AlertOnWatchDuration.
+	var longRunningReported bool
+
 	for {
 		select {
 		case <-i.stop:
 			return nil
+		case <-ctx.Done():
+			i.syncer.txInterrupted(tx)
+			return nil
 		case <-checkTimer.C:
-			status, err := i.getTxStatus(tx)
+			if !longRunningReported {
+				longRunningReported = i.checkWatchDuration(tx)
+			}
+			status, receipt, err := i.getTxStatus(ctx, tx)
 			if err != nil {
 				if !i.isBlockchainErrorUnhandleable(err) {
 					return err
@@ -222,10 +789,58 @@ func (i *GasPriceIncremenetor) watchAndIncrement(tx Transaction) error {
 				i.log(tx, fmt.Errorf("received unhandleable receipt error, marking tx as failed: %w", err))
 				return i.transactionFailed(tx)
 			}
-			if status == StatusSucceeded {
+			mined = status == StatusSucceeded
+			if mined {
+				confirmed, err := i.isConfirmed(tx, receipt)
+				if err != nil {
+					i.log(tx, err)
+					continue
+				}
+				if !confirmed {
+					continue
+				}
+
+				i.handleReceiptEvents(tx, receipt)
 				return i.transactionSuccess(tx)
 			}
+			if tx.Opts.ExpiryBlock != nil {
+				expired, err := i.isPastExpiryBlock(tx)
+				if err != nil {
+					return err
+				}
+				if expired {
+					return i.transactionFailed(tx)
+				}
+			}
 		case <-incTimer.C:
+			// Mined transactions can't be replaced anymore.
+			if mined {
+				continue
+			}
+			// Suspended signers would fail signing and the transaction would be marked as failed.
+			if i.health.isSuspended(tx.SenderAddress()) {
+				continue
+			}
+			if slowedIntervals > 0 {
+				slowedIntervals--
+				if slowedIntervals == 0 {
+					incTimer = replaceTicker(incTimer, tx.Opts.IncreaseInterval)
+				}
+			}
+			if !i.checkNonceSafety(ctx, tx) {
+				continue
+			}
+			if predictor != nil {
+				bump, err := i.shouldBumpAdaptive(tx, predictor)
+				if err != nil {
+					i.log(tx, err)
+					continue
+				}
+				if !bump {
+					continue
+				}
+			}
+
 			newTx, err := i.increaseGasPrice(tx)
 			if err != nil {
 				if !i.isBlockchainErrorUnhandleable(err) {
@@ -235,12 +850,59 @@ func (i *GasPriceIncremenetor) watchAndIncrement(tx Transaction) error {
 				return i.transactionFailed(tx)
 			}
 			tx = newTx
+			i.syncer.txBumped(tx)
+
+			if slowedIntervals == 0 && i.checkThrashing(tx) {
+				slowedIntervals = thrashingSlowedIntervals
+				incTimer = replaceTicker(incTimer, tx.Opts.IncreaseInterval*3/2)
+			}
+		case <-timeoutWarning:
+			i.log(tx, ErrTimeoutWarning{UniqueID: tx.UniqueID, Remaining: tx.Opts.WarnBeforeTimeout})
 		case <-timeout:
+			if tx.State == TxStatePendingConfirmation {
+				i.log(tx, errors.New("required confirmations not reached before the confirmation deadline, marking tx as failed"))
+				return i.transactionFailed(tx)
+			}
+			if mined {
+				return i.transactionPendingConfirmation(tx)
+			}
 			return i.transactionFailed(tx)
 		}
 	}
 }
 
+// isConfirmed returns true if the receipt block has at least
+// the required amount of blocks mined on top of it.
+func (i *GasPriceIncremenetor) isConfirmed(tx Transaction, receipt *types.Receipt) (bool, error) {
+	if tx.Opts.RequiredConfirmations == 0 {
+		return true, nil
+	}
+	if receipt == nil || receipt.BlockNumber == nil {
+		return false, errors.New("can't check confirmations, receipt has no block number")
+	}
+
+	current, err := i.bc.BlockNumber(tx.ChainID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	head := new(big.Int).SetUint64(current)
+	if head.Cmp(receipt.BlockNumber) < 0 {
+		return false, nil
+	}
+
+	return new(big.Int).Sub(head, receipt.BlockNumber).Uint64() >= tx.Opts.RequiredConfirmations, nil
+}
+
+func (i *GasPriceIncremenetor) isPastExpiryBlock(tx Transaction) (bool, error) {
+	current, err := i.bc.BlockNumber(tx.ChainID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	return new(big.Int).SetUint64(current).Cmp(tx.Opts.ExpiryBlock) > 0, nil
+}
+
 func (i *GasPriceIncremenetor) isBlockchainErrorUnhandleable(err error) bool {
 	if errors.Is(err, core.ErrNonceTooHigh) || errors.Is(err, core.ErrNonceTooLow) || errors.Is(err, ethereum.NotFound) {
 		return true
@@ -266,10 +928,14 @@ func (i *GasPriceIncremenetor) increaseGasPrice(tx Transaction) (Transaction, er
 		return Transaction{}, err
 	}
 
-	newGasPrice, _ := new(big.Float).Mul(
-		big.NewFloat(tx.Opts.PriceMultiplier),
-		new(big.Float).SetInt(org.GasPrice()),
-	).Int(nil)
+	newGasPrice := nextGasPrice(org.GasPrice(), tx.Opts.PriceMultiplier, i.minBumpPercent(tx.ChainID))
+	floor, err := i.minGasPrice(tx)
+	if err != nil {
+		return Transaction{}, err
+	}
+	if floor != nil && newGasPrice.Cmp(floor) < 0 {
+		newGasPrice = new(big.Int).Set(floor)
+	}
 
 	if newGasPrice.Cmp(tx.Opts.MaxPrice) > 0 {
 		if err := i.transactionFailed(tx); err != nil {
@@ -279,12 +945,96 @@ func (i *GasPriceIncremenetor) increaseGasPrice(tx Transaction) (Transaction, er
 		return Transaction{}, fmt.Errorf("transaction with uniqueID '%s' failed, gas price limit of %s reached on chain %d", tx.UniqueID, tx.Opts.MaxPrice.String(), tx.ChainID)
 	}
 
-	newTx, err := i.signAndSend(tx.rebuiledWithNewGasPrice(org, newGasPrice), tx.ChainID, tx.SenderAddressHex)
+	newTx, err := i.sendWithUnderpricedRetry(tx, org, newGasPrice)
 	if err != nil {
+		i.log(tx, err)
 		return Transaction{}, i.transactionFailed(tx)
 	}
+	if sent := newTx.GasPrice(); i.cfg.WarnPrice != nil && sent.Cmp(i.cfg.WarnPrice) > 0 {
+		i.log(tx, ErrGasPriceWarning{Price: sent, WarnPrice: i.cfg.WarnPrice, MaxPrice: tx.Opts.MaxPrice})
+	}
 
-	return i.transactionPriceIncreased(tx, newTx)
+	bumped, err := i.transactionPriceIncreased(tx, newTx)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	i.postBump(bumped, org.GasPrice(), newTx.GasPrice())
+	return bumped, nil
+}
+
+// minGasPrice returns the lowest gas price allowed for the transaction.
+// Nil is returned if there is no floor.
+func (i *GasPriceIncremenetor) minGasPrice(tx Transaction) (*big.Int, error) {
+	floor := i.chainConfig(tx.ChainID).MinGasPrice
+	if tx.Opts.MinGasPrice != nil {
+		floor = tx.Opts.MinGasPrice
+	}
+
+	chainMin, err := i.minPrices.get(tx.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain suggested gas price: %w", err)
+	}
+	if chainMin != nil && (floor == nil || chainMin.Cmp(floor) > 0) {
+		floor = chainMin
+	}
+
+	return floor, nil
+}
+
+func (i *GasPriceIncremenetor) minBumpPercent(chainID int64) float64 {
+	pct := i.chainConfig(chainID).MinBumpPercent
+	if pct <= 0 {
+		return DefaultMinBumpPercent
+	}
+	return pct
+}
+
+func (i *GasPriceIncremenetor) lockTTL() time.Duration {
+	if i.cfg.LockTTL <= 0 {
+		return DefaultLockTTL
+	}
+	return i.cfg.LockTTL
+}
+
+// chainConfig returns the config to be used for the given chain
+// applying chain overrides given to AddChain.
+//
+// Only fields set in the override replace the global config.
+func (i *GasPriceIncremenetor) chainConfig(chainID int64) GasIncrementorConfig {
+	cfg := i.cfg
+	if override, ok := i.chains.override(chainID); ok {
+		if override.MinBumpPercent > 0 {
+			cfg.MinBumpPercent = override.MinBumpPercent
+		}
+		if override.MinGasPrice != nil {
+			cfg.MinGasPrice = override.MinGasPrice
+		}
+	}
+	return cfg
+}
+
+// nextGasPrice multiplies the given gas price by the multiplier
+// making sure the result is at least minBumpPercent above the old price.
+func nextGasPrice(old *big.Int, multiplier, minBumpPercent float64) *big.Int {
+	newGasPrice, _ := new(big.Float).Mul(
+		big.NewFloat(multiplier),
+		new(big.Float).SetInt(old),
+	).Int(nil)
+
+	// Minimal price is calculated in basis points and rounded up
+	// to avoid float precision issues the mempool would reject.
+	bps := big.NewInt(int64(math.Round(minBumpPercent * 10000)))
+	minGasPrice := new(big.Int).Mul(old, bps)
+	minGasPrice.Add(minGasPrice, big.NewInt(9999))
+	minGasPrice.Div(minGasPrice, big.NewInt(10000))
+	minGasPrice.Add(minGasPrice, old)
+
+	if newGasPrice.Cmp(minGasPrice) < 0 {
+		return minGasPrice
+	}
+
+	return newGasPrice
 }
 
 // BCTxStatus represents the status of tx on blockchain.
@@ -299,28 +1049,35 @@ const (
 	StatusSucceeded BCTxStatus = "Succeeded"
 )
 
-func (i *GasPriceIncremenetor) getTxStatus(tx Transaction) (BCTxStatus, error) {
+func (i *GasPriceIncremenetor) getTxStatus(ctx context.Context, tx Transaction) (BCTxStatus, *types.Receipt, error) {
 	org, err := tx.getLatestTx()
 	if err != nil {
-		return StatusFailed, fmt.Errorf("can't get tx status, malformed internal tx object: %w", err)
+		return StatusFailed, nil, fmt.Errorf("can't get tx status, malformed internal tx object: %w", err)
 	}
 
-	hash := org.Hash()
-	_, pending, err := i.bc.TransactionByHash(tx.ChainID, hash)
+	receipt, pending, err := i.receipts.FetchReceipt(ctx, tx.ChainID, org.Hash())
 	if err != nil {
-		return StatusFailed, fmt.Errorf("failed to get transaction by hash: %w", err)
+		return StatusFailed, nil, err
 	}
-
 	if pending {
-		return StatusPending, nil
+		return StatusPending, nil, nil
 	}
 
-	receipt, err := i.bc.TransactionReceipt(tx.ChainID, hash)
+	return i.bcTxStatusFromReceipt(tx, receipt), receipt, nil
+}
+
+func (i *GasPriceIncremenetor) handleReceiptEvents(tx Transaction, receipt *types.Receipt) {
+	if i.cfg.ReceiptEventsFn == nil {
+		return
+	}
+
+	events, err := DecodeReceiptEvents(receipt, i.cfg.ReceiptEventABIs)
 	if err != nil {
-		return StatusFailed, fmt.Errorf("failed to get transaction receipt: %w", err)
+		i.log(tx, fmt.Errorf("failed to decode receipt events: %w", err))
+		return
 	}
 
-	return i.bcTxStatusFromReceipt(tx, receipt), nil
+	i.cfg.ReceiptEventsFn(tx, events)
 }
 
 func (i *GasPriceIncremenetor) bcTxStatusFromReceipt(tx Transaction, rcp *types.Receipt) BCTxStatus {
@@ -346,10 +1103,28 @@ func (i *GasPriceIncremenetor) signAndSend(tx *types.Transaction, chainID int64,
 		return nil, fmt.Errorf("can't retry, no signer for address: %s", senderAddrHex)
 	}
 
+	unlock, err := i.nonces.Lock(chainID, common.HexToAddress(senderAddrHex))
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	signedTx, err := signer(tx, chainID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign a transaction: %w", err)
 	}
+	if err := verifySignedTx(signedTx, chainID, common.HexToAddress(senderAddrHex)); err != nil {
+		return nil, fmt.Errorf("signed transaction rejected: %w", err)
+	}
+
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(signedTx.Gas()), signedTx.GasPrice())
+	if delay := i.blockGas.reserve(chainID, cost); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-i.stop:
+			return nil, errors.New("incrementor stopped while waiting for block gas budget")
+		}
+	}
 
 	if err := i.bc.SendTransaction(chainID, signedTx); err != nil {
 		return nil, fmt.Errorf("failed send a transaction: %w", err)
@@ -358,24 +1133,77 @@ func (i *GasPriceIncremenetor) signAndSend(tx *types.Transaction, chainID int64,
 	return signedTx, nil
 }
 
+// ErrChainIDMismatch is returned if a transaction was signed for a different chain than expected.
+type ErrChainIDMismatch struct {
+	Expected, Got int64
+}
+
+func (e ErrChainIDMismatch) Error() string {
+	return fmt.Sprintf("transaction chain ID mismatch: expected %d, got %d", e.Expected, e.Got)
+}
+
+// verifySignedTx checks that the transaction is signed for the given chain by the given sender.
+// Sender check is skipped for an empty sender to stay backwards compatible with single signer setups.
+func verifySignedTx(tx *types.Transaction, chainID int64, sender common.Address) error {
+	if got := tx.ChainId().Int64(); got != chainID {
+		return ErrChainIDMismatch{Expected: chainID, Got: got}
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(chainID)), tx)
+	if err != nil {
+		return fmt.Errorf("failed to recover transaction sender: %w", err)
+	}
+	if sender != (common.Address{}) && from != sender {
+		return fmt.Errorf("transaction is signed by %s, expected %s", from.Hex(), sender.Hex())
+	}
+
+	return nil
+}
+
 func (i *GasPriceIncremenetor) transactionFailed(tx Transaction) error {
+	if err := ValidateStateTransition(tx.State, TxStateFailed); err != nil {
+		return fmt.Errorf("failed marking transaction as failed: %w", err)
+	}
 	tx.State = TxStateFailed
-	if err := i.storage.UpsertIncrementorTransaction(tx); err != nil {
+	tx.FinalizedAt = time.Now().UTC()
+	if err := i.upsert(tx); err != nil {
 		return fmt.Errorf("failed marking transaction as failed: %w", err)
 	}
+	i.finalized(tx)
 
 	return nil
 }
 
 func (i *GasPriceIncremenetor) transactionSuccess(tx Transaction) error {
+	if err := ValidateStateTransition(tx.State, TxStateSucceed); err != nil {
+		return fmt.Errorf("failed marking transaction succeed: %w", err)
+	}
 	tx.State = TxStateSucceed
-	if err := i.storage.UpsertIncrementorTransaction(tx); err != nil {
+	tx.FinalizedAt = time.Now().UTC()
+	if err := i.upsert(tx); err != nil {
 		return fmt.Errorf("failed marking transaction succeed: %w", err)
 	}
+	i.finalized(tx)
+	return nil
+}
+
+func (i *GasPriceIncremenetor) transactionPendingConfirmation(tx Transaction) error {
+	if err := ValidateStateTransition(tx.State, TxStatePendingConfirmation); err != nil {
+		return fmt.Errorf("failed marking transaction as pending confirmation: %w", err)
+	}
+	tx.State = TxStatePendingConfirmation
+	if err := i.upsert(tx); err != nil {
+		return fmt.Errorf("failed marking transaction as pending confirmation: %w", err)
+	}
+
 	return nil
 }
 
 func (i *GasPriceIncremenetor) transactionPriceIncreased(tx Transaction, newTx *types.Transaction) (Transaction, error) {
+	if err := ValidateStateTransition(tx.State, TxStatePriceIncreased); err != nil {
+		return Transaction{}, fmt.Errorf("failed to update transaction after price increase: %w", err)
+	}
+
 	var err error
 	tx.State = TxStatePriceIncreased
 	tx.LatestTx, err = newTx.MarshalJSON()
@@ -383,13 +1211,47 @@ func (i *GasPriceIncremenetor) transactionPriceIncreased(tx Transaction, newTx *
 		return Transaction{}, fmt.Errorf("failed to marshal internal transaction object: %w", err)
 	}
 
-	if err := i.storage.UpsertIncrementorTransaction(tx); err != nil {
+	if err := i.upsert(tx); err != nil {
 		return Transaction{}, fmt.Errorf("failed to update transaction after price increase: %w", err)
 	}
 
 	return tx, nil
 }
 
+// finalized calls the FinalizedFn in a new goroutine recovering from its panics.
+func (i *GasPriceIncremenetor) finalized(tx Transaction) {
+	if i.cfg.FinalizedFn == nil {
+		return
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				i.log(tx, ErrHookPanic{UniqueID: tx.UniqueID, Hook: "FinalizedFn", Value: r})
+			}
+		}()
+		if err := i.cfg.FinalizedFn(tx); err != nil {
+			i.log(tx, fmt.Errorf("finalized transaction handler failed: %w", err))
+		}
+	}()
+}
+
+// postBump calls the PostBumpHook in a new goroutine recovering from its panics.
+func (i *GasPriceIncremenetor) postBump(tx Transaction, oldGasPrice, newGasPrice *big.Int) {
+	if i.cfg.PostBumpHook == nil {
+		return
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				i.log(tx, ErrHookPanic{UniqueID: tx.UniqueID, Hook: "PostBumpHook", Value: r})
+			}
+		}()
+		i.cfg.PostBumpHook(tx, oldGasPrice, newGasPrice)
+	}()
+}
+
 func (i *GasPriceIncremenetor) log(tx Transaction, err error) {
 	if i.logFn != nil {
 		i.logFn(tx, err)
@@ -399,31 +1261,197 @@ func (i *GasPriceIncremenetor) log(tx Transaction, err error) {
 // syncer is used to sync Incrementor so that
 // we dont start tracking the same transaction multiple times.
 type syncer struct {
-	txs map[string]struct{}
-	m   sync.Mutex
+	// txs holds watched transactions keyed by syncer key.
+	txs map[string]Transaction
+	// startedAt holds the time watching of a transaction started.
+	startedAt map[string]time.Time
+	// watches holds the watchers of transactions.
+	watches map[string]*watch
+	// bumps holds the amount of gas price increases since watching started.
+	bumps map[string]int
+	// draining is set once FlushSyncer was called, no new watches are started after it.
+	draining bool
+	// interrupted holds the latest state of transactions whose watchers were stopped while draining.
+	interrupted map[string]Transaction
+	// flushedAt is the time FlushSyncer completed.
+	flushedAt time.Time
+	m         sync.Mutex
+}
+
+// watch is a running transaction watcher.
+type watch struct {
+	cancel context.CancelFunc
+	// done is closed once the watcher has exited.
+	done chan struct{}
 }
 
 func newSyncer() *syncer {
-	return &syncer{txs: make(map[string]struct{})}
+	return &syncer{
+		txs:       make(map[string]Transaction),
+		startedAt: make(map[string]time.Time),
+		watches:   make(map[string]*watch),
+		bumps:     make(map[string]int),
+
+		interrupted: make(map[string]Transaction),
+	}
+}
+
+// syncerKey returns a chain scoped key so that equal unique IDs
+// on different chains do not collide.
+func syncerKey(tx Transaction) string {
+	return fmt.Sprintf("%d/%s", tx.ChainID, tx.UniqueID)
 }
 
-func (s *syncer) txMarkBeingWatched(tx Transaction) {
+// txBumped records a gas price increase of a watched transaction.
+func (s *syncer) txBumped(tx Transaction) {
 	s.m.Lock()
 	defer s.m.Unlock()
-	s.txs[tx.UniqueID] = struct{}{}
+	key := syncerKey(tx)
+	if _, ok := s.txs[key]; ok {
+		s.bumps[key]++
+	}
+}
+
+// bumpStats returns the amount of gas price increases and the time
+// watching started of the watched transaction.
+func (s *syncer) bumpStats(tx Transaction) (bumps int, startedAt time.Time, ok bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	key := syncerKey(tx)
+	if _, ok := s.txs[key]; !ok {
+		return 0, time.Time{}, false
+	}
+	return s.bumps[key], s.startedAt[key], true
+}
+
+// txByUniqueID returns the watched transaction with the given unique ID.
+// It has to scan all watched transactions, so watchers should
+// look their own transactions up by syncer key instead.
+func (s *syncer) txByUniqueID(uniqueID string) (Transaction, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, tx := range s.txs {
+		if tx.UniqueID == uniqueID {
+			return tx, true
+		}
+	}
+	return Transaction{}, false
+}
+
+// txRestoreStartedAt overrides the time watching of the transaction started
+// if it is being watched.
+func (s *syncer) txRestoreStartedAt(tx Transaction, startedAt time.Time) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	key := syncerKey(tx)
+	if _, ok := s.txs[key]; ok {
+		s.startedAt[key] = startedAt
+	}
 }
 
 func (s *syncer) txBeingWatched(tx Transaction) bool {
 	s.m.Lock()
 	defer s.m.Unlock()
-	_, ok := s.txs[tx.UniqueID]
+	_, ok := s.txs[syncerKey(tx)]
 	return ok
 }
 
 func (s *syncer) txRemoveWatched(tx Transaction) {
 	s.m.Lock()
 	defer s.m.Unlock()
-	delete(s.txs, tx.UniqueID)
+	key := syncerKey(tx)
+	delete(s.txs, key)
+	delete(s.startedAt, key)
+	delete(s.watches, key)
+	delete(s.bumps, key)
+}
+
+// txStartWatch marks the transaction as watched by the given watcher.
+// Returns false and removes the transaction if the syncer is draining.
+func (s *syncer) txStartWatch(tx Transaction, w *watch) bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+	key := syncerKey(tx)
+	if s.draining {
+		delete(s.txs, key)
+		delete(s.startedAt, key)
+		delete(s.watches, key)
+		delete(s.bumps, key)
+		return false
+	}
+
+	s.txs[key] = tx
+	s.startedAt[key] = time.Now().UTC()
+	s.watches[key] = w
+	delete(s.bumps, key)
+	return true
+}
+
+// txWatchDone removes the watched transaction unless
+// the watcher was replaced by a newer one.
+func (s *syncer) txWatchDone(tx Transaction, w *watch) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	key := syncerKey(tx)
+	if s.watches[key] != w {
+		return
+	}
+	delete(s.txs, key)
+	delete(s.startedAt, key)
+	delete(s.watches, key)
+	delete(s.bumps, key)
+}
+
+// txStopWatch cancels the watcher of the transaction and returns a channel
+// closed once it has exited. The transaction is kept marked as watched
+// so that it is not picked up again until txRemoveWatched is called
+// or a new watcher is started.
+func (s *syncer) txStopWatch(tx Transaction) <-chan struct{} {
+	s.m.Lock()
+	defer s.m.Unlock()
+	key := syncerKey(tx)
+
+	stopped := make(chan struct{})
+	if w, ok := s.watches[key]; ok {
+		w.cancel()
+		stopped = w.done
+	} else {
+		close(stopped)
+	}
+
+	if _, ok := s.txs[key]; !ok {
+		s.txs[key] = tx
+		s.startedAt[key] = time.Now().UTC()
+	}
+	// There is no watcher running, so the placeholder is done once the stopped one is.
+	s.watches[key] = &watch{cancel: func() {}, done: stopped}
+	return stopped
+}
+
+func (s *syncer) watchedByChain() map[int64]int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	res := make(map[int64]int)
+	for _, tx := range s.txs {
+		res[tx.ChainID]++
+	}
+	return res
+}
+
+func (s *syncer) forEach(fn func(tx Transaction, startedAt time.Time)) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for key, tx := range s.txs {
+		fn(tx, s.startedAt[key])
+	}
+}
+
+func (s *syncer) count() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return len(s.txs)
 }
 
 // SignatureFunc is used to sign transactions when resubmitting them.
@@ -454,6 +1482,34 @@ func (s *safeSigners) getSignerFunc(senderAddressHex string) (SignatureFunc, boo
 	return signer, ok
 }
 
+func (s *safeSigners) add(addr common.Address, signer SignatureFunc) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.signers == nil {
+		s.signers = make(map[common.Address]SignatureFunc)
+	}
+	s.signers[addr] = signer
+}
+
+func (s *safeSigners) remove(addr common.Address) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	delete(s.signers, addr)
+}
+
+func (s *safeSigners) all() map[common.Address]SignatureFunc {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	signers := make(map[common.Address]SignatureFunc, len(s.signers))
+	for addr, signer := range s.signers {
+		signers[addr] = signer
+	}
+	return signers
+}
+
 func (s *safeSigners) getSigners() []string {
 	s.m.Lock()
 	defer s.m.Unlock()
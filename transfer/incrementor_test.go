@@ -17,6 +17,8 @@
 package transfer
 
 import (
+	"context"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"math/big"
@@ -28,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -44,8 +47,9 @@ func TestGasPriceIncrementor(t *testing.T) {
 		st := &mockStorage{}
 		c := newClient(big.NewInt(2))
 
-		sender := common.HexToAddress("")
-		sg := signer{}
+		sg := newSigner()
+		sender := sg.address
+		org = sg.mustSign(org, chid)
 		inc := NewGasPriceIncremenetor(cfg, st, c, map[common.Address]SignatureFunc{
 			sender: sg.SignatureFunc,
 		})
@@ -61,6 +65,8 @@ func TestGasPriceIncrementor(t *testing.T) {
 		}, time.Second, time.Millisecond*10)
 
 		inc.Stop()
+		st.m.Lock()
+		defer st.m.Unlock()
 		assert.True(t, st.inserted)
 		assert.True(t, st.pulled)
 		assert.True(t, c.sent, "should be sent")
@@ -84,8 +90,9 @@ func TestGasPriceIncrementor(t *testing.T) {
 		st := &mockStorage{}
 		c := newClient(big.NewInt(0))
 
-		sender := common.HexToAddress("")
-		sg := signer{}
+		sg := newSigner()
+		sender := sg.address
+		org = sg.mustSign(org, chid)
 		inc := NewGasPriceIncremenetor(cfg, st, c, map[common.Address]SignatureFunc{
 			sender: sg.SignatureFunc,
 		})
@@ -102,6 +109,8 @@ func TestGasPriceIncrementor(t *testing.T) {
 		}, time.Second, time.Millisecond*10)
 
 		inc.Stop()
+		st.m.Lock()
+		defer st.m.Unlock()
 		assert.True(t, st.inserted)
 		assert.True(t, st.pulled)
 		assert.False(t, c.sent, "already mind, should not check")
@@ -125,8 +134,9 @@ func TestGasPriceIncrementor(t *testing.T) {
 		st := &mockStorage{}
 		c := newClient(new(big.Int).Add(opts.MaxPrice, big.NewInt(5)))
 
-		sender := common.HexToAddress("")
-		sg := signer{}
+		sg := newSigner()
+		sender := sg.address
+		org = sg.mustSign(org, chid)
 		inc := NewGasPriceIncremenetor(cfg, st, c, map[common.Address]SignatureFunc{
 			sender: sg.SignatureFunc,
 		})
@@ -143,6 +153,8 @@ func TestGasPriceIncrementor(t *testing.T) {
 		}, time.Second, time.Millisecond*10)
 
 		inc.Stop()
+		st.m.Lock()
+		defer st.m.Unlock()
 		assert.True(t, st.inserted)
 		assert.True(t, st.pulled)
 		assert.True(t, c.sent, "should be sent once")
@@ -163,8 +175,9 @@ func TestGasPriceIncrementor(t *testing.T) {
 		st := &mockStorage{}
 		c := newClient(nil)
 
-		sender := common.HexToAddress("")
-		sg := signer{}
+		sg := newSigner()
+		sender := sg.address
+		org = sg.mustSign(org, chid)
 		inc := NewGasPriceIncremenetor(cfg, st, c, map[common.Address]SignatureFunc{
 			sender: sg.SignatureFunc,
 		})
@@ -172,6 +185,208 @@ func TestGasPriceIncrementor(t *testing.T) {
 	})
 }
 
+func TestGasPriceIncrementor_InsertInitialWithMetadata(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 1)
+	st := &mockStorage{}
+	sender := sg.address
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, newClient(nil), Signers{})
+
+	err := inc.InsertInitial(org, defaultOpts(), sender, WithMetadata("paymentID", "123"), WithMetadata("orderRef", ""))
+	assert.NoError(t, err)
+
+	txs, err := st.GetIncrementorTransactionsToCheck([]string{sender.Hex()})
+	assert.NoError(t, err)
+	assert.Len(t, txs, 1)
+	assert.Equal(t, map[string]string{"paymentID": "123", "orderRef": ""}, txs[0].Metadata)
+	assert.False(t, txs[0].CreatedAt.IsZero())
+}
+
+func TestGasPriceIncrementor_PriorityOrder(t *testing.T) {
+	created := time.Now()
+	newTx := func(id string, timeout time.Duration) Transaction {
+		opts := defaultOpts()
+		opts.Timeout = timeout
+		return Transaction{UniqueID: id, State: TxStateCreated, CreatedAt: created, Opts: opts}
+	}
+	txs := func() []Transaction {
+		return []Transaction{
+			newTx("late", time.Hour),
+			newTx("soonest", time.Minute),
+			newTx("middle", time.Minute*10),
+		}
+	}
+
+	var watched []string
+	watch := func(tx Transaction) {
+		watched = append(watched, tx.UniqueID)
+	}
+
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, &mockStorage{}, newClient(nil), Signers{})
+	inc.process(txs(), watch)
+	assert.Equal(t, []string{"late", "soonest", "middle"}, watched)
+
+	watched = nil
+	inc = NewGasPriceIncremenetor(GasIncrementorConfig{PriorityOrder: true}, &mockStorage{}, newClient(nil), Signers{})
+	inc.process(txs(), watch)
+	assert.Equal(t, []string{"soonest", "middle", "late"}, watched)
+
+	t.Run("transactions expiring at a block are watched last", func(t *testing.T) {
+		atBlock := func(id string) Transaction {
+			tx := newTx(id, 0)
+			tx.Opts.ExpiryBlock = big.NewInt(100)
+			return tx
+		}
+
+		watched = nil
+		inc.process([]Transaction{atBlock("block-a"), newTx("late", time.Hour), atBlock("block-b"), newTx("soonest", time.Minute)}, watch)
+		assert.Equal(t, []string{"soonest", "late", "block-a", "block-b"}, watched)
+	})
+}
+
+// advancingClient keeps transactions pending and mines a block on every block number call.
+type advancingClient struct {
+	mockClient
+	block uint64
+	m     sync.Mutex
+}
+
+func (c *advancingClient) TransactionByHash(chainID int64, hash common.Hash) (*types.Transaction, bool, error) {
+	return nil, true, nil
+}
+
+func (c *advancingClient) BlockNumber(chainID int64) (uint64, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.block++
+	return c.block, nil
+}
+
+func (c *advancingClient) currentBlock() uint64 {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.block
+}
+
+func TestGasPriceIncrementor_ExpiryBlock(t *testing.T) {
+	chid := int64(137)
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), chid)
+
+	opts := defaultOpts()
+	opts.Timeout = 0
+	opts.IncreaseInterval = time.Hour
+	opts.CheckInterval = time.Millisecond
+	opts.ExpiryBlock = big.NewInt(5)
+
+	st := &mockStorage{}
+	c := &advancingClient{}
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{PullInterval: time.Millisecond}, st, c, Signers{
+		sg.address: sg.SignatureFunc,
+	})
+	go inc.Run()
+	defer inc.Stop()
+
+	assert.NoError(t, inc.InsertInitial(org, opts, sg.address))
+	assert.Eventually(t, func() bool {
+		txs, _ := st.GetIncrementorTransactionsToCheck([]string{sg.address.Hex()})
+		return len(txs) == 1 && txs[0].State == TxStateFailed
+	}, time.Second, time.Millisecond*10)
+	assert.Equal(t, uint64(6), c.currentBlock(), "transaction should fail on the first block past expiry")
+
+	t.Run("exactly one of timeout or expiry block is required", func(t *testing.T) {
+		opts := defaultOpts()
+		opts.ExpiryBlock = big.NewInt(5)
+		assert.Error(t, opts.validate())
+
+		opts.Timeout = 0
+		assert.NoError(t, opts.validate())
+
+		opts.ExpiryBlock = nil
+		assert.Error(t, opts.validate())
+	})
+}
+
+// minPriceClient reports a fixed suggested gas price.
+type minPriceClient struct {
+	mockClient
+	min   *big.Int
+	calls int
+}
+
+func (c *minPriceClient) SuggestedGasPrice(chainID int64) (*big.Int, error) {
+	c.calls++
+	return c.min, nil
+}
+
+func TestGasPriceIncrementor_MinGasPrice(t *testing.T) {
+	chid := int64(137)
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), chid)
+
+	increase := func(cfg GasIncrementorConfig, c MultichainClient, opts TransactionOpts) *big.Int {
+		inc := NewGasPriceIncremenetor(cfg, &mockStorage{}, c, Signers{sg.address: sg.SignatureFunc})
+		tx, err := newTransaction(org, sg.address, opts)
+		assert.NoError(t, err)
+
+		increased, err := inc.increaseGasPrice(*tx)
+		assert.NoError(t, err)
+		latest, err := increased.getLatestTx()
+		assert.NoError(t, err)
+		return latest.GasPrice()
+	}
+
+	t.Run("multiplied price is used above the floor", func(t *testing.T) {
+		price := increase(GasIncrementorConfig{MinGasPrice: big.NewInt(2)}, newClient(nil), defaultOpts())
+		assert.Equal(t, big.NewInt(2), price)
+	})
+	t.Run("configured floor is used if multiplied price is lower", func(t *testing.T) {
+		price := increase(GasIncrementorConfig{MinGasPrice: big.NewInt(10)}, newClient(nil), defaultOpts())
+		assert.Equal(t, big.NewInt(10), price)
+	})
+	t.Run("transaction floor overrides configured floor", func(t *testing.T) {
+		opts := defaultOpts()
+		opts.MinGasPrice = big.NewInt(5)
+		price := increase(GasIncrementorConfig{MinGasPrice: big.NewInt(10)}, newClient(nil), opts)
+		assert.Equal(t, big.NewInt(5), price)
+	})
+	t.Run("network minimum is used if higher", func(t *testing.T) {
+		c := &minPriceClient{min: big.NewInt(20)}
+		cfg := GasIncrementorConfig{MinGasPrice: big.NewInt(10), ChainMinGasPriceRefreshInterval: time.Minute}
+		price := increase(cfg, c, defaultOpts())
+		assert.Equal(t, big.NewInt(20), price)
+		assert.Equal(t, 1, c.calls)
+	})
+	t.Run("network minimum is refreshed after interval", func(t *testing.T) {
+		c := &minPriceClient{min: big.NewInt(20)}
+		now := time.Now()
+		prices := newChainMinGasPrices(c, time.Minute)
+		prices.now = func() time.Time { return now }
+
+		for n := 0; n < 3; n++ {
+			price, err := prices.get(chid)
+			assert.NoError(t, err)
+			assert.Equal(t, big.NewInt(20), price)
+		}
+		assert.Equal(t, 1, c.calls)
+
+		now = now.Add(time.Minute)
+		_, err := prices.get(chid)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, c.calls)
+	})
+}
+
+// txMarkBeingWatched marks the transaction as watched without a watcher running.
+func (s *syncer) txMarkBeingWatched(tx Transaction) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	key := syncerKey(tx)
+	s.txs[key] = tx
+	s.startedAt[key] = time.Now().UTC()
+	delete(s.bumps, key)
+}
+
 func Test_syncer(t *testing.T) {
 	s := newSyncer()
 
@@ -180,6 +395,120 @@ func Test_syncer(t *testing.T) {
 	assert.True(t, s.txBeingWatched(tx), "transaction should be watched")
 	s.txRemoveWatched(tx)
 	assert.False(t, s.txBeingWatched(tx), "transaction should no longer be watched")
+
+	t.Run("same unique ID is watched independently on different chains", func(t *testing.T) {
+		s := newSyncer()
+		eth := Transaction{UniqueID: "0x0", ChainID: 1}
+		matic := Transaction{UniqueID: "0x0", ChainID: 137}
+
+		s.txMarkBeingWatched(eth)
+		assert.False(t, s.txBeingWatched(matic))
+		s.txMarkBeingWatched(matic)
+		s.txMarkBeingWatched(Transaction{UniqueID: "0x1", ChainID: 137})
+		assert.Equal(t, map[int64]int{1: 1, 137: 2}, s.watchedByChain())
+
+		s.txRemoveWatched(eth)
+		assert.False(t, s.txBeingWatched(eth))
+		assert.True(t, s.txBeingWatched(matic))
+		assert.Equal(t, map[int64]int{137: 2}, s.watchedByChain())
+	})
+	t.Run("bump stats are looked up by chain", func(t *testing.T) {
+		s := newSyncer()
+		eth := Transaction{UniqueID: "0x0", ChainID: 1}
+		matic := Transaction{UniqueID: "0x0", ChainID: 137}
+
+		s.txMarkBeingWatched(eth)
+		s.txMarkBeingWatched(matic)
+		s.txBumped(matic)
+		s.txBumped(matic)
+
+		bumps, _, ok := s.bumpStats(eth)
+		assert.True(t, ok)
+		assert.Equal(t, 0, bumps)
+		bumps, _, ok = s.bumpStats(matic)
+		assert.True(t, ok)
+		assert.Equal(t, 2, bumps)
+
+		_, _, ok = s.bumpStats(Transaction{UniqueID: "0x0", ChainID: 5})
+		assert.False(t, ok)
+	})
+}
+
+func TestGasPriceIncrementor_ForEachWatched(t *testing.T) {
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, &mockStorage{}, newClient(nil), Signers{})
+	start := time.Now().UTC()
+
+	var wg sync.WaitGroup
+	for n := 0; n < 50; n++ {
+		wg.Add(2)
+		tx := Transaction{UniqueID: fmt.Sprintf("0x%d", n), ChainID: int64(n % 2)}
+		go func() {
+			defer wg.Done()
+			inc.syncer.txMarkBeingWatched(tx)
+			if tx.ChainID == 0 {
+				inc.syncer.txRemoveWatched(tx)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			inc.ForEachWatched(func(tx Transaction, startedAt time.Time) {})
+		}()
+	}
+	wg.Wait()
+
+	count := 0
+	inc.ForEachWatched(func(tx Transaction, startedAt time.Time) {
+		count++
+		assert.Equal(t, int64(1), tx.ChainID)
+		assert.False(t, startedAt.Before(start))
+	})
+	assert.Equal(t, 25, count)
+	assert.Equal(t, inc.WatchedTxCount(), count)
+}
+
+func TestGasPriceIncrementor_WarmUpOnStart(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+	opts := defaultOpts()
+	opts.IncreaseInterval = time.Hour
+	tx, err := newTransaction(org, sg.address, opts)
+	assert.NoError(t, err)
+
+	st := &mockStorage{}
+	assert.NoError(t, st.UpsertIncrementorTransaction(*tx))
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{
+		PullInterval:  time.Hour,
+		WarmUpOnStart: true,
+	}, st, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+	go inc.Run()
+	defer inc.Stop()
+
+	// Pull interval is an hour, so only the warm up can start watching it.
+	assert.Eventually(t, func() bool {
+		return inc.WatchedTxCount() == 1
+	}, time.Second, time.Millisecond)
+}
+
+func TestGasPriceIncrementor_WarmUp(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+	opts := defaultOpts()
+	opts.IncreaseInterval = time.Hour
+	tx, err := newTransaction(org, sg.address, opts)
+	assert.NoError(t, err)
+
+	st := &mockStorage{}
+	assert.NoError(t, st.UpsertIncrementorTransaction(*tx))
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+	defer inc.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(t, inc.WarmUp(ctx))
+	assert.Equal(t, 0, inc.WatchedTxCount())
+
+	assert.NoError(t, inc.WarmUp(context.Background()))
+	assert.Equal(t, 1, inc.WatchedTxCount())
 }
 
 func defaultOpts() TransactionOpts {
@@ -215,6 +544,10 @@ func (s *mockStorage) UpsertIncrementorTransaction(tx Transaction) error {
 	return nil
 }
 
+func (s *mockStorage) InsertIncrementorTransactionIfAbsent(tx Transaction, idempotencyKey string) (bool, error) {
+	return true, s.UpsertIncrementorTransaction(tx)
+}
+
 func (s *mockStorage) GetIncrementorTransactionsToCheck(signers []string) (tx []Transaction, err error) {
 	s.m.Lock()
 	defer s.m.Unlock()
@@ -227,6 +560,40 @@ func (s *mockStorage) GetIncrementorSenderQueue(sender string) (length int, err
 	return 0, nil
 }
 
+func (s *mockStorage) GetIncrementorTransactionsByTimeRange(from, to time.Time, chainID int64, states ...TransactionState) ([]Transaction, error) {
+	return nil, nil
+}
+
+func (s *mockStorage) GetIncrementorFinalizedBefore(before time.Time) ([]Transaction, error) {
+	return nil, nil
+}
+
+func (s *mockStorage) GetIncrementorTransactionsByUniqueIDs(uniqueIDs []string) (map[string]Transaction, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	res := make(map[string]Transaction)
+	for _, id := range uniqueIDs {
+		if s.inserted && s.tx.UniqueID == id {
+			res[id] = s.tx
+		}
+	}
+	return res, nil
+}
+
+func (s *mockStorage) DeleteIncrementorTransactions(uniqueIDs []string) error {
+	return nil
+}
+
+func (s *mockStorage) LockTransaction(ctx context.Context, uniqueID string, ttl time.Duration) (TransactionLock, error) {
+	return noopLock{}, nil
+}
+
+type noopLock struct{}
+
+func (noopLock) Refresh(ttl time.Duration) error { return nil }
+func (noopLock) Unlock() error                   { return nil }
+
 type mockClient struct {
 	gasTreshold *big.Int
 	currentGas  *big.Int
@@ -258,6 +625,34 @@ func (c *mockClient) TransactionByHash(chainID int64, hash common.Hash) (*types.
 	return nil, false, nil
 }
 
+func (c *mockClient) TransactionConfirmations(chainID int64, hash common.Hash) (uint64, error) {
+	return 0, nil
+}
+
+func (c *mockClient) BlockNumber(chainID int64) (uint64, error) {
+	return 0, nil
+}
+
+func (c *mockClient) SuggestedGasPrice(chainID int64) (*big.Int, error) {
+	return nil, nil
+}
+
+func (c *mockClient) NonceAt(chainID int64, account common.Address) (uint64, error) {
+	return 0, nil
+}
+
+func (c *mockClient) PendingNonceAt(chainID int64, account common.Address) (uint64, error) {
+	return 0, nil
+}
+
+func (c *mockClient) HeaderByNumber(chainID int64, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(0)}, nil
+}
+
+func (c *mockClient) PendingTransactionCount(chainID int64) (uint, error) {
+	return 0, nil
+}
+
 func (c *mockClient) SendTransaction(chainID int64, tx *types.Transaction) error {
 	c.currentGas = tx.GasPrice()
 	c.sent = true
@@ -265,12 +660,30 @@ func (c *mockClient) SendTransaction(chainID int64, tx *types.Transaction) error
 }
 
 type signer struct {
-	signed bool
+	key     *ecdsa.PrivateKey
+	address common.Address
+	signed  bool
+}
+
+func newSigner() *signer {
+	key, _ := crypto.GenerateKey()
+	return &signer{
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+	}
+}
+
+func (s *signer) mustSign(tx *types.Transaction, chainID int64) *types.Transaction {
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(big.NewInt(chainID)), s.key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
 }
 
 func (s *signer) SignatureFunc(tx *types.Transaction, chainID int64) (*types.Transaction, error) {
 	s.signed = true
-	return tx, nil
+	return s.mustSign(tx, chainID), nil
 }
 
 func TestGasPriceIncremenetor_isBlockchainErrorUnhandleable(t *testing.T) {
@@ -313,3 +726,171 @@ func TestGasPriceIncremenetor_isBlockchainErrorUnhandleable(t *testing.T) {
 		})
 	}
 }
+
+func TestGasPriceIncrementor_ChainIDVerification(t *testing.T) {
+	sg := newSigner()
+	org := types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{})
+
+	t.Run("initial transaction signed by a different sender is rejected", func(t *testing.T) {
+		st := &mockStorage{}
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, newClient(nil), Signers{})
+
+		err := inc.InsertInitial(newSigner().mustSign(org, 137), defaultOpts(), sg.address)
+		assert.Error(t, err)
+		assert.False(t, st.inserted)
+	})
+	t.Run("initial transaction signed for a different chain is rejected", func(t *testing.T) {
+		st := &mockStorage{}
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, newClient(nil), Signers{})
+		opts := defaultOpts()
+		opts.ChainID = 137
+
+		err := inc.InsertInitial(sg.mustSign(org, 1), opts, sg.address)
+		var mismatch ErrChainIDMismatch
+		assert.True(t, errors.As(err, &mismatch))
+		assert.Equal(t, ErrChainIDMismatch{Expected: 137, Got: 1}, mismatch)
+		assert.False(t, st.inserted)
+
+		assert.NoError(t, inc.InsertInitial(sg.mustSign(org, 137), opts, sg.address))
+		assert.True(t, st.inserted)
+	})
+	t.Run("transaction signed for a different chain is not sent", func(t *testing.T) {
+		c := newClient(nil)
+		misconfigured := func(tx *types.Transaction, chainID int64) (*types.Transaction, error) {
+			return sg.mustSign(tx, 1), nil
+		}
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, &mockStorage{}, c, Signers{
+			sg.address: misconfigured,
+		})
+
+		_, err := inc.signAndSend(org, 137, sg.address.Hex())
+		var mismatch ErrChainIDMismatch
+		assert.True(t, errors.As(err, &mismatch))
+		assert.Equal(t, ErrChainIDMismatch{Expected: 137, Got: 1}, mismatch)
+		assert.False(t, c.sent)
+	})
+	t.Run("transaction signed for the expected chain is sent", func(t *testing.T) {
+		c := newClient(nil)
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, &mockStorage{}, c, Signers{
+			sg.address: sg.SignatureFunc,
+		})
+
+		_, err := inc.signAndSend(org, 137, sg.address.Hex())
+		assert.NoError(t, err)
+		assert.True(t, c.sent)
+	})
+}
+
+func Test_nextGasPrice(t *testing.T) {
+	tests := []struct {
+		name           string
+		old            int64
+		multiplier     float64
+		minBumpPercent float64
+		want           int64
+	}{
+		{name: "multiplier above minimal bump", old: 100, multiplier: 2, minBumpPercent: 0.1, want: 200},
+		{name: "minimal bump overrides small multiplier", old: 100, multiplier: 1.02, minBumpPercent: 0.1, want: 110},
+		{name: "result is rounded up", old: 1, multiplier: 1.02, minBumpPercent: 0.1, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextGasPrice(big.NewInt(tt.old), tt.multiplier, tt.minBumpPercent)
+			assert.Equal(t, big.NewInt(tt.want), got)
+		})
+	}
+}
+
+func TestGasPriceIncrementor_StartWatchingReloadsLockedTransaction(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+	opts := defaultOpts()
+	opts.IncreaseInterval = 10 * time.Millisecond
+	tx, err := newTransaction(org, sg.address, opts)
+	assert.NoError(t, err)
+
+	// Another instance finalized the transaction while it was locked.
+	finalized := *tx
+	finalized.State = TxStateSucceed
+	st := &mapStorage{}
+	assert.NoError(t, st.UpsertIncrementorTransaction(finalized))
+
+	bc := &sendTimesClient{}
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, bc, Signers{sg.address: sg.SignatureFunc})
+	defer inc.Stop()
+
+	inc.startWatching(*tx)
+	assert.Eventually(t, func() bool { return inc.WatchedTxCount() == 0 }, time.Second, 5*time.Millisecond)
+	time.Sleep(3 * opts.IncreaseInterval)
+
+	bc.m.Lock()
+	assert.Empty(t, bc.times, "finalized transaction should not be bumped")
+	bc.m.Unlock()
+	stored, _ := st.get(tx.UniqueID)
+	assert.Equal(t, TxStateSucceed, stored.State)
+}
+
+// blockingLockStorage never grants locks and records refreshes of granted ones.
+type blockingLockStorage struct {
+	mapStorage
+	grant bool
+}
+
+func (s *blockingLockStorage) LockTransaction(ctx context.Context, uniqueID string, ttl time.Duration) (TransactionLock, error) {
+	if s.grant {
+		return lostLock{}, nil
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+type lostLock struct{}
+
+func (lostLock) Refresh(ttl time.Duration) error { return ErrLockLost }
+func (lostLock) Unlock() error                   { return nil }
+
+func TestGasPriceIncrementor_WatchLock(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+	opts := defaultOpts()
+	opts.IncreaseInterval = time.Hour
+	tx, err := newTransaction(org, sg.address, opts)
+	assert.NoError(t, err)
+
+	t.Run("waiting for the lock is stopped by flushing", func(t *testing.T) {
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, &blockingLockStorage{}, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+		defer inc.Stop()
+
+		inc.startWatching(*tx)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		assert.NoError(t, inc.FlushSyncer(ctx))
+		assert.Zero(t, inc.WatchedTxCount())
+	})
+	t.Run("waiting for the lock is stopped by stop", func(t *testing.T) {
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, &blockingLockStorage{}, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+		inc.startWatching(*tx)
+		inc.Stop()
+		assert.Eventually(t, func() bool { return inc.WatchedTxCount() == 0 }, time.Second, 5*time.Millisecond)
+	})
+	t.Run("watching stops once the lock is lost", func(t *testing.T) {
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{LockTTL: 30 * time.Millisecond}, &blockingLockStorage{grant: true}, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+		defer inc.Stop()
+
+		var lost bool
+		var m sync.Mutex
+		inc.AttachLogFunc(func(tx Transaction, err error) {
+			if errors.Is(err, ErrLockLost) {
+				m.Lock()
+				defer m.Unlock()
+				lost = true
+			}
+		})
+
+		inc.startWatching(*tx)
+		assert.Eventually(t, func() bool { return inc.WatchedTxCount() == 0 }, time.Second, 5*time.Millisecond)
+		m.Lock()
+		assert.True(t, lost)
+		m.Unlock()
+	})
+}
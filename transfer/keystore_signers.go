@@ -0,0 +1,151 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// KeystoreSignerLoader loads signers from encrypted keystore files in a directory.
+//
+// All keystore files are expected to be encrypted with the same passphrase.
+type KeystoreSignerLoader struct {
+	Dir        string
+	Passphrase string
+}
+
+// Load decrypts all `UTC--*` keystore files in the directory and returns their signers.
+func (l *KeystoreSignerLoader) Load() (Signers, error) {
+	keys, err := l.load(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	signers := make(Signers, len(keys))
+	for _, key := range keys {
+		signers[key.address] = keySignatureFunc(key.privateKey)
+	}
+	return signers, nil
+}
+
+// WatchDir polls the directory every interval adding signers of new keystore
+// files to the incrementor and removing the ones whose files were deleted.
+//
+// The returned func stops watching.
+func (l *KeystoreSignerLoader) WatchDir(interval time.Duration, incrementor *GasPriceIncremenetor) func() {
+	stop := make(chan struct{})
+	known := make(map[string]loadedKey)
+
+	refresh := func() {
+		keys, err := l.load(known)
+		if err != nil {
+			incrementor.log(Transaction{}, fmt.Errorf("failed to load keystore signers: %w", err))
+			return
+		}
+
+		for path, key := range known {
+			if _, ok := keys[path]; !ok {
+				incrementor.RemoveSigner(key.address)
+				delete(known, path)
+			}
+		}
+		for path, key := range keys {
+			if _, ok := known[path]; !ok {
+				incrementor.AddSigner(key.address, keySignatureFunc(key.privateKey))
+				known[path] = key
+			}
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		refresh()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				refresh()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(stop) })
+	}
+}
+
+type loadedKey struct {
+	address    common.Address
+	privateKey *ecdsa.PrivateKey
+}
+
+// load returns the keys of all keystore files keyed by path.
+// Files present in known are not decrypted again.
+func (l *KeystoreSignerLoader) load(known map[string]loadedKey) (map[string]loadedKey, error) {
+	paths, err := filepath.Glob(filepath.Join(l.Dir, "UTC--*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keystore files: %w", err)
+	}
+
+	keys := make(map[string]loadedKey, len(paths))
+	for _, path := range paths {
+		if key, ok := known[path]; ok {
+			keys[path] = key
+			continue
+		}
+
+		keyJSON, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keystore file %s: %w", path, err)
+		}
+
+		key, err := keystore.DecryptKey(keyJSON, l.Passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt keystore file %s: %w", path, err)
+		}
+
+		keys[path] = loadedKey{address: key.Address, privateKey: key.PrivateKey}
+	}
+
+	return keys, nil
+}
+
+func keySignatureFunc(key *ecdsa.PrivateKey) SignatureFunc {
+	return func(tx *types.Transaction, chainID int64) (*types.Transaction, error) {
+		opts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(chainID))
+		if err != nil {
+			return nil, err
+		}
+
+		return opts.Signer(opts.From, tx)
+	}
+}
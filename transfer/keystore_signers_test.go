@@ -0,0 +1,81 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeystoreSignerLoader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore-signers")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ks := keystore.NewKeyStore(dir, 2, 1)
+	newAccount := func() accounts.Account {
+		acc, err := ks.NewAccount("secret")
+		assert.NoError(t, err)
+		return acc
+	}
+	first := newAccount()
+	loader := &KeystoreSignerLoader{Dir: dir, Passphrase: "secret"}
+
+	t.Run("loads signers", func(t *testing.T) {
+		signers, err := loader.Load()
+		assert.NoError(t, err)
+		assert.Len(t, signers, 1)
+
+		signed, err := signers[first.Address](types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 21000, big.NewInt(1), nil), 137)
+		assert.NoError(t, err)
+		assert.NoError(t, verifySignedTx(signed, 137, first.Address))
+	})
+	t.Run("fails with wrong passphrase", func(t *testing.T) {
+		_, err := (&KeystoreSignerLoader{Dir: dir, Passphrase: "wrong"}).Load()
+		assert.Error(t, err)
+	})
+	t.Run("watches directory", func(t *testing.T) {
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, &mockStorage{}, newClient(nil), Signers{})
+		interval := time.Millisecond * 50
+		stop := loader.WatchDir(interval, inc)
+		defer stop()
+
+		hasSigner := func(addr common.Address) func() bool {
+			return func() bool {
+				_, ok := inc.signers.all()[addr]
+				return ok
+			}
+		}
+		assert.Eventually(t, hasSigner(first.Address), time.Second, time.Millisecond*5)
+
+		second := newAccount()
+		assert.Eventually(t, hasSigner(second.Address), interval*2, time.Millisecond*5, "new keystore file should be picked up within one interval")
+
+		assert.NoError(t, os.Remove(second.URL.Path))
+		assert.Eventually(t, func() bool { return !hasSigner(second.Address)() }, interval*2, time.Millisecond*5)
+		assert.True(t, hasSigner(first.Address)())
+	})
+}
@@ -0,0 +1,71 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"math/big"
+	"sync"
+	"time"
+)
+
+// chainMinGasPrices caches the suggested gas prices of chains used as a gas price floor.
+//
+// The suggested price is a market price, not the protocol enforced minimum.
+type chainMinGasPrices struct {
+	bc       MultichainClient
+	interval time.Duration
+
+	prices      map[int64]*big.Int
+	refreshedAt map[int64]time.Time
+	now         func() time.Time
+	m           sync.Mutex
+}
+
+func newChainMinGasPrices(bc MultichainClient, interval time.Duration) *chainMinGasPrices {
+	return &chainMinGasPrices{
+		bc:          bc,
+		interval:    interval,
+		prices:      make(map[int64]*big.Int),
+		refreshedAt: make(map[int64]time.Time),
+		now:         time.Now,
+	}
+}
+
+// get returns the gas price floor of the chain refreshing it if it's
+// older than the configured interval. Nil is returned if disabled.
+func (c *chainMinGasPrices) get(chainID int64) (*big.Int, error) {
+	if c.interval <= 0 {
+		return nil, nil
+	}
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	now := c.now()
+	if refreshed, ok := c.refreshedAt[chainID]; ok && now.Sub(refreshed) < c.interval {
+		return c.prices[chainID], nil
+	}
+
+	price, err := c.bc.SuggestedGasPrice(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.prices[chainID] = price
+	c.refreshedAt[chainID] = now
+	return price, nil
+}
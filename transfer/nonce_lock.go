@@ -0,0 +1,60 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrNonceLockStopped is returned if the incrementor stops while waiting for a nonce lock.
+var ErrNonceLockStopped = errors.New("stopped while waiting for nonce lock")
+
+// ChannelNonceLock serializes transaction submission per chain and sender
+// so that transactions sharing a nonce are never sent concurrently.
+type ChannelNonceLock struct {
+	locks sync.Map
+	stop  <-chan struct{}
+}
+
+// NewChannelNonceLock returns a new nonce lock. Waiting for a lock
+// is aborted with ErrNonceLockStopped once stop is closed.
+func NewChannelNonceLock(stop <-chan struct{}) *ChannelNonceLock {
+	return &ChannelNonceLock{stop: stop}
+}
+
+// Lock acquires the send lock of the sender on the given chain
+// and returns a func releasing it.
+func (l *ChannelNonceLock) Lock(chainID int64, sender common.Address) (func(), error) {
+	key := fmt.Sprintf("%d/%s", chainID, sender.Hex())
+	lock, _ := l.locks.LoadOrStore(key, make(chan struct{}, 1))
+	ch := lock.(chan struct{})
+
+	select {
+	case ch <- struct{}{}:
+	case <-l.stop:
+		return nil, ErrNonceLockStopped
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-ch })
+	}, nil
+}
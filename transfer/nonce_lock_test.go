@@ -0,0 +1,107 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// nonceClient accepts a single transaction per nonce
+// and fails if transactions are sent concurrently.
+type nonceClient struct {
+	mockClient
+	sending bool
+	used    map[uint64]bool
+	m       sync.Mutex
+}
+
+func (c *nonceClient) SendTransaction(chainID int64, tx *types.Transaction) error {
+	c.m.Lock()
+	if c.sending {
+		c.m.Unlock()
+		return errors.New("concurrent send")
+	}
+	c.sending = true
+	c.m.Unlock()
+
+	time.Sleep(time.Millisecond * 10)
+
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.sending = false
+	if c.used[tx.Nonce()] {
+		return fmt.Errorf("send failed: %w", core.ErrNonceTooLow)
+	}
+	c.used[tx.Nonce()] = true
+	return nil
+}
+
+func TestGasPriceIncrementor_NonceLock(t *testing.T) {
+	sg := newSigner()
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, &mockStorage{}, &nonceClient{used: make(map[uint64]bool)}, Signers{sg.address: sg.SignatureFunc})
+	tx := types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{})
+
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for n := range errs {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			_, errs[n] = inc.signAndSend(tx, 137, sg.address.Hex())
+		}(n)
+	}
+	wg.Wait()
+
+	succeeded, nonceTooLow := 0, 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, core.ErrNonceTooLow):
+			nonceTooLow++
+		default:
+			assert.NoError(t, err)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, 1, nonceTooLow)
+
+	t.Run("waiting is aborted on stop", func(t *testing.T) {
+		lock := NewChannelNonceLock(inc.stop)
+		unlock, err := lock.Lock(137, sg.address)
+		assert.NoError(t, err)
+		defer unlock()
+
+		other, err := lock.Lock(1, sg.address)
+		assert.NoError(t, err, "other chains should not be locked")
+		other()
+
+		inc.Stop()
+		_, err = lock.Lock(137, sg.address)
+		assert.True(t, errors.Is(err, ErrNonceLockStopped))
+	})
+}
@@ -0,0 +1,116 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"fmt"
+)
+
+// ErrUnsafeNonce is logged when a gas price bump is skipped because
+// the sender has submitted transactions with higher nonces.
+type ErrUnsafeNonce struct {
+	UniqueID     string
+	Nonce        uint64
+	PendingNonce uint64
+}
+
+func (e ErrUnsafeNonce) Error() string {
+	return fmt.Sprintf("skipping gas price increase of transaction %s with nonce %d, sender pending nonce is %d", e.UniqueID, e.Nonce, e.PendingNonce)
+}
+
+// NonceSafetyCheck returns false if the sender has submitted transactions
+// with nonces higher than the nonce of this transaction, e.g. manually.
+//
+// Watched are the nonces of other transactions of the sender which are
+// known to be submitted by the incrementor and are not considered unsafe.
+func (t *Transaction) NonceSafetyCheck(ctx context.Context, cl MultichainClient, watched ...uint64) (safe bool, pendingNonce uint64, err error) {
+	if err := ctx.Err(); err != nil {
+		return false, 0, err
+	}
+
+	latest, err := t.getLatestTx()
+	if err != nil {
+		return false, 0, fmt.Errorf("can't check nonce, malformed internal tx object: %w", err)
+	}
+
+	pendingNonce, err = cl.PendingNonceAt(t.ChainID, t.SenderAddress())
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to get sender pending nonce: %w", err)
+	}
+
+	known := make(map[uint64]struct{}, len(watched))
+	for _, nonce := range watched {
+		known[nonce] = struct{}{}
+	}
+	for nonce := latest.Nonce() + 1; nonce < pendingNonce; nonce++ {
+		if _, ok := known[nonce]; !ok {
+			return false, pendingNonce, nil
+		}
+	}
+
+	return true, pendingNonce, nil
+}
+
+// checkNonceSafety returns false if the gas price of the transaction should not be increased.
+func (i *GasPriceIncremenetor) checkNonceSafety(ctx context.Context, tx Transaction) bool {
+	if !i.cfg.NonceSafetyCheckEnabled {
+		return true
+	}
+
+	watched, err := i.watchedNonces(tx)
+	if err != nil {
+		i.log(tx, err)
+		return false
+	}
+
+	safe, pendingNonce, err := tx.NonceSafetyCheck(ctx, i.bc, watched...)
+	if err != nil {
+		i.log(tx, err)
+		return false
+	}
+	if !safe {
+		// Latest transaction was already decoded by the check.
+		latest, _ := tx.getLatestTx()
+		i.log(tx, ErrUnsafeNonce{UniqueID: tx.UniqueID, Nonce: latest.Nonce(), PendingNonce: pendingNonce})
+	}
+
+	return safe
+}
+
+// watchedNonces returns the nonces of other non finalized transactions
+// of the same sender and chain stored by the incrementor.
+func (i *GasPriceIncremenetor) watchedNonces(tx Transaction) ([]uint64, error) {
+	txs, err := i.storage.GetIncrementorTransactionsToCheck([]string{tx.SenderAddress().Hex()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sender transactions: %w", err)
+	}
+
+	nonces := make([]uint64, 0, len(txs))
+	for _, other := range txs {
+		if other.UniqueID == tx.UniqueID || other.ChainID != tx.ChainID || other.SenderAddress() != tx.SenderAddress() || other.State.IsTerminal() {
+			continue
+		}
+		latest, err := other.getLatestTx()
+		if err != nil {
+			continue
+		}
+		nonces = append(nonces, latest.Nonce())
+	}
+
+	return nonces, nil
+}
@@ -0,0 +1,140 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// pendingNonceClient keeps transactions pending and reports the given sender pending nonce.
+type pendingNonceClient struct {
+	pendingClient
+	pendingNonce uint64
+}
+
+func (c *pendingNonceClient) PendingNonceAt(chainID int64, account common.Address) (uint64, error) {
+	return c.pendingNonce, nil
+}
+
+func TestTransaction_NonceSafetyCheck(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(5, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+	tx, err := newTransaction(org, sg.address, defaultOpts())
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		pendingNonce uint64
+		safe         bool
+	}{
+		{5, true},
+		{6, true},
+		{7, false},
+		{10, false},
+	} {
+		safe, pendingNonce, err := tx.NonceSafetyCheck(context.Background(), &pendingNonceClient{pendingNonce: tc.pendingNonce})
+		assert.NoError(t, err)
+		assert.Equal(t, tc.safe, safe, "pending nonce %d", tc.pendingNonce)
+		assert.Equal(t, tc.pendingNonce, pendingNonce)
+	}
+
+	t.Run("watched nonces are not unsafe", func(t *testing.T) {
+		safe, _, err := tx.NonceSafetyCheck(context.Background(), &pendingNonceClient{pendingNonce: 8}, 6, 7)
+		assert.NoError(t, err)
+		assert.True(t, safe)
+
+		safe, _, err = tx.NonceSafetyCheck(context.Background(), &pendingNonceClient{pendingNonce: 8}, 7)
+		assert.NoError(t, err)
+		assert.False(t, safe, "nonce 6 was not submitted by the incrementor")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = tx.NonceSafetyCheck(ctx, &pendingNonceClient{})
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestGasPriceIncrementor_NonceSafetyCheck(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(5, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+	opts := defaultOpts()
+	opts.Timeout = 200 * time.Millisecond
+	tx, err := newTransaction(org, sg.address, opts)
+	assert.NoError(t, err)
+
+	watch := func(enabled bool, queued ...Transaction) (*pendingNonceClient, []ErrUnsafeNonce) {
+		bc := &pendingNonceClient{pendingNonce: 10}
+		st := &queuedStorage{queued: queued}
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{NonceSafetyCheckEnabled: enabled}, st, bc, Signers{sg.address: sg.SignatureFunc})
+		defer inc.Stop()
+
+		var warnings []ErrUnsafeNonce
+		var m sync.Mutex
+		inc.AttachLogFunc(func(tx Transaction, err error) {
+			var warning ErrUnsafeNonce
+			if errors.As(err, &warning) {
+				m.Lock()
+				defer m.Unlock()
+				warnings = append(warnings, warning)
+			}
+		})
+
+		assert.NoError(t, inc.watchAndIncrement(context.Background(), *tx))
+		m.Lock()
+		defer m.Unlock()
+		return bc, warnings
+	}
+
+	bc, warnings := watch(true)
+	assert.False(t, bc.sent, "gas price should not be increased after a skipped nonce")
+	if assert.NotEmpty(t, warnings) {
+		assert.Equal(t, ErrUnsafeNonce{UniqueID: tx.UniqueID, Nonce: 5, PendingNonce: 10}, warnings[0])
+	}
+
+	bc, warnings = watch(false)
+	assert.True(t, bc.sent)
+	assert.Empty(t, warnings)
+
+	var queued []Transaction
+	for nonce := uint64(6); nonce < 10; nonce++ {
+		other, err := newTransaction(sg.mustSign(types.NewTransaction(nonce, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137), sg.address, opts)
+		assert.NoError(t, err)
+		queued = append(queued, *other)
+	}
+	bc, warnings = watch(true, queued...)
+	assert.True(t, bc.sent, "nonces queued by the incrementor should not block increases")
+	assert.Empty(t, warnings)
+}
+
+// queuedStorage additionally returns the queued transactions as ones to check.
+type queuedStorage struct {
+	mockStorage
+	queued []Transaction
+}
+
+func (s *queuedStorage) GetIncrementorTransactionsToCheck(signers []string) ([]Transaction, error) {
+	txs, err := s.mockStorage.GetIncrementorTransactionsToCheck(signers)
+	return append(txs, s.queued...), err
+}
@@ -0,0 +1,169 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PartitionedStorage provides a separate storage for every chain.
+type PartitionedStorage interface {
+	// ForChain returns the storage holding transactions of the given chain.
+	ForChain(chainID int64) Storage
+}
+
+// NewGasPriceIncremenetorPartitioned returns a new incrementer instance which
+// keeps transactions of each of the given chains in a separate storage partition.
+//
+// The given chains are registered as if AddChain was called for each of them.
+func NewGasPriceIncremenetorPartitioned(cfg GasIncrementorConfig, storage PartitionedStorage, chainIDs []int64, cl MultichainClient, signers Signers) *GasPriceIncremenetor {
+	ps := &partitionedStorage{storage: storage}
+	inc := NewGasPriceIncremenetor(cfg, ps, cl, signers)
+	ps.chains = inc.ListChains
+
+	for _, chainID := range chainIDs {
+		inc.AddChain(chainID)
+	}
+	return inc
+}
+
+// partitionedStorage implements Storage on top of
+// partitions of chains registered in the incrementor.
+type partitionedStorage struct {
+	storage PartitionedStorage
+	chains  func() []int64
+}
+
+func (s *partitionedStorage) UpsertIncrementorTransaction(tx Transaction) error {
+	return s.storage.ForChain(tx.ChainID).UpsertIncrementorTransaction(tx)
+}
+
+func (s *partitionedStorage) InsertIncrementorTransactionIfAbsent(tx Transaction, idempotencyKey string) (bool, error) {
+	return s.storage.ForChain(tx.ChainID).InsertIncrementorTransactionIfAbsent(tx, idempotencyKey)
+}
+
+func (s *partitionedStorage) GetIncrementorTransactionsToCheck(possibleSigners []string) ([]Transaction, error) {
+	return s.collect(func(st Storage) ([]Transaction, error) {
+		return st.GetIncrementorTransactionsToCheck(possibleSigners)
+	})
+}
+
+func (s *partitionedStorage) GetIncrementorSenderQueue(sender string) (int, error) {
+	total := 0
+	for _, chainID := range s.chains() {
+		length, err := s.storage.ForChain(chainID).GetIncrementorSenderQueue(sender)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get sender queue on chain %d: %w", chainID, err)
+		}
+		total += length
+	}
+	return total, nil
+}
+
+func (s *partitionedStorage) GetIncrementorTransactionsByTimeRange(from, to time.Time, chainID int64, states ...TransactionState) ([]Transaction, error) {
+	return s.storage.ForChain(chainID).GetIncrementorTransactionsByTimeRange(from, to, chainID, states...)
+}
+
+func (s *partitionedStorage) GetIncrementorFinalizedBefore(before time.Time) ([]Transaction, error) {
+	return s.collect(func(st Storage) ([]Transaction, error) {
+		return st.GetIncrementorFinalizedBefore(before)
+	})
+}
+
+func (s *partitionedStorage) GetIncrementorTransactionsByUniqueIDs(uniqueIDs []string) (map[string]Transaction, error) {
+	byChain, err := uniqueIDsByChain(uniqueIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]Transaction, len(uniqueIDs))
+	for chainID, ids := range byChain {
+		txs, err := s.storage.ForChain(chainID).GetIncrementorTransactionsByUniqueIDs(ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transactions on chain %d: %w", chainID, err)
+		}
+		for id, tx := range txs {
+			res[id] = tx
+		}
+	}
+	return res, nil
+}
+
+func (s *partitionedStorage) DeleteIncrementorTransactions(uniqueIDs []string) error {
+	byChain, err := uniqueIDsByChain(uniqueIDs)
+	if err != nil {
+		return err
+	}
+
+	for chainID, ids := range byChain {
+		if err := s.storage.ForChain(chainID).DeleteIncrementorTransactions(ids); err != nil {
+			return fmt.Errorf("failed to delete transactions on chain %d: %w", chainID, err)
+		}
+	}
+	return nil
+}
+
+func (s *partitionedStorage) LockTransaction(ctx context.Context, uniqueID string, ttl time.Duration) (TransactionLock, error) {
+	chainID, err := chainIDFromUniqueID(uniqueID)
+	if err != nil {
+		return nil, err
+	}
+	return s.storage.ForChain(chainID).LockTransaction(ctx, uniqueID, ttl)
+}
+
+func (s *partitionedStorage) collect(get func(st Storage) ([]Transaction, error)) ([]Transaction, error) {
+	res := make([]Transaction, 0)
+	for _, chainID := range s.chains() {
+		txs, err := get(s.storage.ForChain(chainID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transactions on chain %d: %w", chainID, err)
+		}
+		res = append(res, txs...)
+	}
+	return res, nil
+}
+
+// uniqueIDsByChain groups unique IDs created by TransactionUniqueID by their chain.
+func uniqueIDsByChain(uniqueIDs []string) (map[int64][]string, error) {
+	byChain := make(map[int64][]string)
+	for _, id := range uniqueIDs {
+		chainID, err := chainIDFromUniqueID(id)
+		if err != nil {
+			return nil, err
+		}
+		byChain[chainID] = append(byChain[chainID], id)
+	}
+	return byChain, nil
+}
+
+// chainIDFromUniqueID returns the chain ID of a unique ID created by TransactionUniqueID.
+func chainIDFromUniqueID(uniqueID string) (int64, error) {
+	idx := strings.LastIndex(uniqueID, "|")
+	if idx < 0 {
+		return 0, fmt.Errorf("unique ID %q does not contain a chain ID", uniqueID)
+	}
+
+	chainID, err := strconv.ParseInt(uniqueID[idx+1:], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unique ID %q contains an invalid chain ID: %w", uniqueID, err)
+	}
+	return chainID, nil
+}
@@ -0,0 +1,34 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_chainIDFromUniqueID(t *testing.T) {
+	chainID, err := chainIDFromUniqueID(TransactionUniqueID("0xabc", 137))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(137), chainID)
+
+	_, err = chainIDFromUniqueID("0xabc")
+	assert.Error(t, err)
+	_, err = chainIDFromUniqueID("0xabc|matic")
+	assert.Error(t, err)
+}
@@ -0,0 +1,97 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPriceIncrementor_PostBumpHook(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+	opts := defaultOpts()
+	opts.IncreaseInterval = 20 * time.Millisecond
+	opts.Timeout = 150 * time.Millisecond
+	opts.MaxPrice = big.NewInt(1000000)
+	tx, err := newTransaction(org, sg.address, opts)
+	assert.NoError(t, err)
+
+	type bump struct {
+		state              TransactionState
+		oldPrice, newPrice *big.Int
+	}
+	var bumps []bump
+	var panics []ErrHookPanic
+	var m sync.Mutex
+
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{
+		PostBumpHook: func(tx Transaction, oldGasPrice, newGasPrice *big.Int) {
+			m.Lock()
+			defer m.Unlock()
+			bumps = append(bumps, bump{state: tx.State, oldPrice: oldGasPrice, newPrice: newGasPrice})
+			if len(bumps) == 1 {
+				panic("hook failed")
+			}
+		},
+	}, &mockStorage{}, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+	defer inc.Stop()
+	inc.AttachLogFunc(func(tx Transaction, err error) {
+		var hookPanic ErrHookPanic
+		if errors.As(err, &hookPanic) {
+			m.Lock()
+			defer m.Unlock()
+			panics = append(panics, hookPanic)
+		}
+	})
+
+	inc.syncer.txMarkBeingWatched(*tx)
+	assert.NoError(t, inc.watchAndIncrement(context.Background(), *tx))
+
+	assert.Eventually(t, func() bool {
+		m.Lock()
+		defer m.Unlock()
+		return len(panics) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	m.Lock()
+	defer m.Unlock()
+	assert.Equal(t, "PostBumpHook", panics[0].Hook)
+	assert.Equal(t, "hook failed", panics[0].Value)
+	assert.True(t, len(bumps) >= 2, "watcher should keep bumping after a hook panic")
+
+	// Hooks run concurrently, so calls are ordered by their prices.
+	seen := make(map[string]bool)
+	for _, b := range bumps {
+		assert.Equal(t, TxStatePriceIncreased, b.state)
+		assert.True(t, b.newPrice.Cmp(b.oldPrice) > 0)
+		seen[b.newPrice.String()] = true
+	}
+	for _, b := range bumps {
+		if b.oldPrice.Cmp(big.NewInt(1)) != 0 {
+			assert.True(t, seen[b.oldPrice.String()], "old price %s should be the previous new price", b.oldPrice)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Default EWMAGasPredictor parameters.
+const (
+	DefaultEWMAAlpha         = 0.3
+	DefaultEWMABumpThreshold = 1.0
+)
+
+// EWMAGasPredictor keeps an exponentially weighted moving average
+// of observed market gas prices to decide when a bump is worth it.
+//
+// It is not safe for concurrent use.
+type EWMAGasPredictor struct {
+	// Alpha is the weight of the latest observation within (0, 1].
+	Alpha float64
+	// BumpThreshold is the multiple of the transaction gas price the
+	// average has to exceed for a bump to be suggested.
+	BumpThreshold float64
+
+	ewma *big.Float
+}
+
+// NewEWMAGasPredictor returns a new predictor, using defaults for non positive values.
+func NewEWMAGasPredictor(alpha, bumpThreshold float64) *EWMAGasPredictor {
+	if alpha <= 0 || alpha > 1 {
+		alpha = DefaultEWMAAlpha
+	}
+	if bumpThreshold <= 0 {
+		bumpThreshold = DefaultEWMABumpThreshold
+	}
+
+	return &EWMAGasPredictor{
+		Alpha:         alpha,
+		BumpThreshold: bumpThreshold,
+	}
+}
+
+// Observe adds a market gas price to the moving average.
+func (p *EWMAGasPredictor) Observe(price *big.Int) {
+	current := new(big.Float).SetInt(price)
+	if p.ewma == nil {
+		p.ewma = current
+		return
+	}
+
+	weighted := new(big.Float).Mul(current, big.NewFloat(p.Alpha))
+	previous := new(big.Float).Mul(p.ewma, big.NewFloat(1-p.Alpha))
+	p.ewma = weighted.Add(weighted, previous)
+}
+
+// EWMA returns the current moving average or nil if nothing was observed yet.
+func (p *EWMAGasPredictor) EWMA() *big.Int {
+	if p.ewma == nil {
+		return nil
+	}
+
+	res, _ := p.ewma.Int(nil)
+	return res
+}
+
+// ShouldBump observes the current market gas price and returns true
+// if the moving average exceeds the transaction gas price multiplied by BumpThreshold.
+func (p *EWMAGasPredictor) ShouldBump(currentMarket, txGasPrice *big.Int) bool {
+	p.Observe(currentMarket)
+
+	threshold := new(big.Float).Mul(new(big.Float).SetInt(txGasPrice), big.NewFloat(p.BumpThreshold))
+	return p.ewma.Cmp(threshold) > 0
+}
+
+// shouldBumpAdaptive checks whether the market gas price trend justifies bumping the transaction.
+func (i *GasPriceIncremenetor) shouldBumpAdaptive(tx Transaction, predictor *EWMAGasPredictor) (bool, error) {
+	org, err := tx.getLatestTx()
+	if err != nil {
+		return false, err
+	}
+
+	market, err := i.bc.SuggestedGasPrice(tx.ChainID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get market gas price: %w", err)
+	}
+
+	return predictor.ShouldBump(market, org.GasPrice()), nil
+}
@@ -0,0 +1,78 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEWMAGasPredictor_ShouldBump(t *testing.T) {
+	bumps := func(market []int64) int {
+		p := NewEWMAGasPredictor(0.5, 1)
+		txPrice := big.NewInt(market[0])
+		count := 0
+		for _, price := range market {
+			if p.ShouldBump(big.NewInt(price), txPrice) {
+				count++
+				txPrice = big.NewInt(price)
+			}
+		}
+		return count
+	}
+
+	stable := bumps([]int64{100, 100, 101, 99, 100, 100, 100, 100})
+	rising := bumps([]int64{100, 110, 120, 130, 140, 150, 160, 170})
+	assert.Equal(t, 1, stable)
+	assert.Equal(t, 7, rising)
+	assert.True(t, rising > stable)
+
+	t.Run("uses defaults", func(t *testing.T) {
+		p := NewEWMAGasPredictor(0, 0)
+		assert.Equal(t, DefaultEWMAAlpha, p.Alpha)
+		assert.Equal(t, DefaultEWMABumpThreshold, p.BumpThreshold)
+		assert.Nil(t, p.EWMA())
+
+		p.Alpha = 0.5
+		p.Observe(big.NewInt(100))
+		p.Observe(big.NewInt(200))
+		assert.Equal(t, big.NewInt(150), p.EWMA())
+	})
+}
+
+func TestGasPriceIncrementor_AdaptiveBumping(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(10), []byte{}), 137)
+	tx, err := newTransaction(org, sg.address, defaultOpts())
+	assert.NoError(t, err)
+
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{UseAdaptiveBumping: true}, &mockStorage{}, &minPriceClient{min: big.NewInt(10)}, Signers{sg.address: sg.SignatureFunc})
+	predictor := NewEWMAGasPredictor(inc.cfg.AdaptiveAlpha, inc.cfg.AdaptiveBumpThreshold)
+
+	bump, err := inc.shouldBumpAdaptive(*tx, predictor)
+	assert.NoError(t, err)
+	assert.False(t, bump, "market price matching the transaction should not cause a bump")
+
+	inc.bc = &minPriceClient{min: big.NewInt(50)}
+	bump, err = inc.shouldBumpAdaptive(*tx, predictor)
+	assert.NoError(t, err)
+	assert.True(t, bump, "rising market price should cause a bump")
+}
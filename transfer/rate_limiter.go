@@ -0,0 +1,98 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/time/rate"
+)
+
+// ErrRateLimitExceeded is returned if a sender is inserting transactions faster than allowed.
+var ErrRateLimitExceeded = errors.New("failed to insert a transaction, rate limit exceeded for sender")
+
+// RateLimitConfig configures how many transactions can be inserted for a single sender.
+//
+// Zero MaxInsertsPerSecond disables rate limiting.
+type RateLimitConfig struct {
+	MaxInsertsPerSecond int
+	// Burst is the amount of inserts allowed at once.
+	// If not given it defaults to MaxInsertsPerSecond.
+	Burst int
+}
+
+func (c RateLimitConfig) enabled() bool {
+	return c.MaxInsertsPerSecond > 0
+}
+
+func (c RateLimitConfig) newLimiter() *rate.Limiter {
+	burst := c.Burst
+	if burst <= 0 {
+		burst = c.MaxInsertsPerSecond
+	}
+
+	return rate.NewLimiter(rate.Limit(c.MaxInsertsPerSecond), burst)
+}
+
+// signerLimiters holds a rate limiter for every sender that has inserted a transaction.
+type signerLimiters struct {
+	defaults  RateLimitConfig
+	perSigner map[common.Address]RateLimitConfig
+
+	limiters map[common.Address]*rate.Limiter
+	now      func() time.Time
+	m        sync.Mutex
+}
+
+func newSignerLimiters(defaults RateLimitConfig, perSigner map[string]RateLimitConfig) *signerLimiters {
+	parsed := make(map[common.Address]RateLimitConfig, len(perSigner))
+	for addr, cfg := range perSigner {
+		parsed[common.HexToAddress(addr)] = cfg
+	}
+
+	return &signerLimiters{
+		defaults:  defaults,
+		perSigner: parsed,
+		limiters:  make(map[common.Address]*rate.Limiter),
+		now:       time.Now,
+	}
+}
+
+// allow returns true if a sender is allowed to insert another transaction.
+func (s *signerLimiters) allow(sender common.Address) bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	limiter, ok := s.limiters[sender]
+	if !ok {
+		cfg, ok := s.perSigner[sender]
+		if !ok {
+			cfg = s.defaults
+		}
+		if !cfg.enabled() {
+			return true
+		}
+
+		limiter = cfg.newLimiter()
+		s.limiters[sender] = limiter
+	}
+
+	return limiter.AllowN(s.now(), 1)
+}
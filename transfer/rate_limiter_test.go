@@ -0,0 +1,73 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPriceIncrementor_RateLimit(t *testing.T) {
+	senderA, senderB, senderC := newSigner(), newSigner(), newSigner()
+	cfg := GasIncrementorConfig{
+		PullInterval:      time.Millisecond,
+		MaxQueuePerSigner: 1000,
+		RateLimit: RateLimitConfig{
+			MaxInsertsPerSecond: 100,
+		},
+		PerSignerLimiter: map[string]RateLimitConfig{
+			senderB.address.Hex(): {MaxInsertsPerSecond: 1},
+		},
+	}
+
+	st := &mockStorage{}
+	inc := NewGasPriceIncremenetor(cfg, st, newClient(nil), Signers{})
+	now := time.Now()
+	inc.limiters.now = func() time.Time { return now }
+	insert := func(nonce uint64, sender *signer) error {
+		tx := sender.mustSign(types.NewTransaction(nonce, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 1)
+		return inc.InsertInitial(tx, defaultOpts(), sender.address)
+	}
+
+	t.Run("sender is limited after reaching the limit", func(t *testing.T) {
+		for n := uint64(0); n < 100; n++ {
+			assert.NoError(t, insert(n, senderA))
+		}
+		assert.Equal(t, ErrRateLimitExceeded, insert(100, senderA))
+	})
+	t.Run("sender is allowed again after a second", func(t *testing.T) {
+		now = now.Add(time.Second)
+		assert.NoError(t, insert(100, senderA))
+	})
+	t.Run("senders have independent limits", func(t *testing.T) {
+		assert.NoError(t, insert(0, senderB))
+		assert.Equal(t, ErrRateLimitExceeded, insert(1, senderB))
+
+		assert.NoError(t, insert(0, senderC))
+	})
+	t.Run("storage is not touched when limited", func(t *testing.T) {
+		now = now.Add(time.Millisecond)
+		st.inserted = false
+		assert.Equal(t, ErrRateLimitExceeded, insert(2, senderB))
+		assert.False(t, st.inserted)
+	})
+}
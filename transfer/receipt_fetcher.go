@@ -0,0 +1,67 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ReceiptFetcher returns transaction receipts.
+//
+// Implementations can be used to plug in alternative receipt sources
+// such as a caching layer or an indexer API.
+type ReceiptFetcher interface {
+	// FetchReceipt returns the receipt of the transaction or
+	// true if the transaction is still pending.
+	FetchReceipt(ctx context.Context, chainID int64, hash common.Hash) (receipt *types.Receipt, pending bool, err error)
+}
+
+// DefaultReceiptFetcher fetches receipts using a MultichainClient.
+type DefaultReceiptFetcher struct {
+	bc MultichainClient
+}
+
+// NewDefaultReceiptFetcher returns a new receipt fetcher using the given client.
+func NewDefaultReceiptFetcher(bc MultichainClient) *DefaultReceiptFetcher {
+	return &DefaultReceiptFetcher{bc: bc}
+}
+
+// FetchReceipt looks up the transaction and returns its receipt if it's no longer pending.
+func (f *DefaultReceiptFetcher) FetchReceipt(ctx context.Context, chainID int64, hash common.Hash) (*types.Receipt, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	_, pending, err := f.bc.TransactionByHash(chainID, hash)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get transaction by hash: %w", err)
+	}
+	if pending {
+		return nil, true, nil
+	}
+
+	receipt, err := f.bc.TransactionReceipt(chainID, hash)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get transaction receipt: %w", err)
+	}
+
+	return receipt, false, nil
+}
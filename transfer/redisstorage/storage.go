@@ -0,0 +1,497 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package redisstorage provides a Redis backed transfer.Storage implementation.
+package redisstorage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-redis/redis/v8"
+	"github.com/mysteriumnetwork/payments/transfer"
+)
+
+// KeyPrefix is the prefix of all keys used by RedisStorage.
+//
+// Transactions are stored as hashes under `{incrementor}:tx:<uniqueID>` and indexed
+// by sorted sets of creation time per state and of finalization time.
+// Sender queue lengths are kept in counters per sender.
+//
+// The prefix is a hash tag so all keys share a single Redis Cluster slot
+// which allows scripts to update a transaction and its indexes atomically.
+const KeyPrefix = "{incrementor}:"
+
+// IdempotencyKeyTTL is how long an idempotency key prevents inserting a duplicate transaction.
+const IdempotencyKeyTTL = 7 * 24 * time.Hour
+
+// lockRetryInterval is how often a held lock is rechecked.
+const lockRetryInterval = 10 * time.Millisecond
+
+var allStates = []transfer.TransactionState{
+	transfer.TxStateCreated,
+	transfer.TxStatePriceIncreased,
+	transfer.TxStatePendingConfirmation,
+	transfer.TxStateFailed,
+	transfer.TxStateSucceed,
+}
+
+// upsertScript atomically stores the transaction and updates its indexes.
+// If an idempotency key is given, nothing is stored if the key already exists.
+//
+// KEYS[1] transaction key, KEYS[2] finalized key, KEYS[3] sender queue key,
+// KEYS[4..3+n] state keys, KEYS[4+n] optional idempotency key
+// ARGV uniqueID, state, pending, sender, createdAt score, finalizedAt score, data,
+// idempotency key TTL in milliseconds, n, n state names matching the state keys
+var upsertScript = redis.NewScript(`
+local id, state, pending, sender = ARGV[1], ARGV[2], ARGV[3], ARGV[4]
+local n = tonumber(ARGV[9])
+local stateKeys = {}
+for i = 1, n do
+	stateKeys[ARGV[9 + i]] = KEYS[3 + i]
+end
+
+local old = redis.call('HMGET', KEYS[1], 'state', 'pending', 'sender')
+if old[2] == '1' and old[3] ~= sender then
+	return redis.error_reply('sender of a pending transaction changed')
+end
+
+local idempotencyKey = KEYS[4 + n]
+if idempotencyKey then
+	if redis.call('EXISTS', idempotencyKey) == 1 then
+		return 0
+	end
+	redis.call('SET', idempotencyKey, id, 'PX', ARGV[8])
+end
+
+if old[1] then
+	redis.call('ZREM', stateKeys[old[1]], id)
+	if old[2] == '1' then
+		redis.call('DECR', KEYS[3])
+	end
+end
+
+redis.call('HMSET', KEYS[1], 'state', state, 'pending', pending, 'sender', sender, 'data', ARGV[7])
+redis.call('ZADD', stateKeys[state], ARGV[5], id)
+if pending == '1' then
+	redis.call('INCR', KEYS[3])
+	redis.call('ZREM', KEYS[2], id)
+else
+	redis.call('ZADD', KEYS[2], ARGV[6], id)
+end
+return 1
+`)
+
+// deleteScript atomically removes the transaction and its indexes.
+// Returns -1 if the stored sender doesn't match the given one.
+//
+// KEYS[1] transaction key, KEYS[2] finalized key, KEYS[3] sender queue key, KEYS[4..3+n] state keys
+// ARGV uniqueID, sender, n, n state names matching the state keys
+var deleteScript = redis.NewScript(`
+local id, sender = ARGV[1], ARGV[2]
+local n = tonumber(ARGV[3])
+local stateKeys = {}
+for i = 1, n do
+	stateKeys[ARGV[3 + i]] = KEYS[3 + i]
+end
+
+local old = redis.call('HMGET', KEYS[1], 'state', 'pending', 'sender')
+if not old[1] then
+	return 0
+end
+if old[3] ~= sender then
+	return -1
+end
+
+redis.call('ZREM', stateKeys[old[1]], id)
+redis.call('ZREM', KEYS[2], id)
+if old[2] == '1' then
+	redis.call('DECR', KEYS[3])
+end
+redis.call('DEL', KEYS[1])
+return 1
+`)
+
+// unlockScript releases a lock only if it is still held with the given token.
+var unlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// refreshScript extends a lock only if it is still held with the given token.
+var refreshScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// RedisStorage is a transfer.Storage implementation backed by Redis.
+type RedisStorage struct {
+	cl redis.UniversalClient
+}
+
+var _ transfer.Storage = (*RedisStorage)(nil)
+
+// NewRedisStorage returns a new storage using the given client.
+func NewRedisStorage(cl redis.UniversalClient) *RedisStorage {
+	return &RedisStorage{cl: cl}
+}
+
+// UpsertIncrementorTransaction inserts or updates the given transaction.
+func (s *RedisStorage) UpsertIncrementorTransaction(tx transfer.Transaction) error {
+	_, err := s.upsert(tx, "")
+	return err
+}
+
+// InsertIncrementorTransactionIfAbsent inserts the given transaction
+// unless the idempotency key was used within IdempotencyKeyTTL.
+func (s *RedisStorage) InsertIncrementorTransactionIfAbsent(tx transfer.Transaction, idempotencyKey string) (bool, error) {
+	if idempotencyKey == "" {
+		return false, errors.New("idempotency key must not be empty")
+	}
+
+	return s.upsert(tx, KeyPrefix+"idempotency:"+idempotencyKey)
+}
+
+func (s *RedisStorage) upsert(tx transfer.Transaction, idempotencyKey string) (bool, error) {
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal transaction: %w", err)
+	}
+
+	pending := "1"
+	if tx.State.IsTerminal() {
+		pending = "0"
+	}
+
+	keys := indexKeys(tx.UniqueID, tx.SenderAddress())
+	if idempotencyKey != "" {
+		keys = append(keys, idempotencyKey)
+	}
+	args := append([]interface{}{
+		tx.UniqueID,
+		string(tx.State),
+		pending,
+		senderKey(tx.SenderAddress()),
+		score(tx.CreatedAt),
+		score(tx.FinalizedAt),
+		data,
+		IdempotencyKeyTTL.Milliseconds(),
+	}, stateArgs()...)
+
+	res, err := upsertScript.Run(context.Background(), s.cl, keys, args...).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to upsert transaction: %w", err)
+	}
+
+	return res == 1, nil
+}
+
+// GetIncrementorTransactionsToCheck returns all non finalized transactions of the given signers.
+func (s *RedisStorage) GetIncrementorTransactionsToCheck(possibleSigners []string) ([]transfer.Transaction, error) {
+	signers := make(map[common.Address]struct{}, len(possibleSigners))
+	for _, signer := range possibleSigners {
+		signers[common.HexToAddress(signer)] = struct{}{}
+	}
+
+	var ids []string
+	for _, state := range allStates {
+		if state.IsTerminal() {
+			continue
+		}
+
+		stateIDs, err := s.cl.ZRange(context.Background(), stateKey(state), 0, -1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s transactions: %w", state, err)
+		}
+		ids = append(ids, stateIDs...)
+	}
+
+	return s.load(ids, func(tx transfer.Transaction) bool {
+		_, ok := signers[tx.SenderAddress()]
+		return ok
+	})
+}
+
+// GetIncrementorSenderQueue returns the amount of non finalized transactions for the given sender.
+func (s *RedisStorage) GetIncrementorSenderQueue(sender string) (int, error) {
+	length, err := s.cl.Get(context.Background(), queueKey(common.HexToAddress(sender))).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sender queue: %w", err)
+	}
+
+	return length, nil
+}
+
+// GetIncrementorTransactionsByTimeRange returns transactions of the given chain created within `[from, to)`.
+func (s *RedisStorage) GetIncrementorTransactionsByTimeRange(from, to time.Time, chainID int64, states ...transfer.TransactionState) ([]transfer.Transaction, error) {
+	if len(states) == 0 {
+		states = allStates
+	}
+
+	var ids []string
+	for _, state := range states {
+		stateIDs, err := s.cl.ZRangeByScore(context.Background(), stateKey(state), &redis.ZRangeBy{
+			Min: score(from),
+			Max: scoreCeil(to),
+		}).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s transactions: %w", state, err)
+		}
+		ids = append(ids, stateIDs...)
+	}
+
+	// Scores are rounded to microseconds, so the exact range is checked again.
+	return s.load(ids, func(tx transfer.Transaction) bool {
+		return tx.ChainID == chainID && !tx.CreatedAt.Before(from) && tx.CreatedAt.Before(to)
+	})
+}
+
+// GetIncrementorFinalizedBefore returns all finalized transactions finalized before the given time.
+func (s *RedisStorage) GetIncrementorFinalizedBefore(before time.Time) ([]transfer.Transaction, error) {
+	ids, err := s.cl.ZRangeByScore(context.Background(), finalizedKey(), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: scoreCeil(before),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get finalized transactions: %w", err)
+	}
+
+	return s.load(ids, func(tx transfer.Transaction) bool {
+		return tx.FinalizedAt.Before(before)
+	})
+}
+
+// GetIncrementorTransactionsByUniqueIDs returns the stored transactions with the given unique IDs.
+func (s *RedisStorage) GetIncrementorTransactionsByUniqueIDs(uniqueIDs []string) (map[string]transfer.Transaction, error) {
+	txs, err := s.load(uniqueIDs, func(tx transfer.Transaction) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]transfer.Transaction, len(txs))
+	for _, tx := range txs {
+		res[tx.UniqueID] = tx
+	}
+	return res, nil
+}
+
+// DeleteIncrementorTransactions removes the transactions with given unique IDs.
+func (s *RedisStorage) DeleteIncrementorTransactions(uniqueIDs []string) error {
+	for _, id := range uniqueIDs {
+		if err := s.delete(id); err != nil {
+			return fmt.Errorf("failed to delete transaction %q: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *RedisStorage) delete(uniqueID string) error {
+	// Sender is read first as its queue key has to be passed to the script.
+	sender, err := s.cl.HGet(context.Background(), txKey(uniqueID), "sender").Result()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	args := append([]interface{}{uniqueID, sender}, stateArgs()...)
+	res, err := deleteScript.Run(context.Background(), s.cl, indexKeys(uniqueID, common.HexToAddress(sender)), args...).Int()
+	if err != nil {
+		return err
+	}
+	if res == -1 {
+		return errors.New("sender of the transaction changed")
+	}
+	return nil
+}
+
+// LockTransaction blocks until the lock of the transaction is released or expires
+// or the context is done.
+func (s *RedisStorage) LockTransaction(ctx context.Context, uniqueID string, ttl time.Duration) (transfer.TransactionLock, error) {
+	token, err := lockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	key := KeyPrefix + "lock:" + uniqueID
+	for {
+		ok, err := s.cl.SetNX(ctx, key, token, ttl).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock transaction: %w", err)
+		}
+		if ok {
+			return &redisLock{cl: s.cl, key: key, token: token}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// redisLock is a lock held with a random token.
+type redisLock struct {
+	cl    redis.UniversalClient
+	key   string
+	token string
+}
+
+// Refresh extends the lock if it's still held.
+func (l *redisLock) Refresh(ttl time.Duration) error {
+	extended, err := refreshScript.Run(context.Background(), l.cl, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("failed to refresh transaction lock: %w", err)
+	}
+	if extended == 0 {
+		return transfer.ErrLockLost
+	}
+	return nil
+}
+
+// Unlock releases the lock if it's still held.
+func (l *redisLock) Unlock() error {
+	// Lock might have expired and been acquired by someone else.
+	if err := unlockScript.Run(context.Background(), l.cl, []string{l.key}, l.token).Err(); err != nil {
+		return fmt.Errorf("failed to unlock transaction: %w", err)
+	}
+	return nil
+}
+
+// load returns the transactions with given IDs matching the predicate ordered by creation time.
+func (s *RedisStorage) load(ids []string, predicate func(tx transfer.Transaction) bool) ([]transfer.Transaction, error) {
+	res := make([]transfer.Transaction, 0, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	pipe := s.cl.Pipeline()
+	cmds := make([]*redis.StringCmd, 0, len(ids))
+	for _, id := range ids {
+		cmds = append(cmds, pipe.HGet(context.Background(), txKey(id), "data"))
+	}
+	if _, err := pipe.Exec(context.Background()); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to load transactions: %w", err)
+	}
+
+	for _, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if errors.Is(err, redis.Nil) {
+			// Deleted after the index was read.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load transaction: %w", err)
+		}
+
+		var tx transfer.Transaction
+		if err := json.Unmarshal(data, &tx); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+		}
+		if predicate(tx) {
+			res = append(res, tx)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+	return res, nil
+}
+
+func txKey(uniqueID string) string {
+	return KeyPrefix + "tx:" + uniqueID
+}
+
+func finalizedKey() string {
+	return KeyPrefix + "finalized"
+}
+
+// indexKeys returns the keys of the transaction and all of its indexes
+// in the order expected by upsertScript and deleteScript.
+func indexKeys(uniqueID string, sender common.Address) []string {
+	keys := []string{txKey(uniqueID), finalizedKey(), queueKey(sender)}
+	for _, state := range allStates {
+		keys = append(keys, stateKey(state))
+	}
+	return keys
+}
+
+// stateArgs returns the state names matching the state keys of indexKeys.
+func stateArgs() []interface{} {
+	args := []interface{}{len(allStates)}
+	for _, state := range allStates {
+		args = append(args, string(state))
+	}
+	return args
+}
+
+func stateKey(state transfer.TransactionState) string {
+	return KeyPrefix + "state:" + string(state)
+}
+
+func senderKey(sender common.Address) string {
+	return strings.ToLower(sender.Hex())
+}
+
+func queueKey(sender common.Address) string {
+	return KeyPrefix + "queue:" + senderKey(sender)
+}
+
+// score returns the sorted set score of the given time in microseconds
+// which keeps it within float64 integer precision.
+func score(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.UnixNano()/int64(time.Microsecond), 10)
+}
+
+// scoreCeil is like score but rounds up.
+func scoreCeil(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt((t.UnixNano()+int64(time.Microsecond)-1)/int64(time.Microsecond), 10)
+}
+
+func lockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,225 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package redisstorage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-redis/redis/v8"
+	"github.com/mysteriumnetwork/payments/transfer"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStorage(t *testing.T) (*RedisStorage, *miniredis.Miniredis, func()) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+
+	cl := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return NewRedisStorage(cl), mr, func() {
+		cl.Close()
+		mr.Close()
+	}
+}
+
+func newTx(id string, sender common.Address, state transfer.TransactionState, createdAt time.Time) transfer.Transaction {
+	tx := transfer.Transaction{
+		UniqueID:  id,
+		State:     state,
+		ChainID:   137,
+		CreatedAt: createdAt,
+		LatestTx:  []byte(`{}`),
+		Metadata:  map[string]string{"order": id, "empty": ""},
+	}
+	tx.SetSenderAddress(sender)
+	return tx
+}
+
+func TestRedisStorage(t *testing.T) {
+	st, mr, closeFn := newTestStorage(t)
+	defer closeFn()
+	sender := common.HexToAddress("0xF53aCDd584ccb85eE4EC1590007aD3c16FDFF057")
+	other := common.HexToAddress("0x2")
+	start := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	ids := func(txs []transfer.Transaction) []string {
+		res := make([]string, 0, len(txs))
+		for _, tx := range txs {
+			res = append(res, tx.UniqueID)
+		}
+		return res
+	}
+	queue := func(sender common.Address) int {
+		length, err := st.GetIncrementorSenderQueue(sender.Hex())
+		assert.NoError(t, err)
+		return length
+	}
+
+	first := newTx("first", sender, transfer.TxStateCreated, start)
+	assert.NoError(t, st.UpsertIncrementorTransaction(first))
+	assert.NoError(t, st.UpsertIncrementorTransaction(newTx("second", sender, transfer.TxStateCreated, start.Add(time.Minute))))
+	assert.NoError(t, st.UpsertIncrementorTransaction(newTx("other", other, transfer.TxStateCreated, start.Add(2*time.Minute))))
+	assert.True(t, mr.Exists(KeyPrefix+"tx:first"))
+
+	t.Run("transactions to check are filtered by signer", func(t *testing.T) {
+		txs, err := st.GetIncrementorTransactionsToCheck([]string{sender.Hex()})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"first", "second"}, ids(txs))
+		assert.Equal(t, first.Metadata, txs[0].Metadata)
+		assert.True(t, first.CreatedAt.Equal(txs[0].CreatedAt))
+		assert.Equal(t, 2, queue(sender))
+		assert.Equal(t, 1, queue(other))
+		assert.Equal(t, 0, queue(common.HexToAddress("0x3")))
+	})
+	t.Run("updates move transactions between states and update the queue", func(t *testing.T) {
+		first.State = transfer.TxStatePriceIncreased
+		assert.NoError(t, st.UpsertIncrementorTransaction(first))
+		assert.NoError(t, st.UpsertIncrementorTransaction(first))
+		assert.Equal(t, 2, queue(sender), "repeated upserts should not change the queue")
+
+		first.State = transfer.TxStateSucceed
+		first.FinalizedAt = start.Add(time.Hour)
+		assert.NoError(t, st.UpsertIncrementorTransaction(first))
+		assert.Equal(t, 1, queue(sender))
+
+		txs, err := st.GetIncrementorTransactionsToCheck([]string{sender.Hex(), other.Hex()})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"second", "other"}, ids(txs))
+	})
+	t.Run("time range", func(t *testing.T) {
+		txs, err := st.GetIncrementorTransactionsByTimeRange(start, start.Add(2*time.Minute), 137)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"first", "second"}, ids(txs))
+
+		txs, err = st.GetIncrementorTransactionsByTimeRange(start, start.Add(time.Hour), 137, transfer.TxStateSucceed)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"first"}, ids(txs))
+
+		txs, err = st.GetIncrementorTransactionsByTimeRange(start, start.Add(time.Hour), 1)
+		assert.NoError(t, err)
+		assert.Empty(t, txs)
+	})
+	t.Run("by unique IDs", func(t *testing.T) {
+		txs, err := st.GetIncrementorTransactionsByUniqueIDs([]string{"first", "other", "missing"})
+		assert.NoError(t, err)
+		assert.Len(t, txs, 2)
+		assert.Equal(t, transfer.TxStateSucceed, txs["first"].State)
+		assert.Equal(t, "other", txs["other"].UniqueID)
+	})
+	t.Run("finalized before", func(t *testing.T) {
+		txs, err := st.GetIncrementorFinalizedBefore(start.Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Empty(t, txs)
+
+		txs, err = st.GetIncrementorFinalizedBefore(start.Add(time.Hour + time.Nanosecond))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"first"}, ids(txs))
+	})
+	t.Run("insert if absent", func(t *testing.T) {
+		inserted, err := st.InsertIncrementorTransactionIfAbsent(newTx("new", sender, transfer.TxStateCreated, start), "key")
+		assert.NoError(t, err)
+		assert.True(t, inserted)
+
+		inserted, err = st.InsertIncrementorTransactionIfAbsent(newTx("duplicate", sender, transfer.TxStateCreated, start), "key")
+		assert.NoError(t, err)
+		assert.False(t, inserted)
+		assert.False(t, mr.Exists(KeyPrefix+"tx:duplicate"))
+		assert.Equal(t, 2, queue(sender))
+	})
+	t.Run("idempotency keys expire", func(t *testing.T) {
+		assert.Equal(t, IdempotencyKeyTTL, mr.TTL(KeyPrefix+"idempotency:key"))
+	})
+	t.Run("all keys share the hash tag", func(t *testing.T) {
+		for _, key := range mr.Keys() {
+			assert.True(t, strings.HasPrefix(key, "{incrementor}:"), key)
+		}
+	})
+	t.Run("delete", func(t *testing.T) {
+		assert.NoError(t, st.DeleteIncrementorTransactions([]string{"first", "new", "missing"}))
+		assert.False(t, mr.Exists(KeyPrefix+"tx:first"))
+		assert.Equal(t, 1, queue(sender))
+
+		txs, err := st.GetIncrementorFinalizedBefore(start.Add(2 * time.Hour))
+		assert.NoError(t, err)
+		assert.Empty(t, txs)
+
+		txs, err = st.GetIncrementorTransactionsToCheck([]string{sender.Hex()})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"second"}, ids(txs))
+	})
+}
+
+func TestRedisStorage_LockTransaction(t *testing.T) {
+	st, mr, closeFn := newTestStorage(t)
+	defer closeFn()
+
+	lock, err := st.LockTransaction(context.Background(), "tx", time.Minute)
+	assert.NoError(t, err)
+
+	var acquired bool
+	var m sync.Mutex
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lock, err := st.LockTransaction(context.Background(), "tx", time.Minute)
+		assert.NoError(t, err)
+		m.Lock()
+		acquired = true
+		m.Unlock()
+		assert.NoError(t, lock.Unlock())
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	m.Lock()
+	assert.False(t, acquired, "lock should not be acquired while held")
+	m.Unlock()
+
+	assert.NoError(t, lock.Unlock())
+	<-done
+	assert.False(t, mr.Exists(KeyPrefix+"lock:tx"))
+
+	t.Run("expired lock is not released by its previous holder", func(t *testing.T) {
+		lock, err := st.LockTransaction(context.Background(), "expiring", time.Second)
+		assert.NoError(t, err)
+		mr.FastForward(2 * time.Second)
+
+		next, err := st.LockTransaction(context.Background(), "expiring", time.Minute)
+		assert.NoError(t, err)
+		assert.NoError(t, lock.Unlock())
+		assert.True(t, mr.Exists(KeyPrefix+"lock:expiring"))
+		assert.True(t, errors.Is(lock.Refresh(time.Minute), transfer.ErrLockLost))
+		assert.NoError(t, next.Unlock())
+	})
+	t.Run("refresh extends the lock", func(t *testing.T) {
+		lock, err := st.LockTransaction(context.Background(), "refreshed", time.Second)
+		assert.NoError(t, err)
+		assert.NoError(t, lock.Refresh(time.Minute))
+		assert.Equal(t, time.Minute, mr.TTL(KeyPrefix+"lock:refreshed"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+		_, err = st.LockTransaction(ctx, "refreshed", time.Minute)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "waiting should stop when the context is done")
+		assert.NoError(t, lock.Unlock())
+	})
+}
@@ -0,0 +1,63 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// IsReplaceable returns true if the transaction can still be replaced
+// by resubmitting it with a higher gas price.
+//
+// A transaction is replaceable while it is not finalized, its nonce matches
+// the confirmed nonce of the sender and the chain head is not past its expiry block.
+func (t *Transaction) IsReplaceable(currentNonce uint64, chainHead *big.Int) bool {
+	if t.State.IsTerminal() {
+		return false
+	}
+
+	latest, err := t.getLatestTx()
+	if err != nil {
+		return false
+	}
+	if latest.Nonce() != currentNonce {
+		return false
+	}
+
+	if t.Opts.ExpiryBlock != nil && chainHead != nil && chainHead.Cmp(t.Opts.ExpiryBlock) > 0 {
+		return false
+	}
+
+	return true
+}
+
+// CanReplace fetches the confirmed nonce of the transaction sender and
+// the chain head and reports if the transaction is still replaceable.
+func CanReplace(tx Transaction, cl MultichainClient) (bool, error) {
+	nonce, err := cl.NonceAt(tx.ChainID, tx.SenderAddress())
+	if err != nil {
+		return false, fmt.Errorf("failed to get sender nonce: %w", err)
+	}
+
+	head, err := cl.BlockNumber(tx.ChainID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get chain head: %w", err)
+	}
+
+	return tx.IsReplaceable(nonce, new(big.Int).SetUint64(head)), nil
+}
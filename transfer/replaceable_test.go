@@ -0,0 +1,80 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type replaceableClient struct {
+	mockClient
+	nonce uint64
+	head  uint64
+	err   error
+}
+
+func (c *replaceableClient) NonceAt(chainID int64, account common.Address) (uint64, error) {
+	return c.nonce, c.err
+}
+
+func (c *replaceableClient) BlockNumber(chainID int64) (uint64, error) {
+	return c.head, nil
+}
+
+func TestCanReplace(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(5, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(10), []byte{}), 137)
+	tx, err := newTransaction(org, sg.address, defaultOpts())
+	assert.NoError(t, err)
+
+	t.Run("replaceable while nonce is not used", func(t *testing.T) {
+		ok, err := CanReplace(*tx, &replaceableClient{nonce: 5, head: 100})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+	t.Run("not replaceable once sender nonce advanced", func(t *testing.T) {
+		ok, err := CanReplace(*tx, &replaceableClient{nonce: 6, head: 100})
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("not replaceable past expiry block", func(t *testing.T) {
+		expiring := *tx
+		expiring.Opts.Timeout = 0
+		expiring.Opts.ExpiryBlock = big.NewInt(99)
+		ok, err := CanReplace(expiring, &replaceableClient{nonce: 5, head: 100})
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("not replaceable when finalized", func(t *testing.T) {
+		finalized := *tx
+		finalized.State = TxStateSucceed
+		ok, err := CanReplace(finalized, &replaceableClient{nonce: 5, head: 100})
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("client error is returned", func(t *testing.T) {
+		failure := errors.New("boom")
+		_, err := CanReplace(*tx, &replaceableClient{err: failure})
+		assert.ErrorIs(t, err, failure)
+	})
+}
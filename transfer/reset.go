@@ -0,0 +1,127 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrTransactionNotFound is returned if there is no pending transaction with the given unique ID.
+var ErrTransactionNotFound = errors.New("pending transaction not found")
+
+// ResetTransaction restarts bumping of a pending transaction from newOpts.GasPrice.
+//
+// The current watcher is stopped, the transaction is resent with the
+// same nonce at the given gas price and watched again using newOpts.
+// The transaction lock is held while resending, so other incrementor
+// instances sharing the storage can't increase its gas price meanwhile.
+// Nodes reject replacements priced lower than a transaction still in
+// their pool, in which case the error is returned and the transaction
+// is left as is.
+func (i *GasPriceIncremenetor) ResetTransaction(uniqueID string, newOpts TransactionOpts) error {
+	if newOpts.GasPrice == nil || newOpts.GasPrice.Sign() <= 0 {
+		return errors.New("gas price must be provided to reset a transaction")
+	}
+	if err := newOpts.validate(); err != nil {
+		return fmt.Errorf("can't reset transaction, got wrong tx opts: %w", err)
+	}
+
+	tx, err := i.findPending(uniqueID)
+	if err != nil {
+		return err
+	}
+
+	<-i.syncer.txStopWatch(tx)
+
+	reset, err := i.resetLocked(tx, newOpts)
+	if err != nil {
+		i.syncer.txRemoveWatched(tx)
+		return err
+	}
+
+	// Lock is released by now, so the new watcher can acquire it.
+	i.startWatching(reset)
+	return nil
+}
+
+// resetLocked resends the transaction while holding its lock. Waiting
+// for the lock is limited to the lock TTL as other holders refresh it.
+func (i *GasPriceIncremenetor) resetLocked(tx Transaction, newOpts TransactionOpts) (Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), i.lockTTL())
+	defer cancel()
+
+	lock, err := i.storage.LockTransaction(ctx, tx.UniqueID, i.lockTTL())
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to lock transaction for reset: %w", err)
+	}
+	defer func() {
+		if err := lock.Unlock(); err != nil {
+			i.log(tx, fmt.Errorf("failed to unlock transaction: %w", err))
+		}
+	}()
+
+	// Stored state might have changed while the watcher was stopping.
+	latest, err := i.findPending(tx.UniqueID)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	return i.resendWithGasPrice(latest, newOpts)
+}
+
+func (i *GasPriceIncremenetor) resendWithGasPrice(tx Transaction, newOpts TransactionOpts) (Transaction, error) {
+	org, err := tx.getLatestTx()
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	newTx, err := i.signAndSend(tx.rebuiledWithNewGasPrice(org, newOpts.GasPrice), tx.ChainID, tx.SenderAddressHex)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to reset transaction: %w", err)
+	}
+
+	// Resetting is an explicit restart, so state transitions are not validated.
+	tx.Opts = newOpts
+	tx.State = TxStateCreated
+	tx.LatestTx, err = newTx.MarshalJSON()
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to marshal internal transaction object: %w", err)
+	}
+
+	if err := i.upsert(tx); err != nil {
+		return Transaction{}, fmt.Errorf("failed to update transaction after reset: %w", err)
+	}
+
+	return tx, nil
+}
+
+func (i *GasPriceIncremenetor) findPending(uniqueID string) (Transaction, error) {
+	txs, err := i.storage.GetIncrementorTransactionsToCheck(i.signers.getSigners())
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to get transactions to check: %w", err)
+	}
+
+	for _, tx := range txs {
+		if tx.UniqueID == uniqueID && !tx.State.IsTerminal() {
+			return tx, nil
+		}
+	}
+
+	return Transaction{}, fmt.Errorf("unique ID %q: %w", uniqueID, ErrTransactionNotFound)
+}
@@ -0,0 +1,106 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPriceIncrementor_ResetTransaction(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(7, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1000), []byte{}), 137)
+	opts := defaultOpts()
+	opts.MaxPrice = big.NewInt(10000)
+	opts.IncreaseInterval = time.Hour
+	tx, err := newTransaction(org, sg.address, opts)
+	assert.NoError(t, err)
+
+	st := &mockStorage{}
+	assert.NoError(t, st.UpsertIncrementorTransaction(*tx))
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+	defer inc.Stop()
+
+	inc.tryWatch(*tx)
+	inc.syncer.m.Lock()
+	old := inc.syncer.watches[syncerKey(*tx)]
+	inc.syncer.m.Unlock()
+
+	newOpts := defaultOpts()
+	newOpts.GasPrice = big.NewInt(10)
+	assert.NoError(t, inc.ResetTransaction(tx.UniqueID, newOpts))
+
+	select {
+	case <-old.done:
+	default:
+		assert.Fail(t, "old watcher should have exited")
+	}
+	assert.Equal(t, 1, inc.WatchedTxCount())
+
+	latestPrice := func() (*big.Int, uint64) {
+		st.m.Lock()
+		defer st.m.Unlock()
+		latest, err := st.tx.getLatestTx()
+		assert.NoError(t, err)
+		return latest.GasPrice(), latest.Nonce()
+	}
+	price, nonce := latestPrice()
+	assert.Equal(t, big.NewInt(10), price)
+	assert.Equal(t, uint64(7), nonce)
+
+	assert.Eventually(t, func() bool {
+		price, _ := latestPrice()
+		return price.Cmp(big.NewInt(20)) == 0
+	}, time.Second, time.Millisecond*10, "next bump should start from the reset price")
+
+	t.Run("unknown transaction", func(t *testing.T) {
+		err := inc.ResetTransaction("unknown", newOpts)
+		assert.True(t, errors.Is(err, ErrTransactionNotFound))
+	})
+	t.Run("gas price is required", func(t *testing.T) {
+		assert.Error(t, inc.ResetTransaction(tx.UniqueID, defaultOpts()))
+	})
+	t.Run("transaction locked by another instance is not reset", func(t *testing.T) {
+		st := &lockedStorage{}
+		assert.NoError(t, st.UpsertIncrementorTransaction(*tx))
+		bc := &pendingClient{}
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{LockTTL: 50 * time.Millisecond}, st, bc, Signers{sg.address: sg.SignatureFunc})
+		defer inc.Stop()
+
+		err := inc.ResetTransaction(tx.UniqueID, newOpts)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.False(t, bc.sent)
+		assert.Zero(t, inc.WatchedTxCount())
+	})
+}
+
+// lockedStorage never grants locks as if they were held by another instance.
+type lockedStorage struct {
+	mockStorage
+}
+
+func (s *lockedStorage) LockTransaction(ctx context.Context, uniqueID string, ttl time.Duration) (TransactionLock, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
@@ -0,0 +1,128 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// healthCheckChainID is the chain ID the health check transaction is signed for.
+const healthCheckChainID = 1
+
+// ErrSignerUnhealthy is returned if a signer failed to sign the health check transaction.
+type ErrSignerUnhealthy struct {
+	Address string
+}
+
+func (e ErrSignerUnhealthy) Error() string {
+	return fmt.Sprintf("signer %s is unhealthy", e.Address)
+}
+
+// SignerHealthChecker periodically verifies that signers are able to sign
+// transactions and keeps track of suspended signers.
+type SignerHealthChecker struct {
+	interval  time.Duration
+	suspended map[common.Address]time.Time
+	now       func() time.Time
+	m         sync.Mutex
+}
+
+func newSignerHealthChecker(interval time.Duration) *SignerHealthChecker {
+	return &SignerHealthChecker{
+		interval:  interval,
+		suspended: make(map[common.Address]time.Time),
+		now:       time.Now,
+	}
+}
+
+// check signs a dummy transaction with every signer, suspending the ones
+// failing to produce a valid signature until the next check.
+func (h *SignerHealthChecker) check(signers map[common.Address]SignatureFunc) []error {
+	errs := make([]error, 0)
+	for addr, sign := range signers {
+		if err := checkSigner(addr, sign); err != nil {
+			h.suspend(addr, h.now().Add(h.interval))
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func checkSigner(addr common.Address, sign SignatureFunc) error {
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(0), nil)
+	unhealthy := ErrSignerUnhealthy{Address: addr.Hex()}
+
+	signed, err := sign(tx, healthCheckChainID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", unhealthy, err)
+	}
+
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(healthCheckChainID)), signed)
+	if err != nil {
+		return fmt.Errorf("%w: invalid signature: %v", unhealthy, err)
+	}
+	if sender != addr {
+		return fmt.Errorf("%w: signed by %s", unhealthy, sender.Hex())
+	}
+
+	return nil
+}
+
+func (h *SignerHealthChecker) suspend(addr common.Address, until time.Time) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if current, ok := h.suspended[addr]; ok && current.After(until) {
+		return
+	}
+	h.suspended[addr] = until
+}
+
+// isSuspended returns true if the signer is suspended at the moment.
+func (h *SignerHealthChecker) isSuspended(addr common.Address) bool {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	until, ok := h.suspended[addr]
+	if !ok {
+		return false
+	}
+	if !h.now().Before(until) {
+		delete(h.suspended, addr)
+		return false
+	}
+	return true
+}
+
+// SuspendSigner stops picking up new transactions of the given signer and
+// increasing gas price of its already watched ones until the given time.
+func (i *GasPriceIncremenetor) SuspendSigner(addr common.Address, until time.Time) {
+	i.health.suspend(addr, until)
+}
+
+// CheckSigners runs a health check for all signers, suspending unhealthy ones.
+func (i *GasPriceIncremenetor) CheckSigners() {
+	for _, err := range i.health.check(i.signers.all()) {
+		i.log(Transaction{}, err)
+	}
+}
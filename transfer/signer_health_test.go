@@ -0,0 +1,126 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPriceIncrementor_SignerHealthCheck(t *testing.T) {
+	healthy := newSigner()
+	failing := newSigner()
+	wrong := newSigner()
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{SignerHealthCheckInterval: time.Minute}, &mockStorage{}, newClient(nil), Signers{
+		healthy.address: healthy.SignatureFunc,
+		failing.address: func(tx *types.Transaction, chainID int64) (*types.Transaction, error) {
+			return nil, errors.New("hsm disconnected")
+		},
+		wrong.address: healthy.SignatureFunc,
+	})
+
+	unhealthy := make(map[string]struct{})
+	inc.AttachLogFunc(func(tx Transaction, err error) {
+		var e ErrSignerUnhealthy
+		if assert.True(t, errors.As(err, &e)) {
+			unhealthy[e.Address] = struct{}{}
+		}
+	})
+	inc.CheckSigners()
+	assert.Equal(t, map[string]struct{}{failing.address.Hex(): {}, wrong.address.Hex(): {}}, unhealthy)
+
+	watched := func() []common.Address {
+		res := make([]common.Address, 0)
+		inc.process([]Transaction{
+			{UniqueID: "healthy", SenderAddressHex: healthy.address.Hex(), State: TxStateCreated},
+			{UniqueID: "failing", SenderAddressHex: failing.address.Hex(), State: TxStateCreated},
+		}, func(tx Transaction) {
+			res = append(res, tx.SenderAddress())
+		})
+		return res
+	}
+	assert.Equal(t, []common.Address{healthy.address}, watched())
+
+	t.Run("suspension expires", func(t *testing.T) {
+		inc.health.now = func() time.Time { return time.Now().Add(time.Minute) }
+		defer func() { inc.health.now = time.Now }()
+
+		assert.Equal(t, []common.Address{healthy.address, failing.address}, watched())
+	})
+	t.Run("signers can be suspended manually", func(t *testing.T) {
+		inc.SuspendSigner(healthy.address, time.Now().Add(time.Hour))
+		inc.health.now = func() time.Time { return time.Now().Add(time.Minute) }
+		defer func() { inc.health.now = time.Now }()
+
+		assert.Equal(t, []common.Address{failing.address}, watched())
+	})
+}
+
+// pullCountStorage counts pulls of transactions to check.
+type pullCountStorage struct {
+	mockStorage
+	pulls int
+}
+
+func (s *pullCountStorage) GetIncrementorTransactionsToCheck(signers []string) ([]Transaction, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.pulls++
+	return nil, nil
+}
+
+func (s *pullCountStorage) pullCount() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.pulls
+}
+
+func TestGasPriceIncrementor_HealthCheckDoesNotDelayPulls(t *testing.T) {
+	st := &pullCountStorage{}
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{
+		PullInterval:              50 * time.Millisecond,
+		SignerHealthCheckInterval: 10 * time.Millisecond,
+	}, st, newClient(nil), Signers{})
+	go inc.Run()
+	defer inc.Stop()
+
+	assert.Eventually(t, func() bool { return st.pullCount() >= 2 }, time.Second, 10*time.Millisecond)
+}
+
+func TestGasPriceIncrementor_SuspendedSignerIsNotBumped(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+	opts := defaultOpts()
+	opts.Timeout = 200 * time.Millisecond
+	tx, err := newTransaction(org, sg.address, opts)
+	assert.NoError(t, err)
+
+	bc := &pendingClient{}
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, &mockStorage{}, bc, Signers{sg.address: sg.SignatureFunc})
+	defer inc.Stop()
+	inc.SuspendSigner(sg.address, time.Now().Add(time.Hour))
+
+	assert.NoError(t, inc.watchAndIncrement(context.Background(), *tx))
+	assert.False(t, bc.sent, "gas price of a suspended signer transaction should not be increased")
+}
@@ -0,0 +1,98 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrIllegalTransition is returned if a transaction can not move from one state to another.
+type ErrIllegalTransition struct {
+	From, To TransactionState
+}
+
+func (e ErrIllegalTransition) Error() string {
+	return fmt.Sprintf("illegal transaction state transition from %q to %q", e.From, e.To)
+}
+
+// legalTransitions holds all states a transaction can move to from a given state.
+// Transactions pending confirmation can be removed from the chain by a reorg
+// and get their gas price increased again.
+var legalTransitions = map[TransactionState][]TransactionState{
+	TxStateCreated:             {TxStatePriceIncreased, TxStatePendingConfirmation, TxStateFailed, TxStateSucceed},
+	TxStatePriceIncreased:      {TxStatePriceIncreased, TxStatePendingConfirmation, TxStateFailed, TxStateSucceed},
+	TxStatePendingConfirmation: {TxStatePriceIncreased, TxStatePendingConfirmation, TxStateFailed, TxStateSucceed},
+	TxStateFailed:              {},
+	TxStateSucceed:             {},
+}
+
+// ValidateStateTransition returns an error if a transaction is not allowed
+// to move from one state to the other.
+func ValidateStateTransition(from, to TransactionState) error {
+	for _, allowed := range legalTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+
+	return ErrIllegalTransition{From: from, To: to}
+}
+
+// IsTerminal returns true if the transaction state is final and
+// the transaction will no longer be handled.
+func (s TransactionState) IsTerminal() bool {
+	return s == TxStateFailed || s == TxStateSucceed
+}
+
+// stateDescriptions holds human readable descriptions of transaction states.
+var stateDescriptions = map[TransactionState]string{
+	TxStateCreated:             "transaction was created and is waiting to be confirmed",
+	TxStatePriceIncreased:      "gas price was increased and transaction was resubmitted",
+	TxStatePendingConfirmation: "transaction was mined and is waiting for more confirmations",
+	TxStateFailed:              "transaction failed and will not be retried",
+	TxStateSucceed:             "transaction was confirmed successfully",
+}
+
+// Describe returns a human readable description of the state useful for logging.
+func (s TransactionState) Describe() string {
+	desc, ok := stateDescriptions[s]
+	if !ok {
+		desc = fmt.Sprintf("unknown transaction state %q", string(s))
+	}
+	if s.IsTerminal() {
+		desc += " (terminal)"
+	}
+	return desc
+}
+
+// ErrAmbiguousStatus is returned if a blockchain status does not map to a single transaction state.
+var ErrAmbiguousStatus = errors.New("blockchain status does not map to a transaction state")
+
+// ToTxState returns the final transaction state matching the blockchain status.
+//
+// ErrAmbiguousStatus is returned for pending transactions.
+func (s BCTxStatus) ToTxState() (TransactionState, error) {
+	switch s {
+	case StatusSucceeded:
+		return TxStateSucceed, nil
+	case StatusFailed:
+		return TxStateFailed, nil
+	default:
+		return "", fmt.Errorf("status %q: %w", string(s), ErrAmbiguousStatus)
+	}
+}
@@ -0,0 +1,107 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateStateTransition(t *testing.T) {
+	tests := []struct {
+		from, to TransactionState
+		legal    bool
+	}{
+		{TxStateCreated, TxStateCreated, false},
+		{TxStateCreated, TxStatePriceIncreased, true},
+		{TxStateCreated, TxStateFailed, true},
+		{TxStateCreated, TxStateSucceed, true},
+
+		{TxStatePriceIncreased, TxStateCreated, false},
+		{TxStatePriceIncreased, TxStatePriceIncreased, true},
+		{TxStatePriceIncreased, TxStateFailed, true},
+		{TxStatePriceIncreased, TxStateSucceed, true},
+
+		{TxStatePendingConfirmation, TxStateCreated, false},
+		{TxStatePendingConfirmation, TxStatePriceIncreased, true},
+		{TxStatePendingConfirmation, TxStatePendingConfirmation, true},
+		{TxStatePendingConfirmation, TxStateSucceed, true},
+
+		{TxStateFailed, TxStateCreated, false},
+		{TxStateFailed, TxStatePriceIncreased, false},
+		{TxStateFailed, TxStateFailed, false},
+		{TxStateFailed, TxStateSucceed, false},
+
+		{TxStateSucceed, TxStateCreated, false},
+		{TxStateSucceed, TxStatePriceIncreased, false},
+		{TxStateSucceed, TxStateFailed, false},
+		{TxStateSucceed, TxStateSucceed, false},
+
+		{"", TxStateFailed, false},
+		{"unknown", TxStateSucceed, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			err := ValidateStateTransition(tt.from, tt.to)
+			if tt.legal {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, ErrIllegalTransition{From: tt.from, To: tt.to}, err)
+		})
+	}
+}
+
+func TestTransactionState_IsTerminal(t *testing.T) {
+	assert.False(t, TxStateCreated.IsTerminal())
+	assert.False(t, TxStatePriceIncreased.IsTerminal())
+	assert.True(t, TxStateFailed.IsTerminal())
+	assert.True(t, TxStateSucceed.IsTerminal())
+}
+
+func TestTransactionState_Describe(t *testing.T) {
+	tests := []struct {
+		state TransactionState
+		want  string
+	}{
+		{TxStateCreated, "transaction was created and is waiting to be confirmed"},
+		{TxStatePriceIncreased, "gas price was increased and transaction was resubmitted"},
+		{TxStateFailed, "transaction failed and will not be retried (terminal)"},
+		{TxStateSucceed, "transaction was confirmed successfully (terminal)"},
+		{TransactionState("bogus"), `unknown transaction state "bogus"`},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.state), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.state.Describe())
+		})
+	}
+}
+
+func TestBCTxStatus_ToTxState(t *testing.T) {
+	state, err := StatusSucceeded.ToTxState()
+	assert.NoError(t, err)
+	assert.Equal(t, TxStateSucceed, state)
+
+	state, err = StatusFailed.ToTxState()
+	assert.NoError(t, err)
+	assert.Equal(t, TxStateFailed, state)
+
+	_, err = StatusPending.ToTxState()
+	assert.True(t, errors.Is(err, ErrAmbiguousStatus))
+}
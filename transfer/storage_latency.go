@@ -0,0 +1,138 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultStorageLatencyWindow is how long storage write latencies are kept by default.
+const DefaultStorageLatencyWindow = time.Minute
+
+// maxLatencySamples caps the amount of kept storage write latencies.
+const maxLatencySamples = 10000
+
+// StorageLatencySnapshot holds storage write latency percentiles.
+type StorageLatencySnapshot struct {
+	P50, P99, P999 time.Duration
+	// WriteCount is the total amount of recorded writes.
+	WriteCount uint64
+}
+
+// StorageLatencyMonitor keeps a rolling window of storage write latencies.
+type StorageLatencyMonitor struct {
+	window  time.Duration
+	samples []latencySample
+	count   uint64
+	now     func() time.Time
+	m       sync.Mutex
+}
+
+type latencySample struct {
+	at      time.Time
+	latency time.Duration
+}
+
+func newStorageLatencyMonitor(window time.Duration) *StorageLatencyMonitor {
+	if window <= 0 {
+		window = DefaultStorageLatencyWindow
+	}
+
+	return &StorageLatencyMonitor{
+		window: window,
+		now:    time.Now,
+	}
+}
+
+func (s *StorageLatencyMonitor) record(latency time.Duration) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.count++
+	s.samples = append(s.samples, latencySample{at: s.now(), latency: latency})
+	if len(s.samples) > maxLatencySamples {
+		s.samples = s.samples[len(s.samples)-maxLatencySamples:]
+	}
+}
+
+// Snapshot returns latency percentiles of writes within the window.
+func (s *StorageLatencyMonitor) Snapshot() StorageLatencySnapshot {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	cutoff := s.now().Add(-s.window)
+	first := sort.Search(len(s.samples), func(i int) bool {
+		return s.samples[i].at.After(cutoff)
+	})
+	s.samples = s.samples[first:]
+
+	latencies := make([]time.Duration, len(s.samples))
+	for i, sample := range s.samples {
+		latencies[i] = sample.latency
+	}
+	sort.Slice(latencies, func(a, b int) bool { return latencies[a] < latencies[b] })
+
+	return StorageLatencySnapshot{
+		P50:        percentile(latencies, 0.5),
+		P99:        percentile(latencies, 0.99),
+		P999:       percentile(latencies, 0.999),
+		WriteCount: s.count,
+	}
+}
+
+// percentile returns the q-th percentile of sorted latencies using the nearest rank.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// StorageLatencyStats returns storage write latency statistics.
+func (i *GasPriceIncremenetor) StorageLatencyStats() StorageLatencySnapshot {
+	return i.latency.Snapshot()
+}
+
+// upsert stores the transaction recording the write latency.
+func (i *GasPriceIncremenetor) upsert(tx Transaction) error {
+	start := time.Now()
+	err := i.storage.UpsertIncrementorTransaction(tx)
+	i.latency.record(time.Since(start))
+	return err
+}
+
+// storageBackpressure returns an error if storage writes are slower than allowed
+// and no new transactions should be watched.
+func (i *GasPriceIncremenetor) storageBackpressure() error {
+	if i.cfg.StorageLatencyThreshold <= 0 {
+		return nil
+	}
+
+	if p99 := i.latency.Snapshot().P99; p99 > i.cfg.StorageLatencyThreshold {
+		return fmt.Errorf("storage write latency p99 of %s is above %s, not watching new transactions", p99, i.cfg.StorageLatencyThreshold)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slowStorage delays all transaction writes.
+type slowStorage struct {
+	mockStorage
+	delay time.Duration
+}
+
+func (s *slowStorage) UpsertIncrementorTransaction(tx Transaction) error {
+	time.Sleep(s.delay)
+	return s.mockStorage.UpsertIncrementorTransaction(tx)
+}
+
+func TestGasPriceIncrementor_StorageBackpressure(t *testing.T) {
+	st := &slowStorage{delay: time.Millisecond * 500}
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{StorageLatencyThreshold: time.Millisecond * 100}, st, newClient(nil), Signers{})
+	defer inc.Stop()
+
+	opts := defaultOpts()
+	opts.IncreaseInterval = time.Hour
+	opts.CheckInterval = time.Hour
+	tx := Transaction{UniqueID: "slow", State: TxStateCreated, Opts: opts}
+
+	assert.NoError(t, inc.transactionFailed(tx))
+	stats := inc.StorageLatencyStats()
+	assert.Equal(t, uint64(1), stats.WriteCount)
+	assert.True(t, stats.P99 >= st.delay)
+
+	inc.tryWatch(tx)
+	assert.Equal(t, 0, inc.WatchedTxCount(), "new transactions should not be watched on high storage latency")
+
+	st.delay = 0
+	for n := 0; n < 100; n++ {
+		assert.NoError(t, inc.upsert(tx))
+	}
+	stats = inc.StorageLatencyStats()
+	assert.Equal(t, uint64(101), stats.WriteCount)
+	assert.True(t, stats.P99 < time.Millisecond*100)
+	assert.True(t, stats.P999 >= time.Millisecond*500)
+
+	inc.tryWatch(tx)
+	assert.Equal(t, 1, inc.WatchedTxCount())
+
+	t.Run("old samples leave the window", func(t *testing.T) {
+		m := newStorageLatencyMonitor(time.Minute)
+		now := time.Now()
+		m.now = func() time.Time { return now }
+		m.record(time.Second)
+		assert.Equal(t, time.Second, m.Snapshot().P50)
+
+		now = now.Add(time.Minute)
+		assert.Equal(t, StorageLatencySnapshot{WriteCount: 1}, m.Snapshot())
+	})
+}
@@ -0,0 +1,92 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"fmt"
+	"time"
+)
+
+// thrashingSlowedIntervals is the amount of increase intervals
+// slowed down once a transaction is found thrashing.
+const thrashingSlowedIntervals = 2
+
+// ErrThrashingWarning is logged when a transaction is bumped more often than the ThrashingThreshold.
+type ErrThrashingWarning struct {
+	UniqueID  string
+	BumpRate  float64
+	Threshold float64
+}
+
+func (e ErrThrashingWarning) Error() string {
+	return fmt.Sprintf("transaction %s is thrashing, bumped %.2f times per minute, threshold is %.2f", e.UniqueID, e.BumpRate, e.Threshold)
+}
+
+// BumpRate returns the amount of gas price increases per minute
+// of a watched transaction since watching it started.
+//
+// ErrTransactionNotFound is returned if the transaction is not being watched.
+func (i *GasPriceIncremenetor) BumpRate(uniqueID string) (float64, error) {
+	tx, ok := i.syncer.txByUniqueID(uniqueID)
+	if !ok {
+		return 0, fmt.Errorf("transaction %q: %w", uniqueID, ErrTransactionNotFound)
+	}
+
+	rate, ok := i.bumpRate(tx)
+	if !ok {
+		return 0, fmt.Errorf("transaction %q: %w", uniqueID, ErrTransactionNotFound)
+	}
+	return rate, nil
+}
+
+// bumpRate returns the bump rate of the watched transaction
+// and false if it is not being watched.
+func (i *GasPriceIncremenetor) bumpRate(tx Transaction) (float64, bool) {
+	bumps, startedAt, ok := i.syncer.bumpStats(tx)
+	if !ok {
+		return 0, false
+	}
+
+	elapsed := time.Since(startedAt)
+	if bumps == 0 || elapsed <= 0 {
+		return 0, true
+	}
+
+	return float64(bumps) / elapsed.Minutes(), true
+}
+
+// checkThrashing logs an ErrThrashingWarning and returns true
+// if the transaction bump rate is above the ThrashingThreshold.
+func (i *GasPriceIncremenetor) checkThrashing(tx Transaction) bool {
+	if i.cfg.ThrashingThreshold <= 0 {
+		return false
+	}
+
+	rate, ok := i.bumpRate(tx)
+	if !ok || rate <= i.cfg.ThrashingThreshold {
+		return false
+	}
+
+	i.log(tx, ErrThrashingWarning{UniqueID: tx.UniqueID, BumpRate: rate, Threshold: i.cfg.ThrashingThreshold})
+	return true
+}
+
+// replaceTicker stops the ticker and returns a new one with the given interval.
+func replaceTicker(t *time.Ticker, d time.Duration) *time.Ticker {
+	t.Stop()
+	return time.NewTicker(d)
+}
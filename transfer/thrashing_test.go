@@ -0,0 +1,117 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// sendTimesClient keeps transactions pending and records when transactions are sent.
+type sendTimesClient struct {
+	pendingClient
+	times []time.Time
+	m     sync.Mutex
+}
+
+func (c *sendTimesClient) SendTransaction(chainID int64, tx *types.Transaction) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.times = append(c.times, time.Now())
+	return c.pendingClient.SendTransaction(chainID, tx)
+}
+
+func TestGasPriceIncrementor_BumpRate(t *testing.T) {
+	tx := Transaction{UniqueID: "thrashing", ChainID: 137}
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, &mockStorage{}, newClient(nil), Signers{})
+	defer inc.Stop()
+
+	_, err := inc.BumpRate(tx.UniqueID)
+	assert.True(t, errors.Is(err, ErrTransactionNotFound))
+
+	// Watched for 20 seconds with a bump every 2 seconds.
+	inc.syncer.txMarkBeingWatched(tx)
+	inc.syncer.txRestoreStartedAt(tx, time.Now().Add(-20*time.Second))
+	rate, err := inc.BumpRate(tx.UniqueID)
+	assert.NoError(t, err)
+	assert.Zero(t, rate)
+
+	for n := 0; n < 10; n++ {
+		inc.syncer.txBumped(tx)
+	}
+	rate, err = inc.BumpRate(tx.UniqueID)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.5, rate/60, 0.01, "expected 0.5 bumps per second")
+	assert.False(t, inc.checkThrashing(tx), "threshold is disabled")
+
+	inc.cfg.ThrashingThreshold = 20
+	var warnings []ErrThrashingWarning
+	inc.AttachLogFunc(func(tx Transaction, err error) {
+		var warning ErrThrashingWarning
+		if errors.As(err, &warning) {
+			warnings = append(warnings, warning)
+		}
+	})
+	assert.True(t, inc.checkThrashing(tx))
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, "thrashing", warnings[0].UniqueID)
+	assert.InDelta(t, 30, warnings[0].BumpRate, 0.5)
+}
+
+func TestGasPriceIncrementor_ThrashingSlowsDown(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+	opts := defaultOpts()
+	opts.IncreaseInterval = 40 * time.Millisecond
+	opts.Timeout = 250 * time.Millisecond
+	tx, err := newTransaction(org, sg.address, opts)
+	assert.NoError(t, err)
+
+	bc := &sendTimesClient{}
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{ThrashingThreshold: 1}, &mockStorage{}, bc, Signers{sg.address: sg.SignatureFunc})
+	defer inc.Stop()
+
+	var warned int
+	var m sync.Mutex
+	inc.AttachLogFunc(func(tx Transaction, err error) {
+		if errors.As(err, &ErrThrashingWarning{}) {
+			m.Lock()
+			defer m.Unlock()
+			warned++
+		}
+	})
+
+	inc.syncer.txMarkBeingWatched(*tx)
+	assert.NoError(t, inc.watchAndIncrement(context.Background(), *tx))
+
+	bc.m.Lock()
+	defer bc.m.Unlock()
+	m.Lock()
+	defer m.Unlock()
+	assert.True(t, warned > 0)
+	assert.True(t, len(bc.times) >= 2)
+	gap := bc.times[1].Sub(bc.times[0])
+	assert.True(t, gap >= 55*time.Millisecond, "interval should be slowed down after thrashing, got %s", gap)
+}
@@ -0,0 +1,86 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPriceIncrementor_WarnBeforeTimeout(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+	opts := defaultOpts()
+	opts.IncreaseInterval = time.Hour
+	opts.Timeout = 300 * time.Millisecond
+	opts.WarnBeforeTimeout = 200 * time.Millisecond
+	tx, err := newTransaction(org, sg.address, opts)
+	assert.NoError(t, err)
+
+	st := &mockStorage{}
+	assert.NoError(t, st.UpsertIncrementorTransaction(*tx))
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, st, &pendingClient{}, Signers{sg.address: sg.SignatureFunc})
+	defer inc.Stop()
+
+	var warnedAfter time.Duration
+	var warnings []ErrTimeoutWarning
+	var m sync.Mutex
+	start := time.Now()
+	inc.AttachLogFunc(func(tx Transaction, err error) {
+		var warning ErrTimeoutWarning
+		if errors.As(err, &warning) {
+			m.Lock()
+			defer m.Unlock()
+			warnedAfter = time.Since(start)
+			warnings = append(warnings, warning)
+		}
+	})
+
+	assert.NoError(t, inc.watchAndIncrement(context.Background(), *tx))
+	elapsed := time.Since(start)
+
+	m.Lock()
+	defer m.Unlock()
+	assert.Equal(t, []ErrTimeoutWarning{{UniqueID: tx.UniqueID, Remaining: 200 * time.Millisecond}}, warnings)
+	assert.True(t, warnedAfter >= 100*time.Millisecond && warnedAfter < 250*time.Millisecond, "warned after %s", warnedAfter)
+	assert.True(t, elapsed >= 300*time.Millisecond, "timed out after %s", elapsed)
+	assert.Equal(t, TxStateFailed, st.tx.State, "timeout should still fail the transaction")
+}
+
+func TestTransactionOpts_WarnBeforeTimeout(t *testing.T) {
+	opts := defaultOpts()
+	opts.WarnBeforeTimeout = opts.Timeout
+	assert.Error(t, opts.validate())
+
+	opts.WarnBeforeTimeout = -time.Second
+	assert.Error(t, opts.validate())
+
+	opts.WarnBeforeTimeout = opts.Timeout / 2
+	assert.NoError(t, opts.validate())
+
+	opts.Timeout = 0
+	opts.ExpiryBlock = big.NewInt(10)
+	assert.Error(t, opts.validate(), "warning requires a timeout")
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -35,6 +36,9 @@ const (
 	// TxStatePriceIncreased is given to transactions which have received
 	// a price increase.
 	TxStatePriceIncreased TransactionState = "priceIncreased"
+	// TxStatePendingConfirmation is given to transactions which were mined
+	// but did not reach the required confirmations before timing out.
+	TxStatePendingConfirmation TransactionState = "pendingConfirmation"
 	// TxStateFailed is given to transactions which have
 	// failed and should not be retried anymore.
 	TxStateFailed TransactionState = "failed"
@@ -50,11 +54,36 @@ type Transaction struct {
 	Opts     TransactionOpts
 	State    TransactionState
 
-	OrignalHashHex   string
+	OrignalHashHex string
+	// SenderAddressHex is the hex encoded address of the sender.
+	//
+	// Deprecated: use SenderAddress and SetSenderAddress instead.
+	// The field is kept for serialization compatibility.
 	SenderAddressHex string
 	ChainID          int64
+	// CreatedAt is the time the transaction was initially inserted.
+	CreatedAt time.Time
+	// FinalizedAt is the time the transaction has failed or succeeded.
+	FinalizedAt time.Time
 
 	LatestTx []byte
+
+	// Metadata holds operator defined context for the transaction.
+	// It is not used by the incrementor itself and should be persisted as is.
+	Metadata map[string]string
+}
+
+// TransactionMeta is an option used to attach metadata to a transaction.
+type TransactionMeta func(tx *Transaction)
+
+// WithMetadata attaches the given key value pair to the transaction metadata.
+func WithMetadata(key, value string) TransactionMeta {
+	return func(tx *Transaction) {
+		if tx.Metadata == nil {
+			tx.Metadata = make(map[string]string)
+		}
+		tx.Metadata[key] = value
+	}
 }
 
 // TransactionOpts are provided when creating a new transaction.
@@ -70,6 +99,40 @@ type TransactionOpts struct {
 	// can be given to invalidate a transaction and mark it as failed
 	// after the given time.Time.
 	ValidUntil *time.Time
+
+	// ExpiryBlock is an alternative to Timeout. If given the transaction
+	// is marked as failed once the chain advances past this block
+	// without the transaction being confirmed.
+	// Only one of Timeout or ExpiryBlock can be given.
+	ExpiryBlock *big.Int
+
+	// MinGasPrice overrides the incrementor configured minimal gas price.
+	MinGasPrice *big.Int
+
+	// ChainID is the chain the transaction is meant for. If given, InsertInitial
+	// rejects transactions signed for another chain with ErrChainIDMismatch.
+	ChainID int64
+
+	// GasPrice is the price a transaction is restarted from by ResetTransaction.
+	// It is not used otherwise.
+	GasPrice *big.Int
+
+	// SpeedUp makes gas price bumps keep the original transaction type
+	// and type specific fields instead of replacing it with a legacy transaction.
+	SpeedUp bool
+
+	// WarnBeforeTimeout is optional and makes the incrementor log an
+	// ErrTimeoutWarning the given duration before the transaction times out.
+	// It must be shorter than Timeout.
+	WarnBeforeTimeout time.Duration
+
+	// RequiredConfirmations is the amount of blocks that have to be mined on top of
+	// the block the transaction is included in before it is marked as succeed.
+	// If the timeout is reached while waiting for confirmations, the transaction
+	// is marked with TxStatePendingConfirmation and checked again later, until
+	// another Timeout passes. Transactions removed from the chain by a reorg
+	// get their gas price increased again.
+	RequiredConfirmations uint64
 }
 
 // TransactionUniqueID returns a unique ID for a transaction.
@@ -84,8 +147,11 @@ func (t *TransactionOpts) validate() error {
 	if t.MaxPrice == nil || t.MaxPrice.Cmp(big.NewInt(0)) <= 0 {
 		return errors.New("max price has to be greater than 0")
 	}
-	if t.Timeout <= 0 {
-		return errors.New("timeout value must be provided")
+	if (t.Timeout > 0) == (t.ExpiryBlock != nil) {
+		return errors.New("exactly one of timeout or expiry block must be provided")
+	}
+	if t.Timeout < 0 {
+		return errors.New("timeout value must be positive")
 	}
 	if t.IncreaseInterval <= 0 {
 		return errors.New("increase interval value must be provided")
@@ -96,11 +162,17 @@ func (t *TransactionOpts) validate() error {
 	if t.ValidUntil != nil && t.ValidUntil.Before(time.Now()) {
 		return errors.New("given 'ValidUntil' must be in the future")
 	}
+	if t.WarnBeforeTimeout < 0 {
+		return errors.New("warn before timeout value must be positive")
+	}
+	if t.WarnBeforeTimeout > 0 && t.WarnBeforeTimeout >= t.Timeout {
+		return errors.New("warn before timeout must be shorter than timeout")
+	}
 
 	return nil
 }
 
-func newTransaction(tx *types.Transaction, senderAddress common.Address, opts TransactionOpts) (*Transaction, error) {
+func newTransaction(tx *types.Transaction, senderAddress common.Address, opts TransactionOpts, metas ...TransactionMeta) (*Transaction, error) {
 	hash := tx.Hash().Hex()
 
 	marshaled, err := tx.MarshalJSON()
@@ -108,15 +180,62 @@ func newTransaction(tx *types.Transaction, senderAddress common.Address, opts Tr
 		return nil, err
 	}
 
-	return &Transaction{
-		UniqueID:         TransactionUniqueID(hash, tx.ChainId().Int64()),
-		Opts:             opts,
-		State:            TxStateCreated,
-		OrignalHashHex:   hash,
-		SenderAddressHex: senderAddress.Hex(),
-		ChainID:          tx.ChainId().Int64(),
-		LatestTx:         marshaled,
-	}, nil
+	newTx := &Transaction{
+		UniqueID:       TransactionUniqueID(hash, tx.ChainId().Int64()),
+		Opts:           opts,
+		State:          TxStateCreated,
+		OrignalHashHex: hash,
+		ChainID:        tx.ChainId().Int64(),
+		CreatedAt:      time.Now().UTC(),
+		LatestTx:       marshaled,
+	}
+	newTx.SetSenderAddress(senderAddress)
+	for _, meta := range metas {
+		meta(newTx)
+	}
+
+	return newTx, nil
+}
+
+// ErrInvalidSenderAddress is returned if the transaction sender is not a valid address.
+var ErrInvalidSenderAddress = errors.New("invalid sender address")
+
+// SenderAddress returns the address of the transaction sender.
+func (t *Transaction) SenderAddress() common.Address {
+	return common.HexToAddress(t.SenderAddressHex)
+}
+
+// SetSenderAddress sets the transaction sender stored as lowercase hex.
+func (t *Transaction) SetSenderAddress(addr common.Address) {
+	t.SenderAddressHex = strings.ToLower(addr.Hex())
+}
+
+// ValidateSenderAddress returns an error if the stored sender
+// is not a valid, non zero ethereum address.
+func (t *Transaction) ValidateSenderAddress() error {
+	if !common.IsHexAddress(t.SenderAddressHex) {
+		return fmt.Errorf("%q: %w", t.SenderAddressHex, ErrInvalidSenderAddress)
+	}
+	if t.SenderAddress() == (common.Address{}) {
+		return fmt.Errorf("zero address: %w", ErrInvalidSenderAddress)
+	}
+
+	return nil
+}
+
+// Deadline returns the time by which the transaction is expected to be confirmed.
+// Transactions expiring at a block have no time deadline and the zero time is returned.
+func (t *Transaction) Deadline() time.Time {
+	if t.Opts.ExpiryBlock != nil {
+		return time.Time{}
+	}
+	return t.CreatedAt.Add(t.Opts.Timeout)
+}
+
+// confirmationDeadline returns the time by which a transaction pending
+// confirmation has to reach its required confirmations.
+func (t *Transaction) confirmationDeadline() time.Time {
+	return t.CreatedAt.Add(2 * t.Opts.Timeout)
 }
 
 func (t *Transaction) isExpired() bool {
@@ -133,12 +252,47 @@ func (t *Transaction) getLatestTx() (*types.Transaction, error) {
 }
 
 func (t *Transaction) rebuiledWithNewGasPrice(tx *types.Transaction, newGasPrice *big.Int) *types.Transaction {
+	return t.rebuiledWithData(tx, newGasPrice, tx.Data())
+}
+
+// rebuiledWithData rebuilds the transaction with a new gas price and data.
+func (t *Transaction) rebuiledWithData(tx *types.Transaction, newGasPrice *big.Int, data []byte) *types.Transaction {
+	if t.Opts.SpeedUp {
+		return speedUpTx(tx, newGasPrice, data)
+	}
+
 	return types.NewTransaction(
 		tx.Nonce(),
 		*tx.To(),
 		tx.Value(),
 		tx.Gas(),
 		newGasPrice,
-		tx.Data(),
+		data,
 	)
 }
+
+// speedUpTx returns a copy of the transaction with a new gas price and data
+// keeping its type, recipient, value and nonce.
+func speedUpTx(tx *types.Transaction, newGasPrice *big.Int, data []byte) *types.Transaction {
+	if tx.Type() == types.AccessListTxType {
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasPrice:   newGasPrice,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       data,
+			AccessList: tx.AccessList(),
+		})
+	}
+
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    tx.Nonce(),
+		GasPrice: newGasPrice,
+		Gas:      tx.Gas(),
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Data:     data,
+	})
+}
@@ -0,0 +1,89 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_SenderAddress(t *testing.T) {
+	addr := common.HexToAddress("0xF53aCDd584ccb85eE4EC1590007aD3c16FDFF057")
+
+	tx := Transaction{}
+	tx.SetSenderAddress(addr)
+	assert.Equal(t, "0xf53acdd584ccb85ee4ec1590007ad3c16fdff057", tx.SenderAddressHex)
+	assert.Equal(t, addr, tx.SenderAddress())
+	assert.NoError(t, tx.ValidateSenderAddress())
+
+	for _, invalid := range []string{"", "0x123", "not an address", "0x0000000000000000000000000000000000000000"} {
+		tx := Transaction{SenderAddressHex: invalid}
+		assert.ErrorIs(t, tx.ValidateSenderAddress(), ErrInvalidSenderAddress, invalid)
+	}
+
+	sg := newSigner()
+	created, err := newTransaction(sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil), 137), sg.address, defaultOpts())
+	assert.NoError(t, err)
+	assert.Equal(t, strings.ToLower(sg.address.Hex()), created.SenderAddressHex, "new transactions should store the sender same as SetSenderAddress")
+}
+
+func TestTransaction_RebuildSpeedUp(t *testing.T) {
+	to := common.HexToAddress("0x1")
+	tx := Transaction{Opts: TransactionOpts{SpeedUp: true}}
+
+	t.Run("legacy transaction keeps data and nonce", func(t *testing.T) {
+		org := types.NewTransaction(7, to, big.NewInt(3), 21000, big.NewInt(10), []byte{1, 2, 3})
+
+		rebuilt := tx.rebuiledWithNewGasPrice(org, big.NewInt(20))
+		assert.Equal(t, uint8(types.LegacyTxType), rebuilt.Type())
+		assert.Equal(t, org.Nonce(), rebuilt.Nonce())
+		assert.Equal(t, org.Data(), rebuilt.Data())
+		assert.Equal(t, org.To(), rebuilt.To())
+		assert.Equal(t, org.Value(), rebuilt.Value())
+		assert.Equal(t, org.Gas(), rebuilt.Gas())
+		assert.Equal(t, big.NewInt(20), rebuilt.GasPrice())
+	})
+	t.Run("access list transaction keeps its type", func(t *testing.T) {
+		accessList := types.AccessList{{Address: to, StorageKeys: []common.Hash{common.HexToHash("0x2")}}}
+		org := types.NewTx(&types.AccessListTx{
+			ChainID:    big.NewInt(137),
+			Nonce:      7,
+			GasPrice:   big.NewInt(10),
+			Gas:        21000,
+			To:         &to,
+			Value:      big.NewInt(3),
+			Data:       []byte{1, 2, 3},
+			AccessList: accessList,
+		})
+
+		rebuilt := tx.rebuiledWithNewGasPrice(org, big.NewInt(20))
+		assert.Equal(t, uint8(types.AccessListTxType), rebuilt.Type())
+		assert.Equal(t, accessList, rebuilt.AccessList())
+		assert.Equal(t, "137", rebuilt.ChainId().String())
+		assert.Equal(t, org.Nonce(), rebuilt.Nonce())
+		assert.Equal(t, org.Data(), rebuilt.Data())
+		assert.Equal(t, big.NewInt(20), rebuilt.GasPrice())
+
+		replaced := (&Transaction{}).rebuiledWithNewGasPrice(org, big.NewInt(20))
+		assert.Equal(t, uint8(types.LegacyTxType), replaced.Type(), "replacement should stay legacy")
+	})
+}
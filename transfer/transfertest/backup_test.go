@@ -0,0 +1,117 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfertest
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/transfer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "export.ndjson")
+
+	created := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	validUntil := created.Add(24 * time.Hour)
+	newTx := func(hash string, chainID int64, state transfer.TransactionState, createdAt time.Time) transfer.Transaction {
+		tx := transfer.Transaction{
+			UniqueID:       transfer.TransactionUniqueID(hash, chainID),
+			OrignalHashHex: hash,
+			State:          state,
+			ChainID:        chainID,
+			CreatedAt:      createdAt,
+			LatestTx:       []byte(`{"nonce":"0x1"}`),
+			Opts: transfer.TransactionOpts{
+				PriceMultiplier:       1.5,
+				MaxPrice:              big.NewInt(1000),
+				Timeout:               time.Hour,
+				IncreaseInterval:      time.Minute,
+				CheckInterval:         time.Second,
+				ValidUntil:            &validUntil,
+				MinGasPrice:           big.NewInt(30),
+				RequiredConfirmations: 3,
+			},
+			Metadata: map[string]string{"order": hash, "empty": ""},
+		}
+		tx.SetSenderAddress(common.HexToAddress("0x1"))
+		return tx
+	}
+
+	finalized := newTx("0xa", 137, transfer.TxStateSucceed, created)
+	finalized.FinalizedAt = created.Add(time.Minute)
+	txs := []transfer.Transaction{
+		finalized,
+		newTx("0xb", 137, transfer.TxStatePriceIncreased, created.Add(time.Second)),
+	}
+
+	st := NewInMemoryStorage()
+	for _, tx := range txs {
+		assert.NoError(t, st.UpsertIncrementorTransaction(tx))
+	}
+	assert.NoError(t, st.UpsertIncrementorTransaction(newTx("0xc", 1, transfer.TxStateCreated, created)))
+
+	assert.NoError(t, transfer.ExportToFile(st, path, 137))
+
+	warnings, err := transfer.ValidateExportFile(path)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	imported := NewInMemoryStorage()
+	count, err := transfer.ImportFromFile(imported, path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2, imported.Len())
+
+	got, err := imported.GetIncrementorTransactionsByTimeRange(created, created.Add(time.Hour), 137)
+	assert.NoError(t, err)
+	assert.Equal(t, txs, got)
+
+	t.Run("validation reports broken lines", func(t *testing.T) {
+		broken := filepath.Join(dir, "broken.ndjson")
+		data := `{"UniqueID":"0xa|137","ChainID":137}
+not json
+
+{"ChainID":137}
+{"UniqueID":"0xa|137","ChainID":137}
+{"UniqueID":"0xd|1","ChainID":137}
+{"UniqueID":"0xe","ChainID":137}
+`
+		assert.NoError(t, ioutil.WriteFile(broken, []byte(data), 0600))
+
+		warnings, err := transfer.ValidateExportFile(broken)
+		assert.NoError(t, err)
+		assert.Len(t, warnings, 5)
+
+		st := NewInMemoryStorage()
+		count, err := transfer.ImportFromFile(st, broken)
+		assert.Error(t, err)
+		assert.Equal(t, 1, count)
+
+		_, err = transfer.ValidateExportFile(filepath.Join(dir, "missing.ndjson"))
+		assert.Error(t, err)
+	})
+}
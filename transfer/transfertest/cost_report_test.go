@@ -0,0 +1,127 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfertest
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/payments/transfer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPriceIncrementor_CostReport(t *testing.T) {
+	var signers TestSignerFactory
+	alice, bob := signers.MustGenerate(), signers.MustGenerate()
+	start := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	st := NewInMemoryStorage()
+	receipts := NewInjectableReceiptFetcher(nil)
+	nonce := uint64(0)
+	// Transactions are finalized a minute after creation.
+	insert := func(id string, sender common.Address, createdAt time.Time, state transfer.TransactionState, gasPrice int64, gasUsed uint64) {
+		nonce++
+		signed, err := signers.SignWithAddress(sender, types.NewTransaction(nonce, common.HexToAddress("0x1"), big.NewInt(1), 21000, big.NewInt(gasPrice), nil), 137)
+		assert.NoError(t, err)
+		latest, err := signed.MarshalJSON()
+		assert.NoError(t, err)
+
+		tx := transfer.Transaction{
+			UniqueID:    id,
+			State:       state,
+			ChainID:     137,
+			CreatedAt:   createdAt,
+			FinalizedAt: createdAt.Add(time.Minute),
+			LatestTx:    latest,
+			Metadata:    map[string]string{"customer": id},
+		}
+		tx.SetSenderAddress(sender)
+		assert.NoError(t, st.UpsertIncrementorTransaction(tx))
+
+		if gasUsed == 0 {
+			receipts.Inject(signed.Hash(), nil, false, ethereum.NotFound)
+			return
+		}
+		if id == "bob-rpc-error" {
+			receipts.Inject(signed.Hash(), nil, false, errors.New("rpc unavailable"))
+			return
+		}
+		receipts.Inject(signed.Hash(), &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: gasUsed}, false, nil)
+	}
+
+	insert("alice-1", alice, start, transfer.TxStateSucceed, 10, 21000)
+	insert("alice-2", alice, start.Add(10*time.Minute), transfer.TxStateFailed, 20, 30000)
+	insert("bob-1", bob, start.Add(20*time.Minute), transfer.TxStateSucceed, 5, 50000)
+	insert("bob-never-mined", bob, start.Add(30*time.Minute), transfer.TxStateFailed, 5, 0)
+	insert("bob-pending", bob, start.Add(40*time.Minute), transfer.TxStatePriceIncreased, 5, 50000)
+	insert("bob-rpc-error", bob, start.Add(50*time.Minute), transfer.TxStateSucceed, 5, 50000)
+	insert("alice-created-before", alice, start.Add(-time.Minute), transfer.TxStateSucceed, 10, 21000)
+	insert("alice-finalized-before", alice, start.Add(-2*time.Minute), transfer.TxStateSucceed, 10, 21000)
+	insert("alice-finalized-after", alice, start.Add(time.Hour-30*time.Second), transfer.TxStateSucceed, 10, 21000)
+
+	inc := transfer.NewGasPriceIncremenetor(transfer.GasIncrementorConfig{ReceiptFetcher: receipts}, st, nil, signers.Signers())
+
+	entries, err := inc.TransactionCostReport(start, start.Add(time.Hour), 137)
+	assert.NoError(t, err)
+	costs := make(map[string]string)
+	byID := make(map[string]transfer.CostEntry)
+	for _, entry := range entries {
+		costs[entry.UniqueID] = entry.TotalCost.String()
+		byID[entry.UniqueID] = entry
+	}
+	assert.Equal(t, map[string]string{
+		"alice-created-before": "210000",
+		"alice-1":              "210000",
+		"alice-2":              "600000",
+		"bob-1":                "250000",
+		"bob-never-mined":      "0",
+		"bob-rpc-error":        "0",
+	}, costs, "transactions should be filtered by finalization time")
+	assert.Equal(t, "alice-created-before", entries[0].UniqueID)
+	assert.Equal(t, "bob-rpc-error", entries[len(entries)-1].UniqueID)
+	assert.Error(t, byID["bob-rpc-error"].Err)
+	assert.NoError(t, byID["bob-never-mined"].Err)
+
+	first := byID["alice-1"]
+	assert.Equal(t, alice.Hex(), first.SenderAddress)
+	assert.Equal(t, uint64(21000), first.GasUsed)
+	assert.Equal(t, "10", first.GasPrice.String())
+	assert.Equal(t, start.Add(time.Minute), first.FinalizedAt)
+	assert.Equal(t, map[string]string{"customer": "alice-1"}, first.Metadata)
+
+	summary, err := inc.SummaryCostReport(start, start.Add(time.Hour), 137)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, summary.TransactionCount)
+	assert.Equal(t, 1, summary.UnknownCostCount)
+	assert.Equal(t, "1270000", summary.TotalCost.String())
+	assert.Len(t, summary.PerSender, 2)
+	assert.Equal(t, 3, summary.PerSender[alice.Hex()].TransactionCount)
+	assert.Equal(t, uint64(72000), summary.PerSender[alice.Hex()].GasUsed)
+	assert.Equal(t, "1020000", summary.PerSender[alice.Hex()].TotalCost.String())
+	assert.Equal(t, 3, summary.PerSender[bob.Hex()].TransactionCount)
+	assert.Equal(t, "250000", summary.PerSender[bob.Hex()].TotalCost.String())
+
+	summary, err = inc.SummaryCostReport(start, start.Add(time.Hour), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, summary.TransactionCount)
+	assert.Equal(t, "0", summary.TotalCost.String())
+}
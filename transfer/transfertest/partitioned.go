@@ -0,0 +1,54 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfertest
+
+import (
+	"sync"
+
+	"github.com/mysteriumnetwork/payments/transfer"
+)
+
+// InMemoryPartitionedStorage is a reference transfer.PartitionedStorage
+// implementation backing every chain with a separate InMemoryStorage.
+type InMemoryPartitionedStorage struct {
+	partitions map[int64]*InMemoryStorage
+	m          sync.Mutex
+}
+
+// NewInMemoryPartitionedStorage returns a new empty partitioned storage.
+func NewInMemoryPartitionedStorage() *InMemoryPartitionedStorage {
+	return &InMemoryPartitionedStorage{
+		partitions: make(map[int64]*InMemoryStorage),
+	}
+}
+
+// ForChain returns the storage partition of the given chain creating it if needed.
+func (s *InMemoryPartitionedStorage) ForChain(chainID int64) transfer.Storage {
+	return s.partition(chainID)
+}
+
+func (s *InMemoryPartitionedStorage) partition(chainID int64) *InMemoryStorage {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	p, ok := s.partitions[chainID]
+	if !ok {
+		p = NewInMemoryStorage()
+		s.partitions[chainID] = p
+	}
+	return p
+}
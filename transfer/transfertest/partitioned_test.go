@@ -0,0 +1,62 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfertest
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/payments/transfer"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ transfer.PartitionedStorage = (*InMemoryPartitionedStorage)(nil)
+
+func TestInMemoryPartitionedStorage(t *testing.T) {
+	var signers TestSignerFactory
+	sender := signers.MustGenerate()
+	tx, err := signers.SignWithAddress(sender, types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil), 1)
+	assert.NoError(t, err)
+
+	st := NewInMemoryPartitionedStorage()
+	inc := transfer.NewGasPriceIncremenetorPartitioned(transfer.GasIncrementorConfig{MaxQueuePerSigner: 1}, st, []int64{1, 137}, nil, signers.Signers())
+	assert.NoError(t, inc.InsertInitial(tx, transfer.TransactionOpts{
+		PriceMultiplier:  2,
+		MaxPrice:         big.NewInt(100),
+		Timeout:          time.Minute,
+		IncreaseInterval: time.Second,
+		CheckInterval:    time.Second,
+	}, sender))
+
+	txs, err := st.ForChain(1).GetIncrementorTransactionsToCheck([]string{sender.Hex()})
+	assert.NoError(t, err)
+	assert.Len(t, txs, 1)
+
+	txs, err = st.ForChain(137).GetIncrementorTransactionsToCheck([]string{sender.Hex()})
+	assert.NoError(t, err)
+	assert.Empty(t, txs, "transaction should not be visible in other chain partition")
+
+	canQueue, err := inc.CanQueue(sender)
+	assert.NoError(t, err)
+	assert.False(t, canQueue, "sender queue should be counted across partitions")
+
+	assert.Equal(t, 1, st.partition(1).Len())
+	assert.Equal(t, 0, st.partition(137).Len())
+}
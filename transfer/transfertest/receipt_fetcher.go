@@ -0,0 +1,73 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfertest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/payments/transfer"
+)
+
+type injectedReceipt struct {
+	receipt *types.Receipt
+	pending bool
+	err     error
+}
+
+// InjectableReceiptFetcher returns injected receipt responses
+// and falls back to another fetcher for all other transactions.
+type InjectableReceiptFetcher struct {
+	fallback transfer.ReceiptFetcher
+
+	injected map[common.Hash]injectedReceipt
+	m        sync.Mutex
+}
+
+// NewInjectableReceiptFetcher returns a new fetcher, the fallback is optional.
+func NewInjectableReceiptFetcher(fallback transfer.ReceiptFetcher) *InjectableReceiptFetcher {
+	return &InjectableReceiptFetcher{
+		fallback: fallback,
+		injected: make(map[common.Hash]injectedReceipt),
+	}
+}
+
+// Inject sets the response returned for the given transaction hash.
+func (f *InjectableReceiptFetcher) Inject(hash common.Hash, receipt *types.Receipt, pending bool, err error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.injected[hash] = injectedReceipt{receipt: receipt, pending: pending, err: err}
+}
+
+// FetchReceipt returns the injected response or asks the fallback fetcher.
+// Transactions are reported as pending if neither is available.
+func (f *InjectableReceiptFetcher) FetchReceipt(ctx context.Context, chainID int64, hash common.Hash) (*types.Receipt, bool, error) {
+	f.m.Lock()
+	res, ok := f.injected[hash]
+	f.m.Unlock()
+	if ok {
+		return res.receipt, res.pending, res.err
+	}
+
+	if f.fallback == nil {
+		return nil, true, nil
+	}
+	return f.fallback.FetchReceipt(ctx, chainID, hash)
+}
@@ -0,0 +1,121 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfertest
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/payments/transfer"
+	"github.com/stretchr/testify/assert"
+)
+
+// receiptlessClient accepts transactions but never returns their receipts.
+type receiptlessClient struct{}
+
+var errNoReceipts = errors.New("receipts are not available")
+
+func (c *receiptlessClient) TransactionReceipt(chainID int64, hash common.Hash) (*types.Receipt, error) {
+	return nil, errNoReceipts
+}
+
+func (c *receiptlessClient) SendTransaction(chainID int64, tx *types.Transaction) error {
+	return nil
+}
+
+func (c *receiptlessClient) TransactionByHash(chainID int64, hash common.Hash) (*types.Transaction, bool, error) {
+	return nil, false, errNoReceipts
+}
+
+func (c *receiptlessClient) TransactionConfirmations(chainID int64, hash common.Hash) (uint64, error) {
+	return 0, nil
+}
+
+func (c *receiptlessClient) BlockNumber(chainID int64) (uint64, error) {
+	return 0, nil
+}
+
+func (c *receiptlessClient) SuggestedGasPrice(chainID int64) (*big.Int, error) {
+	return nil, nil
+}
+
+func (c *receiptlessClient) NonceAt(chainID int64, account common.Address) (uint64, error) {
+	return 0, nil
+}
+
+func (c *receiptlessClient) PendingNonceAt(chainID int64, account common.Address) (uint64, error) {
+	return 0, nil
+}
+
+func (c *receiptlessClient) HeaderByNumber(chainID int64, number *big.Int) (*types.Header, error) {
+	return nil, errNoReceipts
+}
+
+func (c *receiptlessClient) PendingTransactionCount(chainID int64) (uint, error) {
+	return 0, nil
+}
+
+func TestInjectableReceiptFetcher(t *testing.T) {
+	hash := common.HexToHash("0x1")
+	fetcher := NewInjectableReceiptFetcher(transfer.NewDefaultReceiptFetcher(&receiptlessClient{}))
+
+	_, _, err := fetcher.FetchReceipt(context.Background(), 137, hash)
+	assert.ErrorIs(t, err, errNoReceipts, "fallback should be used")
+
+	fetcher.Inject(hash, &types.Receipt{Status: types.ReceiptStatusSuccessful}, false, nil)
+	receipt, pending, err := fetcher.FetchReceipt(context.Background(), 137, hash)
+	assert.NoError(t, err)
+	assert.False(t, pending)
+	assert.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+}
+
+func TestGasPriceIncrementor_UsesReceiptFetcher(t *testing.T) {
+	var signers TestSignerFactory
+	sender := signers.MustGenerate()
+	tx, err := signers.SignWithAddress(sender, types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil), 137)
+	assert.NoError(t, err)
+
+	fetcher := NewInjectableReceiptFetcher(nil)
+	fetcher.Inject(tx.Hash(), &types.Receipt{Status: types.ReceiptStatusSuccessful}, false, nil)
+
+	st := NewInMemoryStorage()
+	inc := transfer.NewGasPriceIncremenetor(transfer.GasIncrementorConfig{
+		PullInterval:      time.Millisecond,
+		MaxQueuePerSigner: 10,
+		ReceiptFetcher:    fetcher,
+	}, st, &receiptlessClient{}, signers.Signers())
+	go inc.Run()
+	defer inc.Stop()
+
+	assert.NoError(t, inc.InsertInitial(tx, transfer.TransactionOpts{
+		PriceMultiplier:  2,
+		MaxPrice:         big.NewInt(100),
+		Timeout:          time.Minute,
+		IncreaseInterval: time.Minute,
+		CheckInterval:    time.Millisecond * 10,
+	}, sender))
+
+	assert.Eventually(t, func() bool {
+		txs, err := st.GetIncrementorTransactionsByTimeRange(time.Time{}, time.Now().Add(time.Minute), 137, transfer.TxStateSucceed)
+		return err == nil && len(txs) == 1
+	}, time.Second, time.Millisecond*10)
+}
@@ -0,0 +1,91 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfertest
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mysteriumnetwork/payments/transfer"
+)
+
+// TestSignerFactory creates random signers to be used in tests.
+//
+// The zero value is ready to use.
+type TestSignerFactory struct {
+	keys map[common.Address]*ecdsa.PrivateKey
+	m    sync.Mutex
+}
+
+// Generate creates a new random key and registers it as a signer.
+func (f *TestSignerFactory) Generate() (common.Address, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.keys == nil {
+		f.keys = make(map[common.Address]*ecdsa.PrivateKey)
+	}
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	f.keys[addr] = key
+	return addr, nil
+}
+
+// MustGenerate is like Generate but panics on error.
+func (f *TestSignerFactory) MustGenerate() common.Address {
+	addr, err := f.Generate()
+	if err != nil {
+		panic(err)
+	}
+	return addr
+}
+
+// Signers returns all registered signers.
+func (f *TestSignerFactory) Signers() transfer.Signers {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	signers := make(transfer.Signers, len(f.keys))
+	for addr := range f.keys {
+		addr := addr
+		signers[addr] = func(tx *types.Transaction, chainID int64) (*types.Transaction, error) {
+			return f.SignWithAddress(addr, tx, chainID)
+		}
+	}
+	return signers
+}
+
+// SignWithAddress signs the transaction for the given chain using the key of a registered signer.
+func (f *TestSignerFactory) SignWithAddress(addr common.Address, tx *types.Transaction, chainID int64) (*types.Transaction, error) {
+	f.m.Lock()
+	key, ok := f.keys[addr]
+	f.m.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("signer %s is not registered", addr.Hex())
+	}
+
+	return types.SignTx(tx, types.LatestSignerForChainID(big.NewInt(chainID)), key)
+}
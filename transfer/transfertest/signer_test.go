@@ -0,0 +1,49 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfertest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestSignerFactory(t *testing.T) {
+	var f TestSignerFactory
+	addr := f.MustGenerate()
+	other := f.MustGenerate()
+	assert.Len(t, f.Signers(), 2)
+
+	tx := types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil)
+	signed, err := f.SignWithAddress(addr, tx, 137)
+	assert.NoError(t, err)
+	from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(137)), signed)
+	assert.NoError(t, err)
+	assert.Equal(t, addr, from)
+
+	signed, err = f.Signers()[other](tx, 137)
+	assert.NoError(t, err)
+	from, err = types.Sender(types.LatestSignerForChainID(big.NewInt(137)), signed)
+	assert.NoError(t, err)
+	assert.Equal(t, other, from)
+
+	_, err = f.SignWithAddress(common.HexToAddress("0x1"), tx, 137)
+	assert.Error(t, err)
+}
@@ -0,0 +1,265 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package transfertest provides helpers for testing code built on top of the transfer package.
+package transfertest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/transfer"
+)
+
+// InMemoryStorage is a reference transfer.Storage implementation
+// which keeps all transactions in memory.
+type InMemoryStorage struct {
+	txs  map[string]transfer.Transaction
+	keys map[string]struct{}
+	m    sync.Mutex
+
+	locks map[string]*txLock
+	lm    sync.Mutex
+}
+
+type txLock struct {
+	expires time.Time
+}
+
+// lockRetryInterval is how often a held lock is rechecked.
+const lockRetryInterval = time.Millisecond
+
+// NewInMemoryStorage returns a new empty in memory storage.
+func NewInMemoryStorage() *InMemoryStorage {
+	return &InMemoryStorage{
+		txs:  make(map[string]transfer.Transaction),
+		keys: make(map[string]struct{}),
+
+		locks: make(map[string]*txLock),
+	}
+}
+
+// UpsertIncrementorTransaction inserts or updates the given transaction.
+func (s *InMemoryStorage) UpsertIncrementorTransaction(tx transfer.Transaction) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.txs[tx.UniqueID] = copyTransaction(tx)
+	return nil
+}
+
+// InsertIncrementorTransactionIfAbsent inserts the given transaction
+// unless the idempotency key was already used.
+func (s *InMemoryStorage) InsertIncrementorTransactionIfAbsent(tx transfer.Transaction, idempotencyKey string) (bool, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if _, ok := s.keys[idempotencyKey]; ok {
+		return false, nil
+	}
+
+	s.keys[idempotencyKey] = struct{}{}
+	s.txs[tx.UniqueID] = copyTransaction(tx)
+	return true, nil
+}
+
+// Len returns the amount of stored transactions.
+func (s *InMemoryStorage) Len() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return len(s.txs)
+}
+
+// GetIncrementorTransactionsToCheck returns all non finalized transactions of the given signers.
+func (s *InMemoryStorage) GetIncrementorTransactionsToCheck(possibleSigners []string) ([]transfer.Transaction, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	signers := make(map[common.Address]struct{}, len(possibleSigners))
+	for _, signer := range possibleSigners {
+		signers[common.HexToAddress(signer)] = struct{}{}
+	}
+
+	return s.filter(func(tx transfer.Transaction) bool {
+		_, ok := signers[tx.SenderAddress()]
+		return ok && !tx.State.IsTerminal()
+	}), nil
+}
+
+// GetIncrementorSenderQueue returns the amount of non finalized transactions for the given sender.
+func (s *InMemoryStorage) GetIncrementorSenderQueue(sender string) (int, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return len(s.filter(func(tx transfer.Transaction) bool {
+		return tx.SenderAddress() == common.HexToAddress(sender) && !tx.State.IsTerminal()
+	})), nil
+}
+
+// GetIncrementorTransactionsByTimeRange returns transactions of the given chain created within `[from, to)`.
+func (s *InMemoryStorage) GetIncrementorTransactionsByTimeRange(from, to time.Time, chainID int64, states ...transfer.TransactionState) ([]transfer.Transaction, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.filter(func(tx transfer.Transaction) bool {
+		if tx.ChainID != chainID || tx.CreatedAt.Before(from) || !tx.CreatedAt.Before(to) {
+			return false
+		}
+
+		return len(states) == 0 || hasState(tx, states)
+	}), nil
+}
+
+// GetIncrementorFinalizedBefore returns all finalized transactions finalized before the given time.
+func (s *InMemoryStorage) GetIncrementorFinalizedBefore(before time.Time) ([]transfer.Transaction, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.filter(func(tx transfer.Transaction) bool {
+		return tx.State.IsTerminal() && tx.FinalizedAt.Before(before)
+	}), nil
+}
+
+// GetIncrementorTransactionsByUniqueIDs returns the stored transactions with the given unique IDs.
+func (s *InMemoryStorage) GetIncrementorTransactionsByUniqueIDs(uniqueIDs []string) (map[string]transfer.Transaction, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	res := make(map[string]transfer.Transaction, len(uniqueIDs))
+	for _, id := range uniqueIDs {
+		if tx, ok := s.txs[id]; ok {
+			res[id] = copyTransaction(tx)
+		}
+	}
+	return res, nil
+}
+
+// DeleteIncrementorTransactions removes the transactions with given unique IDs.
+func (s *InMemoryStorage) DeleteIncrementorTransactions(uniqueIDs []string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for _, id := range uniqueIDs {
+		delete(s.txs, id)
+	}
+	return nil
+}
+
+// LockTransaction blocks until the lock of the transaction is released or expires
+// or the context is done.
+func (s *InMemoryStorage) LockTransaction(ctx context.Context, uniqueID string, ttl time.Duration) (transfer.TransactionLock, error) {
+	for {
+		if lock, ok := s.tryLock(uniqueID, ttl); ok {
+			return &memoryLock{s: s, uniqueID: uniqueID, lock: lock}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// memoryLock is a lock held in the InMemoryStorage.
+type memoryLock struct {
+	s        *InMemoryStorage
+	uniqueID string
+	lock     *txLock
+}
+
+// Refresh extends the lock if it's still held.
+func (l *memoryLock) Refresh(ttl time.Duration) error {
+	l.s.lm.Lock()
+	defer l.s.lm.Unlock()
+
+	now := time.Now()
+	if l.s.locks[l.uniqueID] != l.lock || !now.Before(l.lock.expires) {
+		return transfer.ErrLockLost
+	}
+	l.lock.expires = now.Add(ttl)
+	return nil
+}
+
+// Unlock releases the lock if it's still held.
+func (l *memoryLock) Unlock() error {
+	l.s.lm.Lock()
+	defer l.s.lm.Unlock()
+
+	// Lock might have expired and been acquired by someone else.
+	if l.s.locks[l.uniqueID] == l.lock {
+		delete(l.s.locks, l.uniqueID)
+	}
+	return nil
+}
+
+func (s *InMemoryStorage) tryLock(uniqueID string, ttl time.Duration) (*txLock, bool) {
+	s.lm.Lock()
+	defer s.lm.Unlock()
+
+	now := time.Now()
+	if held, ok := s.locks[uniqueID]; ok && now.Before(held.expires) {
+		return nil, false
+	}
+
+	lock := &txLock{expires: now.Add(ttl)}
+	s.locks[uniqueID] = lock
+	return lock, true
+}
+
+// filter returns all transactions matching the predicate ordered by creation time.
+// Caller must hold the lock.
+func (s *InMemoryStorage) filter(predicate func(tx transfer.Transaction) bool) []transfer.Transaction {
+	res := make([]transfer.Transaction, 0)
+	for _, tx := range s.txs {
+		if predicate(tx) {
+			res = append(res, copyTransaction(tx))
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+	return res
+}
+
+func hasState(tx transfer.Transaction, states []transfer.TransactionState) bool {
+	for _, state := range states {
+		if tx.State == state {
+			return true
+		}
+	}
+	return false
+}
+
+// copyTransaction copies reference fields so that
+// stored transactions can not be modified by the caller.
+func copyTransaction(tx transfer.Transaction) transfer.Transaction {
+	tx.LatestTx = append([]byte(nil), tx.LatestTx...)
+	if tx.Metadata != nil {
+		meta := make(map[string]string, len(tx.Metadata))
+		for k, v := range tx.Metadata {
+			meta[k] = v
+		}
+		tx.Metadata = meta
+	}
+
+	return tx
+}
@@ -0,0 +1,244 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfertest
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/payments/transfer"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ transfer.Storage = (*InMemoryStorage)(nil)
+
+func TestInMemoryStorage_GetIncrementorTransactionsByTimeRange(t *testing.T) {
+	st := NewInMemoryStorage()
+	start := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	insert := func(id string, createdAt time.Time, chainID int64, state transfer.TransactionState) {
+		assert.NoError(t, st.UpsertIncrementorTransaction(transfer.Transaction{
+			UniqueID:  id,
+			ChainID:   chainID,
+			State:     state,
+			CreatedAt: createdAt,
+		}))
+	}
+
+	insert("before", start.Add(-time.Nanosecond), 1, transfer.TxStateSucceed)
+	insert("first", start, 1, transfer.TxStateSucceed)
+	insert("second", start.Add(30*time.Minute), 1, transfer.TxStateFailed)
+	insert("other-chain", start.Add(30*time.Minute), 137, transfer.TxStateFailed)
+	insert("at-end", start.Add(time.Hour), 1, transfer.TxStateSucceed)
+
+	ids := func(txs []transfer.Transaction) []string {
+		res := make([]string, 0, len(txs))
+		for _, tx := range txs {
+			res = append(res, tx.UniqueID)
+		}
+		return res
+	}
+
+	txs, err := st.GetIncrementorTransactionsByTimeRange(start, start.Add(time.Hour), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, ids(txs))
+
+	txs, err = st.GetIncrementorTransactionsByTimeRange(start, start.Add(time.Hour), 1, transfer.TxStateFailed)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"second"}, ids(txs))
+
+	txs, err = st.GetIncrementorTransactionsByTimeRange(start, start.Add(time.Hour), 137)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"other-chain"}, ids(txs))
+}
+
+func TestInMemoryStorage_GetIncrementorTransactionsByUniqueIDs(t *testing.T) {
+	st := NewInMemoryStorage()
+	for _, id := range []string{"first", "second", "third"} {
+		assert.NoError(t, st.UpsertIncrementorTransaction(transfer.Transaction{UniqueID: id, State: transfer.TxStateCreated}))
+	}
+	assert.NoError(t, st.UpsertIncrementorTransaction(transfer.Transaction{
+		UniqueID: "second",
+		State:    transfer.TxStatePriceIncreased,
+		Metadata: map[string]string{"bumped": "true"},
+	}))
+
+	txs, err := st.GetIncrementorTransactionsByUniqueIDs([]string{"first", "second", "missing", "other-missing"})
+	assert.NoError(t, err)
+	assert.Len(t, txs, 2)
+	assert.Equal(t, transfer.TxStateCreated, txs["first"].State)
+	assert.Equal(t, transfer.TxStatePriceIncreased, txs["second"].State)
+	assert.Equal(t, map[string]string{"bumped": "true"}, txs["second"].Metadata)
+
+	txs["second"].Metadata["bumped"] = "false"
+	txs, err = st.GetIncrementorTransactionsByUniqueIDs([]string{"second"})
+	assert.NoError(t, err)
+	assert.Equal(t, "true", txs["second"].Metadata["bumped"], "stored transactions should not be modified")
+
+	txs, err = st.GetIncrementorTransactionsByUniqueIDs(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, txs)
+}
+
+func TestInMemoryStorage_ConcurrentInsertInitial(t *testing.T) {
+	var signers TestSignerFactory
+	sender := signers.MustGenerate()
+	tx, err := signers.SignWithAddress(sender, types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil), 137)
+	assert.NoError(t, err)
+
+	st := NewInMemoryStorage()
+	inc := transfer.NewGasPriceIncremenetor(transfer.GasIncrementorConfig{}, st, nil, signers.Signers())
+	opts := transfer.TransactionOpts{
+		PriceMultiplier:  2,
+		MaxPrice:         big.NewInt(100),
+		Timeout:          time.Minute,
+		IncreaseInterval: time.Second,
+		CheckInterval:    time.Second,
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < 2; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, inc.InsertInitial(tx, opts, sender))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, st.Len())
+
+	inserted, err := st.InsertIncrementorTransactionIfAbsent(transfer.Transaction{UniqueID: "other"}, transfer.IdempotencyKey(tx, sender))
+	assert.NoError(t, err)
+	assert.False(t, inserted, "transaction with the same idempotency key should not be inserted")
+	assert.Equal(t, 1, st.Len())
+}
+
+type memoryArchiver struct {
+	archived []transfer.Transaction
+}
+
+func (a *memoryArchiver) Archive(txs []transfer.Transaction) error {
+	a.archived = append(a.archived, txs...)
+	return nil
+}
+
+func TestInMemoryStorage_ArchiveFinalized(t *testing.T) {
+	st := NewInMemoryStorage()
+	now := time.Now().UTC()
+	assert.NoError(t, st.UpsertIncrementorTransaction(transfer.Transaction{UniqueID: "old", State: transfer.TxStateSucceed, FinalizedAt: now.Add(-25 * time.Hour)}))
+	assert.NoError(t, st.UpsertIncrementorTransaction(transfer.Transaction{UniqueID: "recent", State: transfer.TxStateFailed, FinalizedAt: now.Add(-time.Hour)}))
+	assert.NoError(t, st.UpsertIncrementorTransaction(transfer.Transaction{UniqueID: "pending", State: transfer.TxStateCreated}))
+
+	archiver := &memoryArchiver{}
+	inc := transfer.NewGasPriceIncremenetor(transfer.GasIncrementorConfig{
+		ArchiverConfig: transfer.ArchiverConfig{
+			Archiver:     archiver,
+			ArchiveAfter: 24 * time.Hour,
+		},
+	}, st, nil, transfer.Signers{})
+	assert.NoError(t, inc.ArchiveFinalized())
+
+	assert.Len(t, archiver.archived, 1)
+	assert.Equal(t, "old", archiver.archived[0].UniqueID)
+	assert.Equal(t, 2, st.Len())
+
+	txs, err := st.GetIncrementorFinalizedBefore(now)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 1)
+	assert.Equal(t, "recent", txs[0].UniqueID)
+}
+
+func TestInMemoryStorage_LockTransaction(t *testing.T) {
+	st := NewInMemoryStorage()
+
+	t.Run("concurrent locks are acquired one at a time", func(t *testing.T) {
+		lock, err := st.LockTransaction(context.Background(), "tx", time.Minute)
+		assert.NoError(t, err)
+
+		var order []int
+		var om sync.Mutex
+		var wg sync.WaitGroup
+		for n := 1; n <= 2; n++ {
+			wg.Add(1)
+			go func(n int) {
+				defer wg.Done()
+				lock, err := st.LockTransaction(context.Background(), "tx", time.Minute)
+				assert.NoError(t, err)
+
+				om.Lock()
+				order = append(order, n)
+				om.Unlock()
+				time.Sleep(time.Millisecond * 5)
+				assert.NoError(t, lock.Unlock())
+			}(n)
+		}
+
+		time.Sleep(time.Millisecond * 20)
+		om.Lock()
+		assert.Empty(t, order, "lock should not be acquired while held")
+		om.Unlock()
+
+		other, err := st.LockTransaction(context.Background(), "other-tx", time.Minute)
+		assert.NoError(t, err, "other transactions should not be blocked")
+		assert.NoError(t, other.Unlock())
+
+		assert.NoError(t, lock.Unlock())
+		wg.Wait()
+		assert.Len(t, order, 2)
+	})
+	t.Run("lock is released after ttl", func(t *testing.T) {
+		_, err := st.LockTransaction(context.Background(), "expiring", time.Millisecond*10)
+		assert.NoError(t, err)
+
+		start := time.Now()
+		lock, err := st.LockTransaction(context.Background(), "expiring", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, time.Since(start) >= time.Millisecond*5)
+		assert.NoError(t, lock.Unlock())
+	})
+	t.Run("waiting stops when the context is done", func(t *testing.T) {
+		lock, err := st.LockTransaction(context.Background(), "held", time.Minute)
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+		_, err = st.LockTransaction(ctx, "held", time.Minute)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.NoError(t, lock.Unlock())
+	})
+	t.Run("refresh extends the lock until it's lost", func(t *testing.T) {
+		lock, err := st.LockTransaction(context.Background(), "refreshed", time.Millisecond*20)
+		assert.NoError(t, err)
+		time.Sleep(time.Millisecond * 10)
+		assert.NoError(t, lock.Refresh(time.Millisecond*20))
+		time.Sleep(time.Millisecond * 15)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		_, err = st.LockTransaction(ctx, "refreshed", time.Minute)
+		assert.Error(t, err, "refreshed lock should still be held")
+
+		time.Sleep(time.Millisecond * 20)
+		assert.True(t, errors.Is(lock.Refresh(time.Minute), transfer.ErrLockLost))
+	})
+}
@@ -0,0 +1,80 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfertest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// WebhookRequest is a request received by MockWebhookServer.
+type WebhookRequest struct {
+	Method string
+	Header http.Header
+	Body   []byte
+}
+
+// MockWebhookServer is a HTTP server capturing received webhook requests.
+type MockWebhookServer struct {
+	*httptest.Server
+
+	requests []WebhookRequest
+	failures int
+	m        sync.Mutex
+}
+
+// NewMockWebhookServer starts a new webhook server which
+// responds with an error to the first `failures` requests.
+func NewMockWebhookServer(failures int) *MockWebhookServer {
+	s := &MockWebhookServer{failures: failures}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *MockWebhookServer) handle(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.requests = append(s.requests, WebhookRequest{
+		Method: r.Method,
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Requests returns all received requests.
+func (s *MockWebhookServer) Requests() []WebhookRequest {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return append([]WebhookRequest(nil), s.requests...)
+}
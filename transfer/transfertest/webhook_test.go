@@ -0,0 +1,72 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/transfer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	tx := transfer.Transaction{
+		UniqueID: "tx-1",
+		State:    transfer.TxStateSucceed,
+		ChainID:  137,
+	}
+
+	t.Run("succeeded transaction is posted once", func(t *testing.T) {
+		server := NewMockWebhookServer(0)
+		defer server.Close()
+
+		notifier := &transfer.WebhookNotifier{URL: server.URL, Secret: "secret"}
+		assert.NoError(t, notifier.Notify(tx))
+
+		requests := server.Requests()
+		assert.Len(t, requests, 1)
+		assert.Equal(t, http.MethodPost, requests[0].Method)
+
+		var payload transfer.WebhookPayload
+		assert.NoError(t, json.Unmarshal(requests[0].Body, &payload))
+		assert.Equal(t, transfer.WebhookPayload{UniqueID: "tx-1", State: transfer.TxStateSucceed, ChainID: 137}, payload)
+		assert.JSONEq(t, `{"uniqueID":"tx-1","state":"succeed","chainID":137}`, string(requests[0].Body))
+		assert.Equal(t, transfer.WebhookSignature("secret", requests[0].Body), requests[0].Header.Get(transfer.WebhookSignatureHeader))
+		assert.NotEqual(t, transfer.WebhookSignature("other", requests[0].Body), requests[0].Header.Get(transfer.WebhookSignatureHeader))
+	})
+	t.Run("failed deliveries are retried with backoff", func(t *testing.T) {
+		server := NewMockWebhookServer(2)
+		defer server.Close()
+
+		notifier := &transfer.WebhookNotifier{URL: server.URL, Secret: "secret", RetryDelay: 10 * time.Millisecond}
+		start := time.Now()
+		assert.NoError(t, notifier.Notify(tx))
+		assert.True(t, time.Since(start) >= 30*time.Millisecond, "expected 10ms and 20ms delays")
+		assert.Len(t, server.Requests(), 3)
+	})
+	t.Run("error is returned once retries are exhausted", func(t *testing.T) {
+		server := NewMockWebhookServer(10)
+		defer server.Close()
+
+		notifier := &transfer.WebhookNotifier{URL: server.URL, RetryDelay: time.Millisecond}
+		assert.Error(t, notifier.Notify(tx))
+		assert.Len(t, server.Requests(), 1+transfer.DefaultWebhookMaxRetries)
+	})
+}
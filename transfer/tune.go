@@ -0,0 +1,115 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+)
+
+// ReferenceTransactionTimeout is the transaction timeout used to estimate queue sizes.
+const ReferenceTransactionTimeout = 10 * time.Minute
+
+// tuneSampleBlocks is the amount of latest blocks used to measure the block time.
+const tuneSampleBlocks = 100
+
+// ErrBlockTimeUnknown is returned if the block time of a chain can't be measured.
+var ErrBlockTimeUnknown = errors.New("can't measure block time")
+
+// EstimateOptimalQueueSize returns the steady state queue depth per signer calculated
+// as `ceil(ReferenceTransactionTimeout / blockTime) * txPerBlock / signers`.
+//
+// It is never less than 1. The chain ID is currently not used.
+func EstimateOptimalQueueSize(chainID int64, blockTime time.Duration, txPerBlock int, signers int) int {
+	if blockTime <= 0 || txPerBlock <= 0 || signers <= 0 {
+		return 1
+	}
+
+	blocks := int(math.Ceil(float64(ReferenceTransactionTimeout) / float64(blockTime)))
+	size := blocks * txPerBlock / signers
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
+// TuneConfig returns a config recommended for the chain.
+//
+// The block time is measured over the latest 100 blocks and the amount of
+// transactions in the pending block is used as the expected transactions per block.
+// The queue size is estimated for a single signer.
+func TuneConfig(ctx context.Context, chainID int64, cl MultichainClient) (GasIncrementorConfig, error) {
+	blockTime, err := measureBlockTime(ctx, chainID, cl)
+	if err != nil {
+		return GasIncrementorConfig{}, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return GasIncrementorConfig{}, err
+	}
+	pending, err := cl.PendingTransactionCount(chainID)
+	if err != nil {
+		return GasIncrementorConfig{}, fmt.Errorf("failed to get pending transaction count: %w", err)
+	}
+	txPerBlock := int(pending)
+	if txPerBlock < 1 {
+		txPerBlock = 1
+	}
+
+	cfg := GasIncrementorConfig{
+		PullInterval:      blockTime,
+		MaxQueuePerSigner: EstimateOptimalQueueSize(chainID, blockTime, txPerBlock, 1),
+		BlockPeriod:       blockTime,
+	}
+	return cfg, cfg.Validate()
+}
+
+func measureBlockTime(ctx context.Context, chainID int64, cl MultichainClient) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	latest, err := cl.HeaderByNumber(chainID, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block header: %w", err)
+	}
+	if latest.Number == nil || latest.Number.Sign() <= 0 {
+		return 0, fmt.Errorf("%w: chain %d has no mined blocks", ErrBlockTimeUnknown, chainID)
+	}
+
+	sample := big.NewInt(tuneSampleBlocks)
+	if latest.Number.Cmp(sample) < 0 {
+		sample.Set(latest.Number)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	oldest, err := cl.HeaderByNumber(chainID, new(big.Int).Sub(latest.Number, sample))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block header: %w", err)
+	}
+	if latest.Time <= oldest.Time {
+		return 0, fmt.Errorf("%w: block timestamps of chain %d are not increasing", ErrBlockTimeUnknown, chainID)
+	}
+
+	elapsed := time.Duration(latest.Time-oldest.Time) * time.Second
+	return elapsed / time.Duration(sample.Int64()), nil
+}
@@ -0,0 +1,115 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// headerClient has a block mined every blockTime seconds up to head.
+type headerClient struct {
+	mockClient
+	head      int64
+	blockTime uint64
+	pending   uint
+}
+
+func (c *headerClient) HeaderByNumber(chainID int64, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		number = big.NewInt(c.head)
+	}
+	return &types.Header{Number: number, Time: 1600000000 + number.Uint64()*c.blockTime}, nil
+}
+
+func (c *headerClient) PendingTransactionCount(chainID int64) (uint, error) {
+	return c.pending, nil
+}
+
+func TestEstimateOptimalQueueSize(t *testing.T) {
+	for _, tc := range []struct {
+		blockTime  time.Duration
+		txPerBlock int
+		signers    int
+		want       int
+	}{
+		{2 * time.Second, 10, 4, 750},
+		{12 * time.Second, 150, 3, 2500},
+		{7 * time.Second, 1, 1, 86},
+		{7 * time.Minute, 1, 5, 1},
+		{0, 10, 1, 1},
+		{time.Second, 10, 0, 1},
+	} {
+		assert.Equal(t, tc.want, EstimateOptimalQueueSize(137, tc.blockTime, tc.txPerBlock, tc.signers), "%+v", tc)
+	}
+}
+
+func TestTuneConfig(t *testing.T) {
+	t.Run("recommends config from chain throughput", func(t *testing.T) {
+		cfg, err := TuneConfig(context.Background(), 137, &headerClient{head: 1000, blockTime: 2, pending: 50})
+		assert.NoError(t, err)
+		assert.NoError(t, cfg.Validate())
+		assert.Equal(t, 2*time.Second, cfg.PullInterval)
+		assert.Equal(t, 2*time.Second, cfg.BlockPeriod)
+		assert.Equal(t, 15000, cfg.MaxQueuePerSigner)
+	})
+	t.Run("young chain with empty pending block", func(t *testing.T) {
+		cfg, err := TuneConfig(context.Background(), 137, &headerClient{head: 10, blockTime: 15})
+		assert.NoError(t, err)
+		assert.NoError(t, cfg.Validate())
+		assert.Equal(t, 15*time.Second, cfg.PullInterval)
+		assert.Equal(t, 40, cfg.MaxQueuePerSigner)
+	})
+	t.Run("fails without increasing timestamps", func(t *testing.T) {
+		_, err := TuneConfig(context.Background(), 137, &headerClient{head: 1000})
+		assert.True(t, errors.Is(err, ErrBlockTimeUnknown))
+
+		_, err = TuneConfig(context.Background(), 137, &headerClient{blockTime: 2})
+		assert.True(t, errors.Is(err, ErrBlockTimeUnknown))
+	})
+	t.Run("stops on cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := TuneConfig(ctx, 137, &headerClient{head: 1000, blockTime: 2})
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
+func TestGasIncrementorConfig_Validate(t *testing.T) {
+	valid := GasIncrementorConfig{PullInterval: time.Second, MaxQueuePerSigner: 10}
+	assert.NoError(t, valid.Validate())
+
+	for name, modify := range map[string]func(cfg *GasIncrementorConfig){
+		"no pull interval":        func(cfg *GasIncrementorConfig) { cfg.PullInterval = 0 },
+		"no queue":                func(cfg *GasIncrementorConfig) { cfg.MaxQueuePerSigner = 0 },
+		"negative rate limit":     func(cfg *GasIncrementorConfig) { cfg.RateLimit.MaxInsertsPerSecond = -1 },
+		"negative bump percent":   func(cfg *GasIncrementorConfig) { cfg.MinBumpPercent = -0.1 },
+		"alpha above 1":           func(cfg *GasIncrementorConfig) { cfg.AdaptiveAlpha = 1.5 },
+		"negative lock ttl":       func(cfg *GasIncrementorConfig) { cfg.LockTTL = -time.Second },
+		"negative thrashing rate": func(cfg *GasIncrementorConfig) { cfg.ThrashingThreshold = -1 },
+	} {
+		cfg := valid
+		modify(&cfg)
+		assert.Error(t, cfg.Validate(), name)
+	}
+}
@@ -0,0 +1,77 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultUnderpricedRetryMultiplier is the default gas price multiplier for underpriced retries.
+const DefaultUnderpricedRetryMultiplier = 1.01
+
+// sendWithUnderpricedRetry resends the transaction at the given gas price
+// retrying at a slightly higher price if it's rejected as underpriced.
+func (i *GasPriceIncremenetor) sendWithUnderpricedRetry(tx Transaction, org *types.Transaction, gasPrice *big.Int) (*types.Transaction, error) {
+	for retry := 0; ; retry++ {
+		newTx, err := i.signAndSend(i.rebuildTransaction(tx, org, gasPrice), tx.ChainID, tx.SenderAddressHex)
+		if err == nil || !isUnderpriced(err) || retry >= i.cfg.UnderpricedMaxRetries {
+			return newTx, err
+		}
+
+		gasPrice = i.underpricedRetryPrice(gasPrice)
+		if gasPrice.Cmp(tx.Opts.MaxPrice) > 0 {
+			return nil, fmt.Errorf("underpriced retry exceeds max price of %s: %w", tx.Opts.MaxPrice, err)
+		}
+
+		select {
+		case <-time.After(i.cfg.UnderpricedRetryDelay):
+		case <-i.stop:
+			return nil, errors.New("incrementor stopped while retrying underpriced transaction")
+		}
+	}
+}
+
+// underpricedRetryPrice returns the next price to retry with which is always higher than the given one.
+func (i *GasPriceIncremenetor) underpricedRetryPrice(gasPrice *big.Int) *big.Int {
+	multiplier := i.cfg.UnderpricedRetryMultiplier
+	if multiplier <= 1 {
+		multiplier = DefaultUnderpricedRetryMultiplier
+	}
+
+	next := nextGasPrice(gasPrice, multiplier, 0)
+	if next.Cmp(gasPrice) <= 0 {
+		next = new(big.Int).Add(gasPrice, big.NewInt(1))
+	}
+	return next
+}
+
+func isUnderpriced(err error) bool {
+	if errors.Is(err, core.ErrReplaceUnderpriced) || errors.Is(err, core.ErrUnderpriced) {
+		return true
+	}
+
+	// Errors returned over RPC are not typed, resort to string checks.
+	return strings.Contains(err.Error(), core.ErrReplaceUnderpriced.Error()) ||
+		strings.Contains(err.Error(), core.ErrUnderpriced.Error())
+}
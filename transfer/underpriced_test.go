@@ -0,0 +1,80 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// underpricedClient rejects the first sent transactions as underpriced.
+type underpricedClient struct {
+	mockClient
+	rejections int
+	sent       []*big.Int
+}
+
+func (c *underpricedClient) SendTransaction(chainID int64, tx *types.Transaction) error {
+	c.sent = append(c.sent, tx.GasPrice())
+	if len(c.sent) <= c.rejections {
+		return errors.New("replacement transaction underpriced")
+	}
+	return nil
+}
+
+func TestGasPriceIncrementor_UnderpricedRetry(t *testing.T) {
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(500), []byte{}), 137)
+	opts := defaultOpts()
+	opts.MaxPrice = big.NewInt(10000)
+	tx, err := newTransaction(org, sg.address, opts)
+	assert.NoError(t, err)
+
+	cfg := GasIncrementorConfig{
+		UnderpricedMaxRetries: 3,
+		UnderpricedRetryDelay: time.Millisecond,
+	}
+
+	t.Run("retries at higher price", func(t *testing.T) {
+		c := &underpricedClient{rejections: 3}
+		inc := NewGasPriceIncremenetor(cfg, &mockStorage{}, c, Signers{sg.address: sg.SignatureFunc})
+
+		newTx, err := inc.increaseGasPrice(*tx)
+		assert.NoError(t, err)
+		assert.Equal(t, []*big.Int{big.NewInt(1000), big.NewInt(1010), big.NewInt(1020), big.NewInt(1030)}, c.sent)
+
+		latest, err := newTx.getLatestTx()
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(1030), latest.GasPrice())
+	})
+	t.Run("gives up after max retries", func(t *testing.T) {
+		c := &underpricedClient{rejections: 4}
+		st := &mockStorage{}
+		inc := NewGasPriceIncremenetor(cfg, st, c, Signers{sg.address: sg.SignatureFunc})
+
+		_, err := inc.increaseGasPrice(*tx)
+		assert.NoError(t, err)
+		assert.Len(t, c.sent, 4)
+		assert.Equal(t, TxStateFailed, st.tx.State)
+	})
+}
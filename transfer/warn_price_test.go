@@ -0,0 +1,62 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPriceIncrementor_WarnPrice(t *testing.T) {
+	sg := newSigner()
+	opts := defaultOpts()
+	opts.MaxPrice = big.NewInt(10000)
+	bump := func(gasPrice int64, times int) []ErrGasPriceWarning {
+		org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(gasPrice), []byte{}), 137)
+		tx, err := newTransaction(org, sg.address, opts)
+		assert.NoError(t, err)
+
+		warnings := make([]ErrGasPriceWarning, 0)
+		inc := NewGasPriceIncremenetor(GasIncrementorConfig{WarnPrice: big.NewInt(8000)}, &mockStorage{}, newClient(nil), Signers{sg.address: sg.SignatureFunc})
+		inc.AttachLogFunc(func(tx Transaction, err error) {
+			var warning ErrGasPriceWarning
+			if errors.As(err, &warning) {
+				warnings = append(warnings, warning)
+			}
+		})
+
+		for n := 0; n < times; n++ {
+			*tx, err = inc.increaseGasPrice(*tx)
+			assert.NoError(t, err)
+		}
+		return warnings
+	}
+
+	assert.Empty(t, bump(2500, 1), "bump to 50% of max price should not warn")
+	assert.Equal(t, []ErrGasPriceWarning{{Price: big.NewInt(8500), WarnPrice: big.NewInt(8000), MaxPrice: big.NewInt(10000)}}, bump(4250, 1))
+
+	t.Run("warns on every bump", func(t *testing.T) {
+		opts.PriceMultiplier = 1.11
+		warnings := bump(7300, 2)
+		assert.Len(t, warnings, 2)
+	})
+}
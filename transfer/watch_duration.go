@@ -0,0 +1,87 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrLongRunningWatch is logged once for a transaction watched for longer
+// than the configured AlertOnWatchDuration.
+type ErrLongRunningWatch struct {
+	UniqueID  string
+	Watched   time.Duration
+	Threshold time.Duration
+}
+
+func (e ErrLongRunningWatch) Error() string {
+	return fmt.Sprintf("transaction %q is being watched for %s, threshold is %s", e.UniqueID, e.Watched, e.Threshold)
+}
+
+// MaxWatchDuration returns how long the longest watched transaction
+// has been watched for. Zero is returned if nothing is being watched.
+func (i *GasPriceIncremenetor) MaxWatchDuration() time.Duration {
+	durations := i.syncer.watchDurations()
+	if len(durations) == 0 {
+		return 0
+	}
+	return durations[len(durations)-1]
+}
+
+// P99WatchDuration returns the 99th percentile of how long
+// currently watched transactions have been watched for.
+func (i *GasPriceIncremenetor) P99WatchDuration() time.Duration {
+	return percentile(i.syncer.watchDurations(), 0.99)
+}
+
+// checkWatchDuration logs an ErrLongRunningWatch and returns true if the
+// transaction has been watched for longer than the AlertOnWatchDuration.
+func (i *GasPriceIncremenetor) checkWatchDuration(tx Transaction) bool {
+	if i.cfg.AlertOnWatchDuration <= 0 {
+		return false
+	}
+
+	_, startedAt, ok := i.syncer.bumpStats(tx)
+	if !ok {
+		return false
+	}
+
+	watched := time.Since(startedAt)
+	if watched <= i.cfg.AlertOnWatchDuration {
+		return false
+	}
+
+	i.log(tx, ErrLongRunningWatch{UniqueID: tx.UniqueID, Watched: watched, Threshold: i.cfg.AlertOnWatchDuration})
+	return true
+}
+
+// watchDurations returns sorted durations of how long
+// each transaction has been watched for.
+func (s *syncer) watchDurations() []time.Duration {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	now := time.Now()
+	durations := make([]time.Duration, 0, len(s.startedAt))
+	for _, startedAt := range s.startedAt {
+		durations = append(durations, now.Sub(startedAt))
+	}
+	sort.Slice(durations, func(a, b int) bool { return durations[a] < durations[b] })
+	return durations
+}
@@ -0,0 +1,64 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPriceIncrementor_WatchDuration(t *testing.T) {
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{}, &mockStorage{}, newClient(nil), Signers{})
+	defer inc.Stop()
+
+	assert.Zero(t, inc.MaxWatchDuration())
+	assert.Zero(t, inc.P99WatchDuration())
+
+	for n := 1; n <= 100; n++ {
+		tx := Transaction{UniqueID: fmt.Sprintf("tx-%d", n), ChainID: 137}
+		inc.syncer.txMarkBeingWatched(tx)
+		inc.syncer.txRestoreStartedAt(tx, time.Now().Add(-time.Duration(n)*time.Minute))
+	}
+
+	assert.InDelta(t, float64(100*time.Minute), float64(inc.MaxWatchDuration()), float64(time.Second))
+	assert.InDelta(t, float64(99*time.Minute), float64(inc.P99WatchDuration()), float64(time.Second))
+
+	t.Run("long running watches are reported", func(t *testing.T) {
+		var warnings []ErrLongRunningWatch
+		inc.AttachLogFunc(func(tx Transaction, err error) {
+			var warning ErrLongRunningWatch
+			if errors.As(err, &warning) {
+				warnings = append(warnings, warning)
+			}
+		})
+
+		long := Transaction{UniqueID: "tx-90", ChainID: 137}
+		short := Transaction{UniqueID: "tx-10", ChainID: 137}
+		assert.False(t, inc.checkWatchDuration(long), "alert is disabled")
+
+		inc.cfg.AlertOnWatchDuration = time.Hour
+		assert.False(t, inc.checkWatchDuration(short))
+		assert.True(t, inc.checkWatchDuration(long))
+		assert.Len(t, warnings, 1)
+		assert.Equal(t, "tx-90", warnings[0].UniqueID)
+		assert.Equal(t, time.Hour, warnings[0].Threshold)
+	})
+}
@@ -0,0 +1,152 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Default WebhookNotifier retry parameters.
+const (
+	DefaultWebhookMaxRetries = 3
+	DefaultWebhookRetryDelay = time.Second
+	DefaultWebhookTimeout    = 10 * time.Second
+)
+
+// WebhookSignatureHeader holds the hex encoded HMAC-SHA256 of the webhook body.
+const WebhookSignatureHeader = "X-Signature"
+
+// WebhookPayload is the JSON body posted by WebhookNotifier.
+type WebhookPayload struct {
+	UniqueID string           `json:"uniqueID"`
+	State    TransactionState `json:"state"`
+	ChainID  int64            `json:"chainID"`
+}
+
+// WebhookNotifier posts finalized transactions to a webhook.
+//
+// Its Notify method can be used as GasIncrementorConfig.FinalizedFn.
+type WebhookNotifier struct {
+	URL string
+	// Secret is used to sign the request body, see WebhookSignature.
+	Secret     string
+	HTTPClient *http.Client
+
+	// MaxRetries is the amount of times a failed delivery is retried,
+	// doubling RetryDelay after each attempt.
+	// Defaults are used if not given.
+	MaxRetries int
+	RetryDelay time.Duration
+	// Timeout limits a single delivery attempt. Default is used if not given.
+	Timeout time.Duration
+}
+
+// Notify posts the transaction state to the webhook, retrying failed deliveries.
+func (n *WebhookNotifier) Notify(tx Transaction) error {
+	return n.NotifyContext(context.Background(), tx)
+}
+
+// NotifyContext is like Notify but stops retrying once the context is done.
+func (n *WebhookNotifier) NotifyContext(ctx context.Context, tx Transaction) error {
+	body, err := json.Marshal(WebhookPayload{
+		UniqueID: tx.UniqueID,
+		State:    tx.State,
+		ChainID:  tx.ChainID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	retries := n.MaxRetries
+	if retries <= 0 {
+		retries = DefaultWebhookMaxRetries
+	}
+	delay := n.RetryDelay
+	if delay <= 0 {
+		delay = DefaultWebhookRetryDelay
+	}
+
+	for attempt := 0; ; attempt++ {
+		err = n.post(ctx, body)
+		if err == nil || attempt >= retries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to deliver webhook for transaction %q: %w", tx.UniqueID, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook for transaction %q: %w", tx.UniqueID, err)
+	}
+
+	return nil
+}
+
+func (n *WebhookNotifier) post(ctx context.Context, body []byte) error {
+	timeout := n.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, WebhookSignature(n.Secret, body))
+
+	cl := n.HTTPClient
+	if cl == nil {
+		cl = http.DefaultClient
+	}
+
+	res, err := cl.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected webhook response status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// WebhookSignature returns the hex encoded HMAC-SHA256 of the body using the secret.
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
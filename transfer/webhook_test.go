@@ -0,0 +1,131 @@
+/* Mysterium network payment library.
+ *
+ * Copyright (C) 2021 BlockDev AG
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPriceIncrementor_FinalizedWebhook(t *testing.T) {
+	var bodies [][]byte
+	var signatures []string
+	var m sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		m.Lock()
+		defer m.Unlock()
+		bodies = append(bodies, body)
+		signatures = append(signatures, r.Header.Get(WebhookSignatureHeader))
+	}))
+	defer server.Close()
+
+	sg := newSigner()
+	org := sg.mustSign(types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), []byte{}), 137)
+	st := &mockStorage{}
+	notifier := &WebhookNotifier{URL: server.URL, Secret: "secret"}
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{
+		PullInterval:      time.Millisecond,
+		MaxQueuePerSigner: 100,
+		FinalizedFn:       notifier.Notify,
+	}, st, newClient(big.NewInt(2)), Signers{sg.address: sg.SignatureFunc})
+	go inc.Run()
+	defer inc.Stop()
+
+	assert.NoError(t, inc.InsertInitial(org, defaultOpts(), sg.address))
+	assert.Eventually(t, func() bool {
+		m.Lock()
+		defer m.Unlock()
+		return len(bodies) > 0
+	}, time.Second, time.Millisecond*10)
+	time.Sleep(time.Millisecond * 50)
+
+	m.Lock()
+	defer m.Unlock()
+	assert.Len(t, bodies, 1)
+
+	var payload WebhookPayload
+	assert.NoError(t, json.Unmarshal(bodies[0], &payload))
+	assert.Equal(t, TxStateSucceed, payload.State)
+	assert.Equal(t, int64(137), payload.ChainID)
+	assert.NotEmpty(t, payload.UniqueID)
+	assert.Equal(t, WebhookSignature("secret", bodies[0]), signatures[0])
+}
+
+func TestWebhookNotifier_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	notifier := &WebhookNotifier{URL: server.URL, MaxRetries: 1, RetryDelay: time.Millisecond, Timeout: 20 * time.Millisecond}
+	start := time.Now()
+	assert.Error(t, notifier.Notify(Transaction{UniqueID: "tx"}))
+	assert.True(t, time.Since(start) < time.Second, "hanging webhook should time out")
+
+	t.Run("retries stop once the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		notifier := &WebhookNotifier{URL: server.URL, MaxRetries: 100, RetryDelay: time.Hour, Timeout: time.Millisecond}
+		err := notifier.NotifyContext(ctx, Transaction{UniqueID: "tx"})
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
+func TestGasPriceIncrementor_FinalizedFnDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	called := make(chan Transaction, 1)
+	inc := NewGasPriceIncremenetor(GasIncrementorConfig{
+		FinalizedFn: func(tx Transaction) error {
+			called <- tx
+			<-release
+			return nil
+		},
+	}, &mockStorage{}, newClient(nil), Signers{})
+	defer close(release)
+
+	done := make(chan error, 1)
+	go func() { done <- inc.transactionSuccess(Transaction{UniqueID: "tx", State: TxStatePendingConfirmation}) }()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("watcher was blocked by FinalizedFn")
+	}
+	select {
+	case tx := <-called:
+		assert.Equal(t, TxStateSucceed, tx.State)
+	case <-time.After(time.Second):
+		t.Fatal("FinalizedFn was not called")
+	}
+}